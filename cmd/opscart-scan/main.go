@@ -1,9 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/opscart/opscart-k8s-watcher/pkg/analyzer"
@@ -24,14 +31,40 @@ var (
 	format         string // Used by resources, costs, etc.
 	securityFormat string // Used by security command
 	reportFormat   string // Used by report command
+	outputFile     string // Used by report and security --format=html
+	outputDir      string // Used by report and security --format=html
+	retentionDays  int    // Used by report and security --format=html
+	templateFile   string // Used by report and security --format=html
+	ignoreFile     string // Used by security and report commands
+	baselineFile   string // Used by security command
+	saveBaseline   string // Used by security command
+	failOnNew      bool   // Used by security command
 	enhanced       bool
 	monthlyCost    float64
+	pricingFile    string // Used by costs, optimize, report and idle commands
+	groupBy        string // Used by costs command
+	failOnBudget   bool   // Used by costs command
+	emitPatches    string // Used by optimize command
 	showScenarios  bool
+	withLogs       bool  // Used by emergency command
+	logLines       int64 // Used by emergency command
 
 	// NEW v0.2 flags
 	allClustersFlag  bool
 	clusterGroupFlag string
 	compareFlag      []string
+
+	// budgetExceeded is set by any costs run (clusters scan in parallel) with a budget overrun
+	budgetExceeded atomic.Bool
+	// newFindings is set by a security --baseline run that reports findings missing from the baseline
+	newFindings atomic.Bool
+)
+
+const (
+	// exitBudgetExceeded is the exit code for costs --fail-on-budget when a budget is exceeded
+	exitBudgetExceeded = 2
+	// exitNewFindings is the exit code for security --fail-on-new when findings are new since the baseline
+	exitNewFindings = 3
 )
 
 func main() {
@@ -88,6 +121,11 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 		Short: "Find critical issues immediately",
 		Long:  "Scans cluster for broken pods, failed deployments, and critical issues",
 		Run: func(cmd *cobra.Command, args []string) {
+			if logLines <= 0 {
+				fmt.Println("Error: --log-lines must be greater than 0")
+				os.Exit(1)
+			}
+
 			clusters, isCompare, err := resolveTargetClusters()
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
@@ -110,19 +148,24 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			out := progressOutput(format)
+			scanner.PrintMultiClusterHeader(out, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
 				err := runEmergencyScan(context)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
+			runner.SetOutput(out)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(out, results)
 		},
 	}
 	emergencyCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
 	emergencyCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to scan (default: all)")
+	emergencyCmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table|json)")
+	emergencyCmd.Flags().BoolVar(&withLogs, "with-logs", false, "Include previous/current log tail for crashing containers")
+	emergencyCmd.Flags().Int64Var(&logLines, "log-lines", 20, "Number of log lines to fetch with --with-logs")
 	emergencyCmd.Flags().BoolVar(&allClustersFlag, "all-clusters", false, "Scan all configured clusters")
 	emergencyCmd.Flags().StringVar(&clusterGroupFlag, "cluster-group", "", "Scan all clusters in a group")
 
@@ -156,15 +199,17 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			out := progressOutput(format)
+			scanner.PrintMultiClusterHeader(out, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
 				err := runResourcesScan(context)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
+			runner.SetOutput(out)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(out, results)
 		},
 	}
 	resourcesCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
@@ -187,6 +232,22 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 				os.Exit(1)
 			}
 
+			// A baseline belongs to one cluster
+			if baselineFile != "" || saveBaseline != "" {
+				if baselineFile != "" && saveBaseline != "" {
+					fmt.Println("Error: use either --baseline or --save-baseline, not both")
+					os.Exit(1)
+				}
+				if isCompare || len(clusters) != 1 {
+					fmt.Println("Error: --baseline and --save-baseline work on a single cluster")
+					os.Exit(1)
+				}
+			}
+			if failOnNew && baselineFile == "" {
+				fmt.Println("Error: --fail-on-new requires --baseline")
+				os.Exit(1)
+			}
+
 			// Compare mode
 			if isCompare {
 				scanner.PrintCompareHeader(clusters[0].Name, clusters[1].Name)
@@ -211,19 +272,22 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
+				exitOnNewFindings()
 				return
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			out := progressOutput(securityFormat)
+			scanner.PrintMultiClusterHeader(out, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
 				err := runSecurityScan(context)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
+			runner.SetOutput(out)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(out, results)
 		},
 	}
 	securityCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
@@ -232,6 +296,11 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 	securityCmd.Flags().BoolVar(&allClustersFlag, "all-clusters", false, "Scan all configured clusters")
 	securityCmd.Flags().StringVar(&clusterGroupFlag, "cluster-group", "", "Scan all clusters in a group")
 	securityCmd.Flags().StringSliceVar(&compareFlag, "compare", nil, "Compare two clusters (provide exactly 2)")
+	securityCmd.Flags().StringVar(&saveBaseline, "save-baseline", "", "Save the current findings as a baseline file")
+	securityCmd.Flags().StringVar(&baselineFile, "baseline", "", "Report only findings new since this baseline, plus fixed ones")
+	securityCmd.Flags().BoolVar(&failOnNew, "fail-on-new", false, "With --baseline, exit with code 3 when there are findings new since the baseline")
+	securityCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "Accepted-risk exceptions file (default: "+config.DefaultSuppressionsFile+" if present)")
+	addReportOutputFlags(securityCmd)
 
 	// ================================================================
 	// Optimize command (UPDATED for multi-cluster)
@@ -252,9 +321,16 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 				os.Exit(1)
 			}
 
+			// Prices patch savings; optional
+			pricing, err := loadPricing()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			// Single cluster (existing behavior)
 			if len(clusters) == 1 {
-				if err := runOptimizeScan(clusters[0].Context); err != nil {
+				if err := runOptimizeScan(clusters[0].Context, pricing, emitPatches); err != nil {
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
@@ -262,19 +338,27 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			scanner.PrintMultiClusterHeader(os.Stdout, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
-				err := runOptimizeScan(context)
+				// One patch directory per cluster
+				patchDir := emitPatches
+				if patchDir != "" {
+					patchDir = filepath.Join(emitPatches, context)
+				}
+				err := runOptimizeScan(context, pricing, patchDir)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(os.Stdout, results)
 		},
 	}
 	optimizeCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
 	optimizeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to analyze (default: all)")
+	optimizeCmd.Flags().StringVar(&emitPatches, "emit-patches", "", "Write spot, right-sizing and HPA patches plus a savings summary to this directory")
+	optimizeCmd.Flags().Float64VarP(&monthlyCost, "monthly-cost", "m", 0, "Monthly compute (node) cost of the cluster, to price patch savings")
+	optimizeCmd.Flags().StringVar(&pricingFile, "pricing", "", "Node pricing file, to price patch savings")
 	optimizeCmd.Flags().BoolVar(&allClustersFlag, "all-clusters", false, "Scan all configured clusters")
 	optimizeCmd.Flags().StringVar(&clusterGroupFlag, "cluster-group", "", "Scan all clusters in a group")
 
@@ -292,11 +376,27 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 				os.Exit(1)
 			}
 
-			if monthlyCost <= 0 {
-				fmt.Println("Error: --monthly-cost required")
+			pricing, err := loadPricing()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if monthlyCost <= 0 && pricing == nil {
+				fmt.Println("Error: --monthly-cost or --pricing (or pricing_file in config) required")
 				os.Exit(1)
 			}
 
+			var groupKey *analyzer.GroupKey
+			if groupBy != "" {
+				key, err := analyzer.ParseGroupBy(groupBy)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				groupKey = &key
+			}
+
 			if isCompare {
 				fmt.Println("Error: --compare not yet supported for costs command")
 				os.Exit(1)
@@ -304,32 +404,38 @@ Quickly find broken resources, idle workloads, security issues, and generate rep
 
 			// Single cluster (existing behavior)
 			if len(clusters) == 1 {
-				if err := runCostsScan(clusters[0].Context); err != nil {
+				if err := runCostsScan(clusters[0].Context, pricing, groupKey); err != nil {
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
+				exitOnBudgetOverrun()
 				return
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			out := progressOutput(format)
+			scanner.PrintMultiClusterHeader(out, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
-				err := runCostsScan(context)
+				err := runCostsScan(context, pricing, groupKey)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
+			runner.SetOutput(out)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(out, results)
+			exitOnBudgetOverrun()
 		},
 	}
 	costsCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
 	costsCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to analyze (default: all)")
-	costsCmd.Flags().Float64VarP(&monthlyCost, "monthly-cost", "m", 0, "Total cluster cost per month (required)")
-	costsCmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table|json)")
+	costsCmd.Flags().Float64VarP(&monthlyCost, "monthly-cost", "m", 0, "Monthly compute (node) cost of the cluster; storage and LoadBalancers are added on top (required without --pricing)")
+	costsCmd.Flags().StringVar(&pricingFile, "pricing", "", "Node pricing file (per instance type, capacity type and zone)")
+	costsCmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table|json|csv)")
+	costsCmd.Flags().StringVar(&groupBy, "group-by", "", "Showback grouping: label:<key>, annotation:<key> or namespace")
+	costsCmd.Flags().BoolVar(&failOnBudget, "fail-on-budget", false, "Exit with code 2 when a namespace or label exceeds its budget")
 	costsCmd.Flags().BoolVar(&allClustersFlag, "all-clusters", false, "Scan all configured clusters")
 	costsCmd.Flags().StringVar(&clusterGroupFlag, "cluster-group", "", "Scan all clusters in a group")
-	costsCmd.MarkFlagRequired("monthly-cost")
 
 	// ================================================================
 	// Find command (keeps existing all-clusters flag — already works)
@@ -432,15 +538,17 @@ Examples:
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			out := progressOutput(format)
+			scanner.PrintMultiClusterHeader(out, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
 				err := runSnapshotScan(context)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
+			runner.SetOutput(out)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(out, results)
 		},
 	}
 	snapshotCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
@@ -469,9 +577,15 @@ Examples:
 				os.Exit(1)
 			}
 
+			pricing, err := loadPricing()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			// Single cluster (existing behavior)
 			if len(clusters) == 1 {
-				if err := runIdleScan(clusters[0].Context); err != nil {
+				if err := runIdleScan(clusters[0].Context, pricing); err != nil {
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
@@ -479,19 +593,20 @@ Examples:
 			}
 
 			// Multi-cluster mode
-			scanner.PrintMultiClusterHeader(clusters)
+			scanner.PrintMultiClusterHeader(os.Stdout, clusters)
 			scanFunc := func(context string) (*scanner.ClusterResult, error) {
-				err := runIdleScan(context)
+				err := runIdleScan(context, pricing)
 				return &scanner.ClusterResult{}, err
 			}
 
 			runner := scanner.NewMultiClusterRunner(clusters, scanFunc)
 			results := runner.RunAll()
-			scanner.PrintMultiClusterSummary(results)
+			scanner.PrintMultiClusterSummary(os.Stdout, results)
 		},
 	}
 	idleCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
 	idleCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to scan (default: all)")
+	idleCmd.Flags().StringVar(&pricingFile, "pricing", "", "Pricing file for orphaned storage and LoadBalancer costs (default: pricing_file from config)")
 	idleCmd.Flags().BoolVar(&allClustersFlag, "all-clusters", false, "Scan all configured clusters")
 	idleCmd.Flags().StringVar(&clusterGroupFlag, "cluster-group", "", "Scan all clusters in a group")
 
@@ -501,7 +616,7 @@ Examples:
 	reportCmd := &cobra.Command{
 		Use:   "report",
 		Short: "Generate comprehensive cluster report",
-		Long:  "Generate HTML/JSON/CSV report combining security, resources, and cost analysis",
+		Long:  "Generate HTML/JSON/CSV/Markdown report combining security, resources, and cost analysis",
 		Run: func(cmd *cobra.Command, args []string) {
 			clusters, isCompare, err := resolveTargetClusters()
 			if err != nil {
@@ -514,20 +629,47 @@ Examples:
 				os.Exit(1)
 			}
 
+			if templateFile != "" && reportFormat != "" && reportFormat != "html" && reportFormat != "print" {
+				fmt.Println("Error: --template needs --format html or print")
+				os.Exit(1)
+			}
+
+			// One file per cluster must not all land on the same --output path.
+			// A custom template renders a single cluster, so it gets one file per cluster too.
+			fleet := (reportFormat == "" || reportFormat == "html") && templateFile == ""
+			if len(clusters) > 1 && !fleet && outputFile != "" && !strings.Contains(outputFile, "{cluster}") {
+				fmt.Println("Error: --output needs a {cluster} placeholder when writing one report per cluster")
+				os.Exit(1)
+			}
+
+			// Prices the cost section; optional
+			pricing, err := loadPricing()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			// Single cluster
 			if len(clusters) == 1 {
-				if err := runReportGeneration(clusters[0].Context, clusters[0].Name); err != nil {
+				if err := runReportGeneration(clusters[0].Context, clusters[0].Name, pricing); err != nil {
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
 				return
 			}
 
-			// Multi-cluster
-			scanner.PrintMultiClusterHeader(clusters)
+			// Multi-cluster: one fleet report for built-in HTML, one file per cluster otherwise
+			scanner.PrintMultiClusterHeader(os.Stdout, clusters)
+			if fleet {
+				if err := runFleetReport(clusters, pricing); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
 			for i, cluster := range clusters {
 				fmt.Printf("\n🔄 Generating report for %s (%d/%d)...\n", cluster.Name, i+1, len(clusters))
-				if err := runReportGeneration(cluster.Context, cluster.Name); err != nil {
+				if err := runReportGeneration(cluster.Context, cluster.Name, pricing); err != nil {
 					fmt.Printf("❌ %s failed: %v\n", cluster.Name, err)
 				}
 			}
@@ -537,10 +679,91 @@ Examples:
 	}
 
 	reportCmd.Flags().StringVarP(&cluster, "cluster", "c", "", "Cluster context name")
-	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Output format (html|json|csv)")
+	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Output format (html|json|csv|markdown|print)")
 	reportCmd.Flags().BoolVar(&allClustersFlag, "all-clusters", false, "Generate reports for all clusters")
 	reportCmd.Flags().StringVar(&clusterGroupFlag, "cluster-group", "", "Generate reports for cluster group")
-	reportCmd.Flags().Float64Var(&monthlyCost, "monthly-cost", 0, "Monthly cluster cost (optional)")
+	reportCmd.Flags().Float64Var(&monthlyCost, "monthly-cost", 0, "Monthly compute (node) cost of the cluster (optional)")
+	reportCmd.Flags().StringVar(&pricingFile, "pricing", "", "Node pricing file, used instead of --monthly-cost")
+	reportCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "Accepted-risk exceptions file (default: "+config.DefaultSuppressionsFile+" if present)")
+	addReportOutputFlags(reportCmd)
+
+	var openLatest bool
+	reportLatestCmd := &cobra.Command{
+		Use:   "latest",
+		Short: "Show the most recent report",
+		Long:  "Print the path of the most recently written report, optionally opening it in the browser",
+		Run: func(cmd *cobra.Command, args []string) {
+			output, _ := reportOutput()
+			ext := map[string]string{"html": ".html", "print": ".html", "json": ".json", "csv": ".csv", "markdown": ".md", "md": ".md", "any": ""}
+			reportExt, ok := ext[reportFormat]
+			if !ok {
+				fmt.Printf("Error: unsupported format: %s\n", reportFormat)
+				os.Exit(1)
+			}
+			latest, err := report.LatestReport(output.Dir, reportExt)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(latest)
+			if openLatest {
+				if err := openInBrowser(latest); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		},
+	}
+	reportLatestCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Report type to look for (html|json|csv|markdown|any)")
+	reportLatestCmd.Flags().StringVar(&outputDir, "output-dir", "", "Reports directory (default: reports.dir from config, else ./reports)")
+	reportLatestCmd.Flags().BoolVar(&openLatest, "open", false, "Open the report in the default browser")
+
+	reportPruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old reports",
+		Long:  "Delete reports older than the retention period (--retention-days, else reports.retention_days from config, else 30)",
+		Run: func(cmd *cobra.Command, args []string) {
+			output, days := reportOutput()
+			if days <= 0 {
+				days = 30
+			}
+			deleted, err := report.PruneReports(output.Dir, time.Duration(days)*24*time.Hour)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("🧹 Deleted %d reports older than %d days from %s\n", deleted, days, output.Dir)
+		},
+	}
+	reportPruneCmd.Flags().StringVar(&outputDir, "output-dir", "", "Reports directory (default: reports.dir from config, else ./reports)")
+	reportPruneCmd.Flags().IntVar(&retentionDays, "retention-days", 0, "Delete reports older than this many days")
+
+	reportCmd.AddCommand(reportLatestCmd)
+	reportCmd.AddCommand(reportPruneCmd)
+
+	// ================================================================
+	// Schema command - JSON Schema of each command's JSON output
+	// ================================================================
+	schemaCmd := &cobra.Command{
+		Use:       "schema <command>",
+		Short:     "Print the JSON Schema of a command's JSON output",
+		Long:      "Print the JSON Schema (draft 2020-12) of the --format json output of " + strings.Join(jsonOutputCommands(), ", "),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: jsonOutputCommands(),
+		Run: func(cmd *cobra.Command, args []string) {
+			data, ok := jsonOutputs[args[0]]
+			if !ok {
+				fmt.Printf("Error: %s has no JSON output (one of: %s)\n", args[0], strings.Join(jsonOutputCommands(), ", "))
+				os.Exit(1)
+			}
+			schema, err := json.MarshalIndent(models.JSONSchema(args[0], data), "", "  ")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(schema))
+		},
+	}
 
 	// Add all commands
 	rootCmd.AddCommand(configCmd)
@@ -553,6 +776,7 @@ Examples:
 	rootCmd.AddCommand(snapshotCmd)
 	rootCmd.AddCommand(idleCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(schemaCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -628,8 +852,25 @@ func resolveTargetClusters() ([]config.ClusterConfig, bool, error) {
 // Extracted scan functions (existing logic moved to functions)
 // ================================================================
 
-func runEmergencyScan(clusterContext string) error {
+// printClusterHeader names the scanned cluster; JSON and CSV output keep stdout to the data
+func printClusterHeader(clusterContext, outputFormat string) {
+	if outputFormat == "json" || outputFormat == "csv" {
+		return
+	}
 	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
+}
+
+// progressOutput is where multi-cluster banners, progress and summaries go: stderr for
+// JSON and CSV, so stdout holds only the per-cluster output
+func progressOutput(outputFormat string) io.Writer {
+	if outputFormat == "json" || outputFormat == "csv" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func runEmergencyScan(clusterContext string) error {
+	printClusterHeader(clusterContext, format)
 	s, err := scanner.NewScanner(clusterContext)
 	if err != nil {
 		return fmt.Errorf("connecting to cluster: %w", err)
@@ -640,29 +881,40 @@ func runEmergencyScan(clusterContext string) error {
 		return fmt.Errorf("scanning cluster: %w", err)
 	}
 
-	scanner.PrintEmergencyIssues(issues)
+	// Group issues sharing a root cause (node, config, storage, image, rollout)
+	incidents := s.CorrelateIssues(issues)
+
+	if withLogs {
+		s.AttachLogs(issues, logLines)
+	}
+
+	if format == "json" {
+		scanner.PrintEmergencyIssuesJSON(clusterContext, issues, incidents)
+	} else {
+		scanner.PrintEmergencyIssues(issues, incidents)
+	}
 	return nil
 }
 
 func runResourcesScan(clusterContext string) error {
-	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
+	printClusterHeader(clusterContext, format)
 	clientset, err := getKubernetesClient(clusterContext)
 	if err != nil {
 		return fmt.Errorf("connecting to cluster: %w", err)
 	}
 
-	ra := analyzer.NewResourceAnalyzer(clientset)
+	ra := newResourceAnalyzer(clientset, clusterContext)
 	analysis, err := ra.AnalyzeClusterResources(namespace)
 	if err != nil {
 		return fmt.Errorf("analyzing resources: %w", err)
 	}
 
-	analyzer.PrintResourceAnalysis(analysis, format)
+	analyzer.PrintResourceAnalysis(clusterContext, analysis, format)
 	return nil
 }
 
 func runSecurityScan(clusterContext string) error {
-	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
+	printClusterHeader(clusterContext, securityFormat)
 
 	// Default to table if not specified
 	if securityFormat == "" {
@@ -680,42 +932,85 @@ func runSecurityScan(clusterContext string) error {
 		return fmt.Errorf("connecting to cluster: %w", err)
 	}
 
-	sa := analyzer.NewSecurityAuditor(clientset)
+	sa, err := newSecurityAuditor(clientset, clusterContext)
+	if err != nil {
+		return err
+	}
 	audit, err := sa.AuditClusterSecurity(namespace)
 	if err != nil {
 		return fmt.Errorf("auditing security: %w", err)
 	}
+	if err := saveSecurityBaseline(clusterContext, audit); err != nil {
+		return err
+	}
+	if audit.Baseline != nil && len(audit.Issues) > 0 {
+		newFindings.Store(true)
+	}
 
-	analyzer.PrintSecurityAudit(audit, securityFormat)
+	analyzer.PrintSecurityAudit(clusterContext, audit, securityFormat)
 	return nil
 }
 
-func runOptimizeScan(clusterContext string) error {
+func runOptimizeScan(clusterContext string, pricing *config.Pricing, patchDir string) error {
 	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
 	clientset, err := getKubernetesClient(clusterContext)
 	if err != nil {
 		return fmt.Errorf("connecting to cluster: %w", err)
 	}
 
-	ra := analyzer.NewResourceAnalyzer(clientset)
+	ra := newResourceAnalyzer(clientset, clusterContext)
 	analysis, err := ra.AnalyzeClusterResources(namespace)
 	if err != nil {
 		return fmt.Errorf("analyzing resources: %w", err)
 	}
 
 	analyzer.PrintOptimizationSummary(analysis.Optimizations)
+	analyzer.PrintRightsizingDiffs(analysis)
+
+	if patchDir == "" {
+		return nil
+	}
+
+	// Savings are priced only when a cost input is available
+	priced := false
+	costPerCore, costPerGB := 0.0, 0.0
+	if monthlyCost > 0 || pricing != nil {
+		ca := analyzer.NewCostAnalyzer(analysis)
+		if pricing != nil {
+			ca.SetPricing(pricing)
+		}
+		estimate, err := ca.AnalyzeCosts(monthlyCost)
+		if err != nil {
+			return fmt.Errorf("analyzing costs: %w", err)
+		}
+		costPerCore, costPerGB = analyzer.ComputeRates(estimate, analysis)
+		priced = true
+	}
+
+	patches, err := ra.GeneratePatches(namespace, analysis, costPerCore, costPerGB)
+	if err != nil {
+		return fmt.Errorf("generating patches: %w", err)
+	}
+	if err := analyzer.WritePatches(patchDir, clusterContext, patches, priced); err != nil {
+		return err
+	}
+	fmt.Printf("📝 Wrote %d patches to %s (savings per patch: %s)\n",
+		len(patches), patchDir, filepath.Join(patchDir, analyzer.PatchSummaryFile))
 	return nil
 }
 
-func runCostsScan(clusterContext string) error {
-	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
+func runCostsScan(clusterContext string, pricing *config.Pricing, groupKey *analyzer.GroupKey) error {
+	printClusterHeader(clusterContext, format)
 	clientset, err := getKubernetesClient(clusterContext)
 	if err != nil {
 		return fmt.Errorf("connecting to cluster: %w", err)
 	}
 
 	// First get resource analysis
-	ra := analyzer.NewResourceAnalyzer(clientset)
+	ra := newResourceAnalyzer(clientset, clusterContext)
+	if groupKey != nil {
+		ra.SetGroupBy(*groupKey)
+	}
 	resourceAnalysis, err := ra.AnalyzeClusterResources(namespace)
 	if err != nil {
 		return fmt.Errorf("analyzing resources: %w", err)
@@ -723,17 +1018,116 @@ func runCostsScan(clusterContext string) error {
 
 	// Then perform cost analysis
 	ca := analyzer.NewCostAnalyzer(resourceAnalysis)
+	if pricing != nil {
+		ca.SetPricing(pricing)
+	}
+	if groupKey != nil {
+		// Security findings are reported per group alongside cost
+		sa, err := newSecurityAuditor(clientset, clusterContext)
+		if err != nil {
+			return err
+		}
+		audit, err := sa.AuditClusterSecurity(namespace)
+		if err != nil {
+			return fmt.Errorf("auditing security: %w", err)
+		}
+		ca.SetSecurityFindings(audit.Issues)
+	}
 	costEstimate, err := ca.AnalyzeCosts(monthlyCost)
 	if err != nil {
 		return fmt.Errorf("analyzing costs: %w", err)
 	}
 
-	analyzer.PrintCostAnalysis(costEstimate, format)
+	// Budgets and cost jumps vs the stored history; a broken history only loses the comparison
+	budgets := (&config.OpsCartConfig{}).BudgetSettings()
+	if cfg, err := config.LoadConfig(); err == nil {
+		budgets = cfg.BudgetSettings()
+	}
+	history, historyErr := analyzer.LoadCostHistory(clusterContext)
+	if historyErr != nil {
+		// Leave an unreadable history in place rather than replacing it with this run
+		fmt.Fprintf(os.Stderr, "⚠️  %v (not updating %s)\n", historyErr, analyzer.CostHistoryPath(clusterContext))
+	}
+	analyzer.CheckBudgets(costEstimate, budgets, history)
+	checkLabelBudgets(clientset, clusterContext, pricing, costEstimate, budgets)
+	if historyErr == nil {
+		if err := analyzer.SaveCostHistory(clusterContext, history, costEstimate); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}
+	if analyzer.OverBudget(costEstimate) {
+		budgetExceeded.Store(true)
+	}
+
+	analyzer.PrintCostAnalysis(clusterContext, costEstimate, format)
 	return nil
 }
 
+// checkLabelBudgets evaluates the label budgets CheckBudgets skipped because --group-by
+// names another key, grouping the cluster by each of those labels in a separate analysis.
+// A failed grouping only warns, so a CI run never passes silently.
+func checkLabelBudgets(clientset *kubernetes.Clientset, clusterContext string, pricing *config.Pricing, estimate *models.CostEstimate, budgets config.BudgetConfig) {
+	for _, key := range analyzer.UncheckedLabelBudgets(estimate, budgets) {
+		ra := newResourceAnalyzer(clientset, clusterContext)
+		ra.SetGroupBy(analyzer.GroupKey{Source: "label", Key: key})
+		analysis, err := ra.AnalyzeClusterResources(namespace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Budgets for label %q not evaluated: %v\n", key, err)
+			continue
+		}
+		ca := analyzer.NewCostAnalyzer(analysis)
+		if pricing != nil {
+			ca.SetPricing(pricing)
+		}
+		grouped, err := ca.AnalyzeCosts(monthlyCost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Budgets for label %q not evaluated: %v\n", key, err)
+			continue
+		}
+		analyzer.AddLabelBudgetAlerts(estimate, grouped, budgets)
+	}
+}
+
+// jsonOutputs maps each command with JSON output to the data in its envelope. The
+// snapshot schema is the --enhanced shape; its extra fields are optional.
+var jsonOutputs = map[string]interface{}{
+	"emergency": scanner.EmergencyOutput{},
+	"resources": models.ClusterResourceAnalysis{},
+	"security":  analyzer.SecurityAuditOutput{},
+	"costs":     models.CostEstimate{},
+	"snapshot":  models.EnhancedClusterSnapshot{},
+	"report":    report.ReportData{},
+}
+
+// jsonOutputCommands lists the commands with JSON output, sorted
+func jsonOutputCommands() []string {
+	var commands []string
+	for command := range jsonOutputs {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// exitOnBudgetOverrun exits with exitBudgetExceeded when --fail-on-budget is set and a budget was exceeded
+func exitOnBudgetOverrun() {
+	if failOnBudget && budgetExceeded.Load() {
+		fmt.Fprintln(os.Stderr, "❌ Budget exceeded")
+		os.Exit(exitBudgetExceeded)
+	}
+}
+
+// exitOnNewFindings exits with exitNewFindings when --fail-on-new is set and the audit found
+// issues missing from the baseline
+func exitOnNewFindings() {
+	if failOnNew && newFindings.Load() {
+		fmt.Fprintln(os.Stderr, "❌ New findings since the baseline")
+		os.Exit(exitNewFindings)
+	}
+}
+
 func runSnapshotScan(clusterContext string) error {
-	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
+	printClusterHeader(clusterContext, format)
 	s, err := scanner.NewScanner(clusterContext)
 	if err != nil {
 		return fmt.Errorf("connecting to cluster: %w", err)
@@ -762,12 +1156,13 @@ func runSnapshotScan(clusterContext string) error {
 	return nil
 }
 
-func runIdleScan(clusterContext string) error {
+func runIdleScan(clusterContext string, pricing *config.Pricing) error {
 	fmt.Printf("\n🔍 Cluster: %s\n", clusterContext)
 	s, err := scanner.NewScanner(clusterContext)
 	if err != nil {
 		return fmt.Errorf("connecting to cluster: %w", err)
 	}
+	s.SetPricing(pricing)
 
 	idle, err := s.FindIdleResources(namespace)
 	if err != nil {
@@ -778,389 +1173,385 @@ func runIdleScan(clusterContext string) error {
 	return nil
 }
 
-func runReportGeneration(clusterContext string, clusterName string) error {
-	fmt.Printf("\n🔍 Cluster: %s\n", clusterName)
-	fmt.Println("📊 Generating comprehensive report...")
+// newResourceAnalyzer creates a ResourceAnalyzer, attaching Prometheus usage history and
+// namespace environments when configured
+func newResourceAnalyzer(clientset *kubernetes.Clientset, clusterContext string) *analyzer.ResourceAnalyzer {
+	ra := analyzer.NewResourceAnalyzer(clientset)
 
-	// Get Kubernetes client
-	clientset, err := getKubernetesClient(clusterContext)
+	cfg, err := config.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("connecting to cluster: %w", err)
+		return ra
 	}
-
-	// Run REAL security audit
-	fmt.Println("  🛡️  Running security audit...")
-	sa := analyzer.NewSecurityAuditor(clientset)
-	audit, err := sa.AuditClusterSecurity(namespace)
-	if err != nil {
-		return fmt.Errorf("security audit failed: %w", err)
+	if prom := cfg.PrometheusFor(clusterContext); prom.URL != "" {
+		ra.SetPrometheus(analyzer.NewPrometheusClient(prom.URL, prom.BearerToken), prom.Window)
 	}
-	cisResult := analyzer.CalculateCISScore(audit)
+	ra.SetEnvironments(cfg.EnvironmentSettings())
+	return ra
+}
 
-	// Build report data with REAL security findings
-	reportData := &report.ReportData{
-		ClusterName:    clusterName,
-		GeneratedAt:    time.Now(),
-		CISScore:       cisResult.Score,
-		SecurityScore:  cisResult.Score,
-		ControlsPassed: cisResult.PassedChecks,
-		ControlsFailed: cisResult.FailedChecks,
-		PodCount:       audit.TotalPodsAudited,
-		NamespaceCount: len(audit.Issues),
-		MonthlyCost:    monthlyCost,
-	}
-
-	// Calculate savings if cost provided
-	if monthlyCost > 0 {
-		reportData.PotentialSavings = report.SavingsRange{
-			Min: monthlyCost * 0.24,
-			Max: monthlyCost * 0.36,
+// newSecurityAuditor creates a security auditor with the --baseline findings and the
+// accepted-risk exceptions from --ignore-file, or from .opscart-ignore.yaml in the working
+// directory when it exists. A baseline saved from another cluster is rejected.
+func newSecurityAuditor(clientset *kubernetes.Clientset, clusterContext string) (*analyzer.SecurityAuditor, error) {
+	sa := analyzer.NewSecurityAuditor(clientset)
+	if baselineFile != "" {
+		baseline, err := analyzer.LoadSecurityBaseline(baselineFile)
+		if err != nil {
+			return nil, err
 		}
+		if baseline.Cluster != "" && baseline.Cluster != clusterContext {
+			return nil, fmt.Errorf("baseline %s was saved from cluster %q, not %q", baselineFile, baseline.Cluster, clusterContext)
+		}
+		sa.SetBaseline(baseline)
 	}
 
-	// Extract security risks
-	risks := audit.Risks
-
-	// Add critical issues
-	if risks.PrivilegedContainers > 0 {
-		reportData.CriticalIssues = append(reportData.CriticalIssues, report.IssueItem{
-			Severity:    "critical",
-			Title:       fmt.Sprintf("🔴 %d privileged containers detected", risks.PrivilegedContainers),
-			Description: "Containers with elevated privileges can escape containment",
-			Count:       risks.PrivilegedContainers,
-		})
-	}
-
-	if risks.HostPathVolumes > 0 {
-		reportData.CriticalIssues = append(reportData.CriticalIssues, report.IssueItem{
-			Severity:    "critical",
-			Title:       fmt.Sprintf("🔴 %d pods mounting host paths", risks.HostPathVolumes),
-			Description: "Host path volumes provide direct access to host filesystem",
-			Count:       risks.HostPathVolumes,
-		})
-	}
-
-	if risks.HostPID > 0 {
-		reportData.CriticalIssues = append(reportData.CriticalIssues, report.IssueItem{
-			Severity:    "critical",
-			Title:       fmt.Sprintf("🔴 %d containers sharing host PID namespace", risks.HostPID),
-			Description: "Host PID namespace sharing allows container processes to see all processes",
-			Count:       risks.HostPID,
-		})
-	}
-
-	// Add warnings
-	if risks.RunningAsRoot > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers running as root", risks.RunningAsRoot),
-			Description: "Running as root increases attack surface",
-			Count:       risks.RunningAsRoot,
-		})
+	path := ignoreFile
+	if path == "" {
+		if _, err := os.Stat(config.DefaultSuppressionsFile); err != nil {
+			return sa, nil
+		}
+		path = config.DefaultSuppressionsFile
 	}
-
-	if risks.MissingResourceLimits > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers missing resource limits", risks.MissingResourceLimits),
-			Description: "Missing resource limits can lead to resource exhaustion",
-			Count:       risks.MissingResourceLimits,
-		})
+	suppressions, err := config.LoadSuppressions(path)
+	if err != nil {
+		return nil, err
 	}
+	sa.SetSuppressions(suppressions)
+	return sa, nil
+}
 
-	if risks.HostNetwork > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers using host network", risks.HostNetwork),
-			Description: "Host network access bypasses network policies",
-			Count:       risks.HostNetwork,
-		})
+// saveSecurityBaseline writes the audit to --save-baseline when given. Messages go to
+// stderr so JSON output stays parseable.
+func saveSecurityBaseline(clusterContext string, audit *models.SecurityAudit) error {
+	if saveBaseline == "" {
+		return nil
 	}
-
-	if risks.HostIPC > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers sharing host IPC namespace", risks.HostIPC),
-			Description: "Host IPC namespace sharing can leak sensitive information",
-			Count:       risks.HostIPC,
-		})
+	if err := analyzer.SaveSecurityBaseline(saveBaseline, clusterContext, audit); err != nil {
+		return err
 	}
+	fmt.Fprintf(os.Stderr, "💾 Baseline saved: %s (%d findings)\n", saveBaseline, len(audit.Issues))
+	return nil
+}
 
-	if risks.PrivilegeEscalation > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers allowing privilege escalation", risks.PrivilegeEscalation),
-			Description: "Privilege escalation can lead to container breakout",
-			Count:       risks.PrivilegeEscalation,
-		})
+// loadPricing loads the node pricing file from --pricing, or from the config when
+// --monthly-cost is not given (nil when neither applies)
+func loadPricing() (*config.Pricing, error) {
+	if pricingFile != "" && monthlyCost > 0 {
+		return nil, fmt.Errorf("use either --pricing or --monthly-cost, not both")
 	}
-
-	if risks.DefaultServiceAccount > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d pods using default service account", risks.DefaultServiceAccount),
-			Description: "Default service account may have excessive permissions",
-			Count:       risks.DefaultServiceAccount,
-		})
+	path := pricingFile
+	if path == "" && monthlyCost <= 0 {
+		if cfg, err := config.LoadConfig(); err == nil {
+			path = cfg.PricingFile
+		}
 	}
-
-	if risks.AddedCapabilities > 0 {
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers with added capabilities", risks.AddedCapabilities),
-			Description: "Unnecessary capabilities increase attack surface",
-			Count:       risks.AddedCapabilities,
-		})
+	if path == "" {
+		return nil, nil
 	}
+	return config.LoadPricing(path)
+}
 
-	// Calculate overall scores
-	reportData.OverallScore = report.CalculateOverallScore(reportData.SecurityScore, 75, 60)
-	reportData.ResourceScore = 75
-	reportData.CostScore = 60
+func runReportGeneration(clusterContext string, clusterName string, pricing *config.Pricing) error {
+	fmt.Printf("\n🔍 Cluster: %s\n", clusterName)
+	fmt.Println("📊 Generating comprehensive report...")
 
-	// Default to html if not specified
-	if reportFormat == "" {
-		reportFormat = "html"
+	reportFmt, err := parseReportFormat(reportFormat)
+	if err != nil {
+		return err
 	}
 
-	// Determine format
-	var reportFmt report.ReportFormat
-	switch reportFormat {
-	case "html":
-		reportFmt = report.FormatHTML
-	case "json":
-		reportFmt = report.FormatJSON
-	case "csv":
-		reportFmt = report.FormatCSV
-	default:
-		return fmt.Errorf("unsupported format: %s", reportFormat)
+	reportData, err := buildClusterReport(clusterContext, clusterName, pricing)
+	if err != nil {
+		return err
 	}
 
 	// Generate report
-	generator := report.NewGenerator(reportFmt, "")
+	generator, output, retention, err := newReportGenerator(reportFmt, true)
+	if err != nil {
+		return err
+	}
 	outputPath, err := generator.Generate(reportData)
 	if err != nil {
 		return fmt.Errorf("generating report: %w", err)
 	}
+	pruneReports(output, retention)
 
 	// Show success
 	fmt.Printf("\n✅ Report generated: %s\n", outputPath)
-	if reportFmt == report.FormatHTML {
+	if reportFmt == report.FormatHTML || reportFmt == report.FormatPrint {
 		fmt.Printf("🌐 Open in browser: file://%s\n", outputPath)
 	}
 	fmt.Printf("📊 Summary: CIS Score %d/100 | %d Critical | %d Warnings | %d Total Issues\n",
-		cisResult.Score, len(reportData.CriticalIssues), len(reportData.WarningIssues), len(audit.Issues))
+		reportData.CISScore, len(reportData.CriticalIssues), len(reportData.WarningIssues), reportData.IssueCount)
 
 	return nil
 }
 
-func generateSecurityReport(clusterContext string) error {
-	fmt.Println("📊 Generating security report...")
+// runFleetReport scans every cluster and writes one HTML report with an overview and drill-down.
+// A failed cluster is listed in the report instead of aborting the run.
+func runFleetReport(clusters []config.ClusterConfig, pricing *config.Pricing) error {
+	var reports []*report.ReportData
+	var failed []report.FleetFailure
+	for i, cluster := range clusters {
+		fmt.Printf("\n🔄 Scanning %s (%d/%d)...\n", cluster.Name, i+1, len(clusters))
+		reportData, err := buildClusterReport(cluster.Context, cluster.Name, pricing)
+		if err != nil {
+			fmt.Printf("❌ %s failed: %v\n", cluster.Name, err)
+			failed = append(failed, report.FleetFailure{ClusterName: cluster.Name, Error: err.Error()})
+			continue
+		}
+		reports = append(reports, reportData)
+	}
+	if len(reports) == 0 {
+		return fmt.Errorf("no cluster could be scanned")
+	}
 
-	// Get clientset and run audit
-	clientset, err := getKubernetesClient(clusterContext)
+	name := clusterGroupFlag
+	if name == "" {
+		name = "all-clusters"
+	}
+	fleet := report.NewFleetData(name, reports, failed)
+	generator, output, retention, err := newReportGenerator(report.FormatHTML, false)
 	if err != nil {
-		return fmt.Errorf("connecting to cluster: %w", err)
+		return err
 	}
-
-	sa := analyzer.NewSecurityAuditor(clientset)
-	audit, err := sa.AuditClusterSecurity(namespace)
+	outputPath, err := generator.GenerateFleet(fleet)
 	if err != nil {
-		return fmt.Errorf("auditing security: %w", err)
+		return fmt.Errorf("generating fleet report: %w", err)
 	}
+	pruneReports(output, retention)
 
-	// Calculate CIS score
-	cisResult := analyzer.CalculateCISScore(audit)
+	fmt.Printf("\n✅ Fleet report generated: %s\n", outputPath)
+	fmt.Printf("🌐 Open in browser: file://%s\n", outputPath)
+	fmt.Printf("📊 Summary: %d clusters | %d failed | worst: %s (%d/100)\n",
+		len(reports), len(failed), fleet.Clusters[0].ClusterName, fleet.Clusters[0].OverallScore)
+	return nil
+}
+
+// addReportOutputFlags adds the flags controlling report files: location, retention and template
+func addReportOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Report file; placeholders {cluster} {group} {kind} {format} {ext} {date} {timestamp}")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Reports directory (default: reports.dir from config, else ./reports)")
+	cmd.Flags().IntVar(&retentionDays, "retention-days", 0, "Delete reports older than this many days after writing (default: reports.retention_days from config)")
+	cmd.Flags().StringVar(&templateFile, "template", "", "Custom Go html/template for HTML reports (default: reports.template from config)")
+}
 
-	// Build report data with REAL values
-	reportData := &report.ReportData{
-		ClusterName:    clusterContext,
-		GeneratedAt:    time.Now(),
-		CISScore:       cisResult.Score,
-		SecurityScore:  cisResult.Score,
-		ControlsPassed: cisResult.PassedChecks,
-		ControlsFailed: cisResult.FailedChecks,
-		PodCount:       audit.TotalPodsAudited,
-		NamespaceCount: len(audit.Issues),
-	}
-
-	risks := audit.Risks
-
-	// Add critical issues with details
-	if risks.PrivilegedContainers > 0 {
-		details := extractResourceNames(audit.Issues, "privileged_container", 5)
-		reportData.CriticalIssues = append(reportData.CriticalIssues, report.IssueItem{
-			Severity:    "critical",
-			Title:       fmt.Sprintf("🔴 %d privileged containers detected", risks.PrivilegedContainers),
-			Description: "Containers with elevated privileges can escape containment and compromise the host",
-			Count:       risks.PrivilegedContainers,
-			Details:     details,
+// newReportGenerator creates a generator with the resolved output settings and the config's branding.
+// withTemplate applies --template (or reports.template); fleet reports keep the built-in layout.
+// Also returns the output and retention for pruneReports.
+func newReportGenerator(format report.ReportFormat, withTemplate bool) (*report.Generator, report.OutputOptions, int, error) {
+	generator := report.NewGenerator(format, "")
+	output, retention := reportOutput()
+	generator.SetOutput(output)
+
+	tmpl := templateFile
+	if cfg, err := config.LoadConfig(); err == nil {
+		branding := cfg.Reports.Branding
+		err := generator.SetBranding(report.Branding{
+			CompanyName:    branding.CompanyName,
+			Logo:           config.ExpandHome(branding.Logo),
+			PrimaryColor:   branding.PrimaryColor,
+			SecondaryColor: branding.SecondaryColor,
 		})
+		if err != nil {
+			return nil, output, retention, fmt.Errorf("reports.branding: %w", err)
+		}
+		if tmpl == "" {
+			tmpl = config.ExpandHome(cfg.Reports.Template)
+		}
 	}
 
-	if risks.HostPID > 0 {
-		details := extractResourceNames(audit.Issues, "host_pid", 5)
-		reportData.CriticalIssues = append(reportData.CriticalIssues, report.IssueItem{
-			Severity:    "critical",
-			Title:       fmt.Sprintf("🔴 %d containers sharing host PID namespace", risks.HostPID),
-			Description: "Host PID namespace sharing allows container processes to see all host processes",
-			Count:       risks.HostPID,
-			Details:     details,
-		})
+	if withTemplate && tmpl != "" && (format == report.FormatHTML || format == report.FormatPrint) {
+		if err := generator.LoadTemplate(tmpl); err != nil {
+			return nil, output, retention, err
+		}
 	}
+	return generator, output, retention, nil
+}
 
-	if risks.HostPathVolumes > 0 {
-		details := extractResourceNames(audit.Issues, "host_path_volume", 5)
-		reportData.CriticalIssues = append(reportData.CriticalIssues, report.IssueItem{
-			Severity:    "critical",
-			Title:       fmt.Sprintf("🔴 %d pods mounting host paths", risks.HostPathVolumes),
-			Description: "Host path volumes provide direct access to host filesystem",
-			Count:       risks.HostPathVolumes,
-			Details:     details,
-		})
+// reportOutput resolves report output settings: flags over the config's reports section over defaults.
+// Also returns the retention in days (0 keeps reports forever).
+func reportOutput() (report.OutputOptions, int) {
+	output := report.OutputOptions{
+		Path:  outputFile,
+		Dir:   report.DefaultReportsDir,
+		Group: clusterGroupFlag,
+	}
+	days := retentionDays
+	if cfg, err := config.LoadConfig(); err == nil {
+		if cfg.Reports.Dir != "" {
+			output.Dir = config.ExpandHome(cfg.Reports.Dir)
+		}
+		output.Template = cfg.Reports.FilenameTemplate
+		if days == 0 {
+			days = cfg.Reports.RetentionDays
+		}
 	}
+	if outputDir != "" {
+		output.Dir = outputDir
+	}
+	return output, days
+}
 
-	// Add warnings with details
-	if risks.HostIPC > 0 {
-		details := extractResourceNames(audit.Issues, "host_ipc", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers sharing host IPC namespace", risks.HostIPC),
-			Description: "Host IPC namespace sharing can leak sensitive information",
-			Count:       risks.HostIPC,
-			Details:     details,
-		})
+// pruneReports applies the retention policy after a report is written; failures only warn
+func pruneReports(output report.OutputOptions, retentionDays int) {
+	if retentionDays <= 0 {
+		return
+	}
+	deleted, err := report.PruneReports(output.Dir, time.Duration(retentionDays)*24*time.Hour)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		return
 	}
+	if deleted > 0 {
+		fmt.Printf("🧹 Deleted %d reports older than %d days\n", deleted, retentionDays)
+	}
+}
 
-	if risks.RunningAsRoot > 0 {
-		details := extractResourceNames(audit.Issues, "running_as_root", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers running as root", risks.RunningAsRoot),
-			Description: "Running as root increases attack surface",
-			Count:       risks.RunningAsRoot,
-			Details:     details,
-		})
+// openInBrowser opens a file with the platform's default handler
+func openInBrowser(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
 	}
+	return nil
+}
 
-	if risks.MissingResourceLimits > 0 {
-		details := extractResourceNames(audit.Issues, "missing_resource_limits", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers missing resource limits", risks.MissingResourceLimits),
-			Description: "Missing resource limits can lead to resource exhaustion",
-			Count:       risks.MissingResourceLimits,
-			Details:     details,
-		})
+// parseReportFormat maps the --format flag to a report format (html when empty)
+func parseReportFormat(name string) (report.ReportFormat, error) {
+	switch name {
+	case "", "html":
+		return report.FormatHTML, nil
+	case "json":
+		return report.FormatJSON, nil
+	case "csv":
+		return report.FormatCSV, nil
+	case "markdown", "md":
+		return report.FormatMarkdown, nil
+	case "print":
+		return report.FormatPrint, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", name)
 	}
+}
 
-	if risks.HostNetwork > 0 {
-		details := extractResourceNames(audit.Issues, "host_network", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers using host network", risks.HostNetwork),
-			Description: "Host network access bypasses network policies",
-			Count:       risks.HostNetwork,
-			Details:     details,
-		})
+// buildClusterReport runs the security, resource, cost and emergency scans behind a report
+func buildClusterReport(clusterContext string, clusterName string, pricing *config.Pricing) (*report.ReportData, error) {
+	// Get Kubernetes client
+	clientset, err := getKubernetesClient(clusterContext)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to cluster: %w", err)
 	}
 
-	if risks.PrivilegeEscalation > 0 {
-		details := extractResourceNames(audit.Issues, "privilege_escalation", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers allowing privilege escalation", risks.PrivilegeEscalation),
-			Description: "Privilege escalation can lead to container breakout",
-			Count:       risks.PrivilegeEscalation,
-			Details:     details,
-		})
+	// Run REAL security audit
+	fmt.Println("  🛡️  Running security audit...")
+	sa, err := newSecurityAuditor(clientset, clusterContext)
+	if err != nil {
+		return nil, err
 	}
+	audit, err := sa.AuditClusterSecurity(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("security audit failed: %w", err)
+	}
+	cisResult := analyzer.CalculateCISScore(audit)
 
-	if risks.AddedCapabilities > 0 {
-		details := extractResourceNames(audit.Issues, "added_capabilities", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d containers with added capabilities", risks.AddedCapabilities),
-			Description: "Unnecessary capabilities increase attack surface",
-			Count:       risks.AddedCapabilities,
-			Details:     details,
-		})
+	fmt.Println("  📦 Analyzing resources...")
+	ra := newResourceAnalyzer(clientset, clusterContext)
+	resources, err := ra.AnalyzeClusterResources(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("analyzing resources: %w", err)
 	}
 
-	if risks.DefaultServiceAccount > 0 {
-		details := extractResourceNames(audit.Issues, "default_service_account", 5)
-		reportData.WarningIssues = append(reportData.WarningIssues, report.IssueItem{
-			Severity:    "warning",
-			Title:       fmt.Sprintf("🟡 %d pods using default service account", risks.DefaultServiceAccount),
-			Description: "Default service account may have excessive permissions",
-			Count:       risks.DefaultServiceAccount,
-			Details:     details,
-		})
+	// Costs need a monthly cost or node pricing; without either the cost section stays empty
+	var costs *models.CostEstimate
+	if monthlyCost > 0 || pricing != nil {
+		fmt.Println("  💰 Estimating costs...")
+		ca := analyzer.NewCostAnalyzer(resources)
+		if pricing != nil {
+			ca.SetPricing(pricing)
+		}
+		costs, err = ca.AnalyzeCosts(monthlyCost)
+		if err != nil {
+			return nil, fmt.Errorf("analyzing costs: %w", err)
+		}
 	}
 
-	// Generate HTML report
-	generator := report.NewGenerator(report.FormatHTML, "")
-	outputPath, err := generator.GenerateSecurityHTML(reportData)
+	fmt.Println("  🚨 Checking for emergency issues...")
+	s, err := scanner.NewScanner(clusterContext)
 	if err != nil {
-		return fmt.Errorf("generating report: %w", err)
+		return nil, fmt.Errorf("connecting to cluster: %w", err)
+	}
+	emergencies, err := s.FindEmergencyIssues(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("finding emergency issues: %w", err)
 	}
 
-	fmt.Printf("\n✅ Security report generated: %s\n", outputPath)
-	fmt.Printf("🌐 Open in browser: file://%s\n", outputPath)
-	fmt.Printf("\n📊 Summary: CIS Score %d/100 | %d Critical | %d Warnings | %d Total Issues\n",
-		cisResult.Score, len(reportData.CriticalIssues), len(reportData.WarningIssues), len(audit.Issues))
-
-	return nil
+	return report.Build(clusterName, report.BuildOptions{
+		SecurityAudit:    audit,
+		CISResult:        &cisResult,
+		ResourceAnalysis: resources,
+		CostEstimate:     costs,
+		EmergencyIssues:  emergencies,
+	}), nil
 }
 
-// extractResourceNames gets top N resource names (deduplicated with counts)
-func extractResourceNames(issues []models.SecurityIssue, issueType string, limit int) []string {
-	podCounts := make(map[string]int)
+func generateSecurityReport(clusterContext string) error {
+	fmt.Println("📊 Generating security report...")
 
-	for _, issue := range issues {
-		if issue.Type == issueType {
-			key := issue.Namespace + "/" + issue.Name
-			podCounts[key]++
-		}
+	// Get clientset and run audit
+	clientset, err := getKubernetesClient(clusterContext)
+	if err != nil {
+		return fmt.Errorf("connecting to cluster: %w", err)
 	}
 
-	type podInfo struct {
-		key   string
-		count int
+	sa, err := newSecurityAuditor(clientset, clusterContext)
+	if err != nil {
+		return err
 	}
-	var pods []podInfo
-	for key, count := range podCounts {
-		pods = append(pods, podInfo{key, count})
+	audit, err := sa.AuditClusterSecurity(namespace)
+	if err != nil {
+		return fmt.Errorf("auditing security: %w", err)
 	}
-
-	// Sort by count descending
-	for i := 0; i < len(pods)-1; i++ {
-		for j := i + 1; j < len(pods); j++ {
-			if pods[j].count > pods[i].count {
-				pods[i], pods[j] = pods[j], pods[i]
-			}
-		}
+	if err := saveSecurityBaseline(clusterContext, audit); err != nil {
+		return err
+	}
+	if audit.Baseline != nil && len(audit.Issues) > 0 {
+		newFindings.Store(true)
 	}
 
-	var resources []string
-	for i := 0; i < len(pods) && i < limit; i++ {
-		parts := strings.Split(pods[i].key, "/")
-		podName := parts[1]
-		namespace := parts[0]
+	// Calculate CIS score
+	cisResult := analyzer.CalculateCISScore(audit)
 
-		if pods[i].count > 1 {
-			resources = append(resources, fmt.Sprintf("%s in namespace %s (%d issues)", podName, namespace, pods[i].count))
-		} else {
-			resources = append(resources, fmt.Sprintf("%s in namespace %s", podName, namespace))
-		}
-	}
+	reportData := report.Build(clusterContext, report.BuildOptions{
+		SecurityAudit: audit,
+		CISResult:     &cisResult,
+	})
 
-	remaining := len(pods) - limit
-	if remaining > 0 {
-		resources = append(resources, fmt.Sprintf("... and %d more pods", remaining))
+	// Generate HTML report
+	generator, output, retention, err := newReportGenerator(report.FormatHTML, true)
+	if err != nil {
+		return err
 	}
+	outputPath, err := generator.GenerateSecurityHTML(reportData)
+	if err != nil {
+		return fmt.Errorf("generating report: %w", err)
+	}
+	pruneReports(output, retention)
 
-	return resources
+	fmt.Printf("\n✅ Security report generated: %s\n", outputPath)
+	fmt.Printf("🌐 Open in browser: file://%s\n", outputPath)
+	fmt.Printf("\n📊 Summary: CIS Score %d/100 | %d Critical | %d Warnings | %d Total Issues\n",
+		cisResult.Score, len(reportData.CriticalIssues), len(reportData.WarningIssues), len(audit.Issues))
+
+	return nil
 }
 
 // ================================================================
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+	"github.com/opscart/opscart-k8s-watcher/pkg/kube"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LoadSecurityBaseline reads a baseline written by SaveSecurityBaseline
+func LoadSecurityBaseline(path string) (*models.SecurityBaseline, error) {
+	path = config.ExpandHome(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline (%s): %w", path, err)
+	}
+
+	baseline := &models.SecurityBaseline{}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, fmt.Errorf("parsing baseline (%s): %w", path, err)
+	}
+	for _, finding := range baseline.Findings {
+		if finding.Fingerprint == "" {
+			return nil, fmt.Errorf("baseline %s has findings without fingerprints: save it again with --save-baseline", path)
+		}
+	}
+	return baseline, nil
+}
+
+// SaveSecurityBaseline writes the audit's reported findings as a baseline. Suppressed
+// findings are left out so they resurface when their exception expires.
+func SaveSecurityBaseline(path string, clusterName string, audit *models.SecurityAudit) error {
+	path = config.ExpandHome(path)
+
+	baseline := models.SecurityBaseline{
+		Cluster:   clusterName,
+		CreatedAt: time.Now().UTC(),
+		Findings:  audit.Issues,
+	}
+	if baseline.Findings == nil {
+		baseline.Findings = []models.SecurityIssue{}
+	}
+
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding baseline: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating baseline directory: %w", err)
+		}
+	}
+	if err := config.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing baseline: %w", err)
+	}
+	return nil
+}
+
+// findingFingerprint identifies a finding across pod restarts, rollouts and CronJob runs: it
+// hashes the issue type, namespace, owning workload, container and description, but not the
+// pod name. cronJobs maps Jobs to their CronJob (see kube.JobCronJobs).
+func findingFingerprint(pod corev1.Pod, cronJobs map[string]string, issue models.SecurityIssue) string {
+	kind, name := kube.PodWorkloadWithCronJobs(pod, cronJobs)
+	container := ""
+	if issue.Resource == "container" {
+		container = strings.TrimPrefix(issue.Name, pod.Name+"/")
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		issue.Type, pod.Namespace, kind, name, container, issue.Description,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fixedSinceBaseline lists baseline findings (one per fingerprint) not seen in this audit.
+// A namespace-scoped audit only compares findings from that namespace.
+func fixedSinceBaseline(baseline *models.SecurityBaseline, namespace string, seen map[string]bool) []models.SecurityIssue {
+	fixed := []models.SecurityIssue{}
+	listed := make(map[string]bool)
+	for _, finding := range baseline.Findings {
+		if namespace != "" && finding.Namespace != namespace {
+			continue
+		}
+		if seen[finding.Fingerprint] || listed[finding.Fingerprint] {
+			continue
+		}
+		listed[finding.Fingerprint] = true
+		fixed = append(fixed, finding)
+	}
+	return fixed
+}
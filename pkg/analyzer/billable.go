@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// collectBillableResources records volumes, LoadBalancer Services and Ingresses for cost analysis,
+// and adds them to their showback group when grouping. Best-effort: a failed list leaves that category empty.
+func (ra *ResourceAnalyzer) collectBillableResources(namespace string, analysis *models.ClusterResourceAnalysis, groups *groupTracker) {
+	if pvcList, err := ra.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, pvc := range pvcList.Items {
+			size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+				size = capacity
+			}
+			storageClass := ""
+			if pvc.Spec.StorageClassName != nil {
+				storageClass = *pvc.Spec.StorageClassName
+			}
+			accessMode := ""
+			if len(pvc.Spec.AccessModes) > 0 {
+				accessMode = string(pvc.Spec.AccessModes[0])
+			}
+
+			analysis.Volumes = append(analysis.Volumes, models.PVCDetail{
+				PVCInfo: models.PVCInfo{
+					Name:         pvc.Name,
+					Namespace:    pvc.Namespace,
+					Status:       string(pvc.Status.Phase),
+					StorageClass: storageClass,
+					Size:         size.String(),
+					VolumeName:   pvc.Spec.VolumeName,
+				},
+				Age:        models.Duration(time.Since(pvc.CreationTimestamp.Time)),
+				AccessMode: accessMode,
+			})
+
+			if groups != nil && pvc.Status.Phase == corev1.ClaimBound {
+				group := groups.get(groups.groupOf(pvc.ObjectMeta), pvc.Namespace)
+				group.StorageGB[storageClass] += float64(size.Value()) / (1024 * 1024 * 1024)
+			}
+		}
+	}
+
+	// Released PVs keep their disk (and bill) after the claim is gone
+	if pvList, err := ra.clientset.CoreV1().PersistentVolumes().List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, pv := range pvList.Items {
+			if pv.Status.Phase != corev1.VolumeReleased {
+				continue
+			}
+			claimNamespace, claimName := "", ""
+			if pv.Spec.ClaimRef != nil {
+				claimNamespace, claimName = pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name
+			}
+			if namespace != "" && claimNamespace != namespace {
+				continue
+			}
+
+			capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+			analysis.Volumes = append(analysis.Volumes, models.PVCDetail{
+				PVCInfo: models.PVCInfo{
+					Name:         claimName,
+					Namespace:    claimNamespace,
+					Status:       string(corev1.VolumeReleased),
+					StorageClass: pv.Spec.StorageClassName,
+					Size:         capacity.String(),
+					VolumeName:   pv.Name,
+				},
+				Age: models.Duration(time.Since(pv.CreationTimestamp.Time)),
+			})
+
+			// The claim is gone, so its namespace decides the group
+			if groups != nil {
+				group := groups.get(groups.groupOf(metav1.ObjectMeta{Namespace: claimNamespace}), claimNamespace)
+				group.StorageGB[pv.Spec.StorageClassName] += float64(capacity.Value()) / (1024 * 1024 * 1024)
+			}
+		}
+	}
+
+	if svcList, err := ra.clientset.CoreV1().Services(namespace).List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, svc := range svcList.Items {
+			if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+				continue
+			}
+			externalIP := ""
+			if len(svc.Status.LoadBalancer.Ingress) > 0 {
+				externalIP = svc.Status.LoadBalancer.Ingress[0].IP
+				if externalIP == "" {
+					externalIP = svc.Status.LoadBalancer.Ingress[0].Hostname
+				}
+			}
+
+			var ports []int32
+			for _, port := range svc.Spec.Ports {
+				ports = append(ports, port.Port)
+			}
+
+			analysis.LoadBalancers = append(analysis.LoadBalancers, models.ServiceInfo{
+				Name:       svc.Name,
+				Namespace:  svc.Namespace,
+				Type:       string(svc.Spec.Type),
+				ClusterIP:  svc.Spec.ClusterIP,
+				ExternalIP: externalIP,
+				Ports:      ports,
+			})
+
+			if groups != nil {
+				groups.get(groups.groupOf(svc.ObjectMeta), svc.Namespace).LoadBalancers++
+			}
+		}
+	}
+
+	if ingList, err := ra.clientset.NetworkingV1().Ingresses(namespace).List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, ing := range ingList.Items {
+			var hosts []string
+			for _, rule := range ing.Spec.Rules {
+				if rule.Host != "" {
+					hosts = append(hosts, rule.Host)
+				}
+			}
+
+			analysis.Ingresses = append(analysis.Ingresses, models.IngressInfo{
+				Name:       ing.Name,
+				Namespace:  ing.Namespace,
+				Hosts:      hosts,
+				TLSEnabled: len(ing.Spec.TLS) > 0,
+			})
+
+			if groups != nil {
+				groups.get(groups.groupOf(ing.ObjectMeta), ing.Namespace).Ingresses++
+			}
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+)
+
+const (
+	// jumpBaselineAge is how old a stored run must be to serve as the week-over-week baseline
+	jumpBaselineAge = 7 * 24 * time.Hour
+
+	// minJumpBaselineAge is how old a run must be to stand in for a missing week-old one
+	minJumpBaselineAge = 24 * time.Hour
+
+	// costHistoryRetention bounds the stored history per cluster
+	costHistoryRetention = 90 * 24 * time.Hour
+
+	// minJumpAmount ignores percentage jumps on trivially small costs
+	minJumpAmount = 10.0
+)
+
+// CostHistoryPath returns where costs runs for a cluster are stored
+func CostHistoryPath(clusterName string) string {
+	home, _ := os.UserHomeDir()
+	name := strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(clusterName)
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(home, ".opscart", "history", name+"-costs.json")
+}
+
+// LoadCostHistory reads a cluster's stored costs runs (empty when none exist yet)
+func LoadCostHistory(clusterName string) ([]models.CostSnapshot, error) {
+	data, err := os.ReadFile(CostHistoryPath(clusterName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cost history: %w", err)
+	}
+
+	var history []models.CostSnapshot
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("parsing cost history: %w", err)
+	}
+	return history, nil
+}
+
+// SaveCostHistory appends this run to the cluster's history, dropping runs past retention
+func SaveCostHistory(clusterName string, history []models.CostSnapshot, estimate *models.CostEstimate) error {
+	now := time.Now()
+	var kept []models.CostSnapshot
+	for _, snapshot := range history {
+		if now.Sub(snapshot.Timestamp) <= costHistoryRetention {
+			kept = append(kept, snapshot)
+		}
+	}
+	kept = append(kept, newCostSnapshot(estimate, now))
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cost history: %w", err)
+	}
+
+	path := CostHistoryPath(clusterName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+	if err := config.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing cost history: %w", err)
+	}
+	return nil
+}
+
+// newCostSnapshot records the per-namespace and per-group totals of a run
+func newCostSnapshot(estimate *models.CostEstimate, now time.Time) models.CostSnapshot {
+	snapshot := models.CostSnapshot{
+		Timestamp:  now,
+		Method:     estimate.Method,
+		TotalCost:  estimate.TotalClusterCost,
+		Namespaces: make(map[string]float64),
+		GroupBy:    estimate.GroupBy,
+	}
+	for _, ns := range estimate.NamespaceCosts {
+		snapshot.Namespaces[ns.Name] = ns.EstimatedCost.Best
+	}
+	if len(estimate.GroupCosts) > 0 {
+		snapshot.Groups = make(map[string]float64)
+		for _, group := range estimate.GroupCosts {
+			snapshot.Groups[group.Name] = group.TotalCost
+		}
+	}
+	return snapshot
+}
+
+// CheckBudgets flags namespaces and groups over their budget or up more than the jump
+// threshold since last week's run. Runs priced differently are not compared.
+func CheckBudgets(estimate *models.CostEstimate, budgets config.BudgetConfig, history []models.CostSnapshot) {
+	now := time.Now()
+	baseline := jumpBaseline(history, estimate.Method, now)
+	interval := ""
+	if baseline != nil {
+		interval = formatInterval(now.Sub(baseline.Timestamp))
+	}
+
+	var alerts []models.BudgetAlert
+	for _, ns := range estimate.NamespaceCosts {
+		cost := ns.EstimatedCost.Best
+		if budget, ok := budgets.Namespaces[ns.Name]; ok && budget > 0 && cost > budget {
+			alerts = append(alerts, overBudgetAlert("namespace", ns.Name, cost, budget))
+		}
+		if baseline != nil {
+			if alert, ok := costJumpAlert("namespace", ns.Name, cost, baseline.Namespaces, baseline.Timestamp, interval, budgets.JumpThreshold); ok {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	// Label budgets apply to groups when grouping by that label
+	alerts = append(alerts, labelBudgetAlerts(estimate, budgets)...)
+	for _, group := range estimate.GroupCosts {
+		if baseline != nil && baseline.GroupBy == estimate.GroupBy {
+			if alert, ok := costJumpAlert(estimate.GroupBy, group.Name, group.TotalCost, baseline.Groups, baseline.Timestamp, interval, budgets.JumpThreshold); ok {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	estimate.BudgetAlerts = sortBudgetAlerts(alerts)
+}
+
+// UncheckedLabelBudgets returns the label keys with budgets that CheckBudgets could not
+// evaluate because the estimate is not grouped by them, sorted
+func UncheckedLabelBudgets(estimate *models.CostEstimate, budgets config.BudgetConfig) []string {
+	var keys []string
+	for key := range budgets.Labels {
+		if estimate.GroupBy != "label:"+key {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddLabelBudgetAlerts adds the label budget overruns of grouped, an estimate of the same
+// run grouped by one of the UncheckedLabelBudgets keys, to the estimate's alerts
+func AddLabelBudgetAlerts(estimate, grouped *models.CostEstimate, budgets config.BudgetConfig) {
+	estimate.BudgetAlerts = sortBudgetAlerts(append(estimate.BudgetAlerts, labelBudgetAlerts(grouped, budgets)...))
+}
+
+// labelBudgetAlerts flags groups over their budget when the estimate is grouped by a budgeted label
+func labelBudgetAlerts(estimate *models.CostEstimate, budgets config.BudgetConfig) []models.BudgetAlert {
+	labelKey, byLabel := strings.CutPrefix(estimate.GroupBy, "label:")
+	if !byLabel {
+		return nil
+	}
+	var alerts []models.BudgetAlert
+	for _, group := range estimate.GroupCosts {
+		if budget, ok := budgets.Labels[labelKey][group.Name]; ok && budget > 0 && group.TotalCost > budget {
+			alerts = append(alerts, overBudgetAlert(estimate.GroupBy, group.Name, group.TotalCost, budget))
+		}
+	}
+	return alerts
+}
+
+// sortBudgetAlerts puts overruns first, then the largest changes
+func sortBudgetAlerts(alerts []models.BudgetAlert) []models.BudgetAlert {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if alerts[i].Type != alerts[j].Type {
+			return alerts[i].Type == "over_budget"
+		}
+		return alerts[i].ChangePercent > alerts[j].ChangePercent
+	})
+	return alerts
+}
+
+// OverBudget reports whether any namespace or group exceeded its budget
+func OverBudget(estimate *models.CostEstimate) bool {
+	for _, alert := range estimate.BudgetAlerts {
+		if alert.Type == "over_budget" {
+			return true
+		}
+	}
+	return false
+}
+
+// jumpBaseline returns the newest run at least a week old, else the oldest run if it is at
+// least a day old (nil when there is no comparable run)
+func jumpBaseline(history []models.CostSnapshot, method string, now time.Time) *models.CostSnapshot {
+	var weekOld, oldest *models.CostSnapshot
+	for i := range history {
+		snapshot := &history[i]
+		if snapshot.Method != method {
+			continue
+		}
+		if oldest == nil || snapshot.Timestamp.Before(oldest.Timestamp) {
+			oldest = snapshot
+		}
+		if now.Sub(snapshot.Timestamp) >= jumpBaselineAge && (weekOld == nil || snapshot.Timestamp.After(weekOld.Timestamp)) {
+			weekOld = snapshot
+		}
+	}
+	if weekOld != nil {
+		return weekOld
+	}
+	if oldest != nil && now.Sub(oldest.Timestamp) >= minJumpBaselineAge {
+		return oldest
+	}
+	return nil
+}
+
+// formatInterval describes the time between the baseline run and now in days
+func formatInterval(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// overBudgetAlert describes a monthly cost above its ceiling
+func overBudgetAlert(scope, name string, cost, budget float64) models.BudgetAlert {
+	over := (cost - budget) / budget * 100
+	return models.BudgetAlert{
+		Type:          "over_budget",
+		Scope:         scope,
+		Name:          name,
+		Severity:      "high",
+		Cost:          cost,
+		Budget:        budget,
+		ChangePercent: over,
+		Message:       fmt.Sprintf("$%s/month is %.0f%% over its $%s budget", formatCurrency(cost), over, formatCurrency(budget)),
+	}
+}
+
+// costJumpAlert describes an increase above threshold percent since the baseline run,
+// interval ago
+func costJumpAlert(scope, name string, cost float64, previous map[string]float64, since time.Time, interval string, threshold float64) (models.BudgetAlert, bool) {
+	prev, ok := previous[name]
+	if !ok || prev <= 0 || cost-prev < minJumpAmount {
+		return models.BudgetAlert{}, false
+	}
+	change := (cost - prev) / prev * 100
+	if change <= threshold {
+		return models.BudgetAlert{}, false
+	}
+	return models.BudgetAlert{
+		Type:          "cost_jump",
+		Scope:         scope,
+		Name:          name,
+		Severity:      "medium",
+		Cost:          cost,
+		PreviousCost:  prev,
+		PreviousRun:   since.Format("2006-01-02"),
+		Interval:      interval,
+		ChangePercent: change,
+		Message: fmt.Sprintf("up %.0f%% in %s, from $%s on %s to $%s/month",
+			change, interval, formatCurrency(prev), since.Format("Jan 2"), formatCurrency(cost)),
+	}, true
+}
@@ -10,20 +10,21 @@ import (
 
 // CISControl represents a CIS Kubernetes benchmark control
 type CISControl struct {
-	ID          string
-	Description string
-	Weight      float64
-	Passed      bool
-	Finding     string
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight"`
+	Passed      bool    `json:"passed"`
+	Finding     string  `json:"finding"`
+	IssueType   string  `json:"issue_type,omitempty"` // SecurityIssue.Type the control checks ("" when not audited)
 }
 
 // CISResult holds the CIS compliance score
 type CISResult struct {
-	Score        int
-	TotalChecks  int
-	PassedChecks int
-	FailedChecks int
-	Controls     []CISControl
+	Score        int          `json:"score"`
+	TotalChecks  int          `json:"total_checks"`
+	PassedChecks int          `json:"passed_checks"`
+	FailedChecks int          `json:"failed_checks"`
+	Controls     []CISControl `json:"controls"`
 }
 
 // CalculateCISScore evaluates cluster against CIS Kubernetes benchmarks
@@ -36,6 +37,7 @@ func CalculateCISScore(audit *models.SecurityAudit) CISResult {
 			Weight:      10.0,
 			Passed:      audit.Risks.PrivilegedContainers == 0,
 			Finding:     fmt.Sprintf("%d privileged containers", audit.Risks.PrivilegedContainers),
+			IssueType:   "privileged_container",
 		},
 		{
 			ID:          "5.2.2",
@@ -43,6 +45,7 @@ func CalculateCISScore(audit *models.SecurityAudit) CISResult {
 			Weight:      8.0,
 			Passed:      audit.Risks.HostPID == 0,
 			Finding:     fmt.Sprintf("%d pods using hostPID", audit.Risks.HostPID),
+			IssueType:   "host_pid",
 		},
 		{
 			ID:          "5.2.3",
@@ -50,6 +53,7 @@ func CalculateCISScore(audit *models.SecurityAudit) CISResult {
 			Weight:      7.0,
 			Passed:      audit.Risks.HostIPC == 0,
 			Finding:     fmt.Sprintf("%d pods using hostIPC", audit.Risks.HostIPC),
+			IssueType:   "host_ipc",
 		},
 		{
 			ID:          "5.2.4",
@@ -57,6 +61,7 @@ func CalculateCISScore(audit *models.SecurityAudit) CISResult {
 			Weight:      8.0,
 			Passed:      audit.Risks.HostNetwork == 0,
 			Finding:     fmt.Sprintf("%d pods using hostNetwork", audit.Risks.HostNetwork),
+			IssueType:   "host_network",
 		},
 		{
 			ID:          "5.2.6",
@@ -64,6 +69,7 @@ func CalculateCISScore(audit *models.SecurityAudit) CISResult {
 			Weight:      6.0,
 			Passed:      audit.Risks.RunningAsRoot == 0,
 			Finding:     fmt.Sprintf("%d containers as root", audit.Risks.RunningAsRoot),
+			IssueType:   "running_as_root",
 		},
 		// Network Policies - not currently tracked, always passes
 		{
@@ -79,6 +85,7 @@ func CalculateCISScore(audit *models.SecurityAudit) CISResult {
 			Weight:      4.0,
 			Passed:      audit.Risks.MissingResourceLimits == 0,
 			Finding:     fmt.Sprintf("%d containers missing limits", audit.Risks.MissingResourceLimits),
+			IssueType:   "missing_resource_limits",
 		},
 	}
 
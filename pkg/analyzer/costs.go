@@ -4,12 +4,25 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 )
 
+const (
+	// Best-case savings rates of the spot and HPA scenarios, also used to price their patches
+	spotSavingsRate = 0.70
+	hpaSavingsRate  = 0.25
+)
+
 // CostAnalyzer performs cost analysis and optimization scenario modeling
 type CostAnalyzer struct {
 	resourceAnalysis *models.ClusterResourceAnalysis
+
+	// Optional node pricing (see SetPricing); replaces the proportional split
+	pricing *config.Pricing
+
+	// Optional security findings attributed to showback groups (see SetSecurityFindings)
+	securityIssues []models.SecurityIssue
 }
 
 // NewCostAnalyzer creates a new cost analyzer from resource analysis
@@ -19,25 +32,33 @@ func NewCostAnalyzer(resourceAnalysis *models.ClusterResourceAnalysis) *CostAnal
 	}
 }
 
-// AnalyzeCosts calculates cost estimates with ranges and optimization scenarios
-func (ca *CostAnalyzer) AnalyzeCosts(totalClusterCost float64) (*models.CostEstimate, error) {
-	if totalClusterCost <= 0 {
-		return nil, fmt.Errorf("total cluster cost must be greater than 0")
+// AnalyzeCosts calculates cost estimates with ranges and optimization scenarios.
+// computeCost is the monthly node spend; storage and networking are priced on top of
+// it. With pricing set, node prices determine compute and computeCost is ignored.
+func (ca *CostAnalyzer) AnalyzeCosts(computeCost float64) (*models.CostEstimate, error) {
+	if ca.pricing != nil {
+		return ca.analyzeNodeCosts()
+	}
+
+	if computeCost <= 0 {
+		return nil, fmt.Errorf("monthly compute cost must be greater than 0")
 	}
 
 	estimate := &models.CostEstimate{
-		TotalClusterCost: totalClusterCost,
+		TotalClusterCost: computeCost,
 		Method:           "request_proportional",
 		Confidence:       "medium",
 		Assumptions:      ca.generateAssumptions(),
 		Disclaimers:      ca.generateDisclaimers(),
 	}
 
-	// Calculate namespace costs
-	estimate.NamespaceCosts = ca.calculateNamespaceCosts(totalClusterCost)
+	// Calculate namespace costs, then add storage and networking on top of compute
+	estimate.NamespaceCosts = ca.calculateNamespaceCosts(computeCost)
+	ca.addStorageAndNetworkCosts(estimate)
+	ca.calculateGroupCosts(estimate, nil)
 
 	// Generate optimization scenarios
-	estimate.OptimizationScenarios = ca.generateOptimizationScenarios(totalClusterCost)
+	estimate.OptimizationScenarios = ca.generateOptimizationScenarios(computeCost)
 
 	// Calculate total savings potential
 	estimate.TotalSavingsPotential = ca.calculateTotalSavings(estimate.OptimizationScenarios)
@@ -228,7 +249,7 @@ func (ca *CostAnalyzer) generateSpotScenario(totalCost float64) *models.Optimiza
 
 	// Spot instances typically save 70% (range: 60-80%)
 	savingsLow := spotEligibleCost * 0.60
-	savingsBest := spotEligibleCost * 0.70
+	savingsBest := spotEligibleCost * spotSavingsRate
 	savingsHigh := spotEligibleCost * 0.80
 
 	return &models.OptimizationScenario{
@@ -287,7 +308,7 @@ func (ca *CostAnalyzer) generateIdleScenario(totalCost float64) *models.Optimiza
 
 				idleReason := ""
 				if ns.IdlePods > 0 {
-					idleReason = fmt.Sprintf("%d idle pods", ns.IdlePods)
+					idleReason = fmt.Sprintf("%d/%d pods idle", ns.IdlePods, ns.PodCount)
 				} else {
 					idleReason = "high waste score"
 				}
@@ -345,6 +366,11 @@ func (ca *CostAnalyzer) generateIdleScenario(totalCost float64) *models.Optimiza
 
 // generateRightsizeScenario calculates savings from right-sizing
 func (ca *CostAnalyzer) generateRightsizeScenario(totalCost float64) *models.OptimizationScenario {
+	// Prefer concrete numbers from usage history when Prometheus is configured
+	if ca.resourceAnalysis.HistorySource != "" {
+		return ca.generateHistoryRightsizeScenario(totalCost)
+	}
+
 	oversizedCost := 0.0
 	oversizedCPU := 0.0
 	oversizedNamespaces := []string{}
@@ -400,6 +426,65 @@ func (ca *CostAnalyzer) generateRightsizeScenario(totalCost float64) *models.Opt
 	}
 }
 
+// generateHistoryRightsizeScenario prices history-based right-sizing recommendations
+func (ca *CostAnalyzer) generateHistoryRightsizeScenario(totalCost float64) *models.OptimizationScenario {
+	analysis := ca.resourceAnalysis
+	if analysis.TotalCPUCores <= 0 || analysis.TotalMemoryGB <= 0 {
+		return nil
+	}
+
+	// Same 50/50 CPU/memory split used for namespace allocation
+	costPerCore := totalCost * 0.5 / analysis.TotalCPUCores
+	costPerGB := totalCost * 0.5 / analysis.TotalMemoryGB
+
+	currentCost := 0.0
+	savings := 0.0
+	freedCPU := 0.0
+	freedMemory := 0.0
+	var commands []string
+
+	for _, rec := range analysis.Rightsizing {
+		saved := rec.CPUCoresSaved*costPerCore + rec.MemoryGBSaved*costPerGB
+		if saved <= 0 {
+			continue
+		}
+		replicas := float64(rec.Replicas)
+		currentCost += (rec.Current.CPURequest*costPerCore + rec.Current.MemoryRequest*costPerGB) * replicas
+		savings += saved
+		freedCPU += rec.CPUCoresSaved
+		freedMemory += rec.MemoryGBSaved
+		commands = append(commands, setResourcesCommand(rec))
+	}
+
+	if savings <= 0 {
+		return nil
+	}
+
+	// Freed requests only turn into savings once nodes can be removed - discount the low end
+	actions := []string{fmt.Sprintf("Apply the %d recommendations below (full YAML diffs: opscart-scan optimize)", len(commands))}
+	for i, command := range commands {
+		if i == 5 {
+			actions = append(actions, fmt.Sprintf("... and %d more", len(commands)-5))
+			break
+		}
+		actions = append(actions, command)
+	}
+	actions = append(actions, "Let the cluster autoscaler (or a manual node pool resize) remove freed nodes")
+
+	return &models.OptimizationScenario{
+		Name:        "Right-size Workloads (usage history)",
+		Description: fmt.Sprintf("Set requests to p95 usage over %s for %d containers", analysis.HistoryWindow, len(commands)),
+		CurrentCost: models.CostRange{Low: currentCost, Best: currentCost, High: currentCost},
+		AfterCost:   models.CostRange{Low: currentCost - savings, Best: currentCost - savings, High: currentCost - savings*0.6},
+		Savings:     models.CostRange{Low: savings * 0.6, Best: savings, High: savings},
+		Impact:      fmt.Sprintf("Free %.1f CPU cores and %.1f GB of requests", freedCPU, freedMemory),
+		Effort:      "Low",
+		Risk:        "Medium",
+		Timeline:    "1 week",
+		Actions:     actions,
+	}
+}
+
 // calculateTotalSavings sums up all optimization scenario savings
 func (ca *CostAnalyzer) calculateTotalSavings(scenarios []models.OptimizationScenario) models.CostRange {
 	var totalLow, totalBest, totalHigh float64
@@ -420,8 +505,9 @@ func (ca *CostAnalyzer) calculateTotalSavings(scenarios []models.OptimizationSce
 // generateAssumptions creates the list of assumptions
 func (ca *CostAnalyzer) generateAssumptions() []string {
 	return []string{
-		"Cost allocation based on CPU + Memory resource requests (not actual usage)",
-		"Does NOT include: storage costs, networking egress, load balancers, public IPs",
+		"Compute allocation based on CPU + Memory resource requests (not actual usage)",
+		"Provided monthly cost covers compute; storage (per StorageClass $/GB-month) and LoadBalancer/Ingress costs are added on top",
+		"Does NOT include: networking egress, public IPs",
 		"Spot instance savings assume 70% discount vs on-demand",
 		"Assumes proportional sharing of node costs across pods",
 		"Cluster cost provided by user - not validated against actual Azure billing",
@@ -478,9 +564,9 @@ func (ca *CostAnalyzer) generateHPAScenario(totalCost float64) *models.Optimizat
 	}
 
 	// HPA typically saves 20-40% during off-peak hours (assume 40% of the time)
-	savingsLow := hpaCandidateCost * 0.15  // 15% conservative
-	savingsBest := hpaCandidateCost * 0.25 // 25% realistic
-	savingsHigh := hpaCandidateCost * 0.35 // 35% optimistic
+	savingsLow := hpaCandidateCost * 0.15            // 15% conservative
+	savingsBest := hpaCandidateCost * hpaSavingsRate // 25% realistic
+	savingsHigh := hpaCandidateCost * 0.35           // 35% optimistic
 
 	return &models.OptimizationScenario{
 		Name:        "Add Horizontal Pod Autoscalers",
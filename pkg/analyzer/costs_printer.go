@@ -1,19 +1,26 @@
 package analyzer
 
 import (
-	"encoding/json"
+	"bytes"
+	"encoding/csv"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 )
 
 // PrintCostAnalysis displays cost analysis in professional format
-func PrintCostAnalysis(estimate *models.CostEstimate, format string) {
+func PrintCostAnalysis(clusterName string, estimate *models.CostEstimate, format string) {
 	if format == "json" {
-		printCostJSON(estimate)
+		printCostJSON(clusterName, estimate)
+		return
+	}
+	if format == "csv" {
+		printCostCSV(clusterName, estimate)
 		return
 	}
 
@@ -35,23 +42,68 @@ func printCostTable(estimate *models.CostEstimate) {
 	fmt.Println()
 
 	// Cluster cost
-	fmt.Printf("Total Cluster Cost (provided): $%s/month\n", formatCurrency(estimate.TotalClusterCost))
+	computeSource := "provided"
+	if estimate.Method == "node_pricing" {
+		computeSource = "node pricing"
+	}
+	fmt.Printf("Total Cluster Cost: $%s/month\n", formatCurrency(estimate.TotalClusterCost))
+	fmt.Printf("   Compute (%s): $%s\n", computeSource, formatCurrency(estimate.ComputeCost))
+	if estimate.UnusedStorageCost > 0 {
+		fmt.Printf("   Storage: $%s (incl. $%s on released volumes)\n",
+			formatCurrency(estimate.StorageCost), formatCurrency(estimate.UnusedStorageCost))
+	} else {
+		fmt.Printf("   Storage: $%s\n", formatCurrency(estimate.StorageCost))
+	}
+	fmt.Printf("   Network: $%s\n", formatCurrency(estimate.NetworkCost))
 	fmt.Printf("Allocation Method: %s\n", estimate.Method)
 	fmt.Printf("Confidence Level: %s\n\n", estimate.Confidence)
 
+	if len(estimate.BudgetAlerts) > 0 {
+		printBudgetAlerts(estimate)
+	}
+
+	if len(estimate.NodeCosts) > 0 {
+		printNodeCosts(estimate)
+	}
+
+	if len(estimate.GroupCosts) > 0 {
+		printGroupCosts(estimate)
+	}
+
 	// Namespace cost allocation
 	fmt.Println("NAMESPACE COST ALLOCATION:")
 	fmt.Println()
 
+	breakdown := estimate.StorageCost > 0 || estimate.NetworkCost > 0
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAMESPACE\tEST. COST/MONTH\tBASIS\tCONFIDENCE")
+	if breakdown {
+		fmt.Fprintln(w, "NAMESPACE\tEST. COST/MONTH\tCOMPUTE\tSTORAGE\tNETWORK\tBASIS\tCONFIDENCE")
+	} else {
+		fmt.Fprintln(w, "NAMESPACE\tEST. COST/MONTH\tBASIS\tCONFIDENCE")
+	}
 	fmt.Fprintln(w, strings.Repeat("─", 100))
 
 	for _, nsCost := range estimate.NamespaceCosts {
 		costRange := formatCostRange(nsCost.EstimatedCost)
 		basis := fmt.Sprintf("%.1f%% share", nsCost.WeightedShare*100)
 		confidence := determineConfidence(nsCost)
+		if estimate.Method == "node_pricing" {
+			basis = fmt.Sprintf("%.1f%% of cluster (node rates)", nsCost.WeightedShare*100)
+			confidence = "High"
+		}
 
+		if breakdown {
+			fmt.Fprintf(w, "%s\t%s\t$%s\t$%s\t$%s\t%s\t%s\n",
+				nsCost.Name,
+				costRange,
+				formatCurrency(nsCost.ComputeCost),
+				formatCurrency(nsCost.StorageCost),
+				formatCurrency(nsCost.NetworkCost),
+				basis,
+				confidence)
+			continue
+		}
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
 			nsCost.Name,
 			costRange,
@@ -61,6 +113,13 @@ func printCostTable(estimate *models.CostEstimate) {
 	w.Flush()
 	fmt.Println()
 
+	if len(estimate.StorageCosts) > 0 {
+		printStorageCosts(estimate)
+	}
+	if len(estimate.NetworkCosts) > 0 {
+		printNetworkCosts(estimate)
+	}
+
 	// Optimization scenarios
 	if len(estimate.OptimizationScenarios) > 0 {
 		fmt.Println("OPTIMIZATION SCENARIOS:")
@@ -81,7 +140,8 @@ func printCostTable(estimate *models.CostEstimate) {
 		fmt.Printf("   Current: $%s/month\n", formatCurrency(estimate.TotalClusterCost))
 		fmt.Printf("   After:   $%s/month (save %.0f%%)\n\n", formatCurrency(bestCase), pctSavings)
 	} else {
-		fmt.Println("✅ No major optimization opportunities found - cluster looks efficient!\n")
+		fmt.Println("✅ No major optimization opportunities found - cluster looks efficient!")
+		fmt.Println()
 	}
 
 	// Assumptions
@@ -99,6 +159,226 @@ func printCostTable(estimate *models.CostEstimate) {
 	fmt.Println("   4. Track actual savings with Azure Cost Management")
 }
 
+// printBudgetAlerts lists budget overruns and cost jumps
+func printBudgetAlerts(estimate *models.CostEstimate) {
+	fmt.Println("🚨 BUDGET ALERTS:")
+	for _, alert := range estimate.BudgetAlerts {
+		icon := "🔴"
+		if alert.Type == "cost_jump" {
+			icon = "📈"
+		}
+		fmt.Printf("   %s %s %s: %s\n", icon, alert.Scope, alert.Name, alert.Message)
+	}
+	fmt.Println()
+}
+
+// printNodeCosts prints per-node prices and the allocated vs idle capacity split
+func printNodeCosts(estimate *models.CostEstimate) {
+	fmt.Println("NODE COSTS:")
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tINSTANCE TYPE\tCAPACITY\tZONE\t$/HOUR\t$/MONTH\tALLOCATED\tIDLE")
+	fmt.Fprintln(w, strings.Repeat("─", 110))
+
+	for _, node := range estimate.NodeCosts {
+		if !node.Priced {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t-\t-\t-\t- (no price)\n",
+				node.Name, valueOrDash(node.InstanceType), node.CapacityType, valueOrDash(node.Zone))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t$%.3f\t$%s\t$%s\t$%s\n",
+			node.Name,
+			valueOrDash(node.InstanceType),
+			node.CapacityType,
+			valueOrDash(node.Zone),
+			node.HourlyPrice,
+			formatCurrency(node.MonthlyCost),
+			formatCurrency(node.Allocated),
+			formatCurrency(node.Idle))
+	}
+	w.Flush()
+	fmt.Println()
+
+	allocatedPct := estimate.AllocatedCost / estimate.ComputeCost * 100
+	fmt.Printf("Allocated to workloads: $%s/month (%.0f%%)\n", formatCurrency(estimate.AllocatedCost), allocatedPct)
+	fmt.Printf("Idle capacity:          $%s/month (%.0f%%) - not charged to any namespace\n\n",
+		formatCurrency(estimate.IdleCapacityCost), 100-allocatedPct)
+}
+
+// printGroupCosts prints showback by label/annotation value
+func printGroupCosts(estimate *models.CostEstimate) {
+	fmt.Printf("COST BY %s:\n", strings.ToUpper(estimate.GroupBy))
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tPODS\tCPU REQ\tMEM REQ\tCOMPUTE\tSTORAGE\tNETWORK\tTOTAL\tSHARE\tWASTE\tSECURITY (C/H/M)\tNAMESPACES")
+	fmt.Fprintln(w, strings.Repeat("─", 130))
+
+	for _, group := range estimate.GroupCosts {
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.1f GB\t$%s\t$%s\t$%s\t$%s\t%.1f%%\t$%s\t%d/%d/%d\t%s\n",
+			group.Name,
+			group.PodCount,
+			group.CPUCoresRequested,
+			group.MemoryGBRequested,
+			formatCurrency(group.ComputeCost),
+			formatCurrency(group.StorageCost),
+			formatCurrency(group.NetworkCost),
+			formatCurrency(group.TotalCost),
+			group.Share*100,
+			formatCurrency(group.WasteCost),
+			group.SecurityFindings["critical"],
+			group.SecurityFindings["high"],
+			group.SecurityFindings["medium"],
+			formatList(group.Namespaces))
+	}
+	w.Flush()
+	fmt.Println()
+	fmt.Printf("   %q groups pods, volumes and Services without the key on the object or its namespace\n\n", unallocatedGroup)
+}
+
+// costCSV guards stdout so multi-cluster runs produce one table: the header once, then
+// each cluster's rows in a single write
+var costCSV struct {
+	sync.Mutex
+	headerWritten bool
+}
+
+// printCostCSV outputs one row per showback group (or namespace) for spreadsheet import
+func printCostCSV(clusterName string, estimate *models.CostEstimate) {
+	costCSV.Lock()
+	defer costCSV.Unlock()
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	defer func() {
+		writer.Flush()
+		os.Stdout.Write(buf.Bytes())
+	}()
+
+	if !costCSV.headerWritten {
+		costCSV.headerWritten = true
+		writer.Write([]string{
+			"cluster", "group_by", "group", "namespaces", "pods", "cpu_cores_requested", "memory_gb_requested",
+			"compute_cost", "storage_cost", "network_cost", "total_cost", "share_percent",
+			"idle_pods", "waste_cost", "security_critical", "security_high", "security_medium", "security_low",
+		})
+	}
+
+	money := func(amount float64) string { return strconv.FormatFloat(amount, 'f', 2, 64) }
+
+	if len(estimate.GroupCosts) > 0 {
+		for _, group := range estimate.GroupCosts {
+			writer.Write([]string{
+				clusterName,
+				estimate.GroupBy,
+				group.Name,
+				strings.Join(group.Namespaces, ";"),
+				strconv.Itoa(group.PodCount),
+				strconv.FormatFloat(group.CPUCoresRequested, 'f', 3, 64),
+				strconv.FormatFloat(group.MemoryGBRequested, 'f', 3, 64),
+				money(group.ComputeCost),
+				money(group.StorageCost),
+				money(group.NetworkCost),
+				money(group.TotalCost),
+				strconv.FormatFloat(group.Share*100, 'f', 2, 64),
+				strconv.Itoa(group.IdlePods),
+				money(group.WasteCost),
+				strconv.Itoa(group.SecurityFindings["critical"]),
+				strconv.Itoa(group.SecurityFindings["high"]),
+				strconv.Itoa(group.SecurityFindings["medium"]),
+				strconv.Itoa(group.SecurityFindings["low"]),
+			})
+		}
+		return
+	}
+
+	// Without --group-by, namespaces are the groups; pod, waste and security columns stay empty
+	for _, ns := range estimate.NamespaceCosts {
+		writer.Write([]string{
+			clusterName, "namespace", ns.Name, ns.Name, "", "", "",
+			money(ns.ComputeCost),
+			money(ns.StorageCost),
+			money(ns.NetworkCost),
+			money(ns.EstimatedCost.Best),
+			strconv.FormatFloat(ns.EstimatedCost.Best/estimate.TotalClusterCost*100, 'f', 2, 64),
+			"", "", "", "", "", "",
+		})
+	}
+}
+
+// printStorageCosts prints volume costs, with unbound claims and released volumes listed separately
+func printStorageCosts(estimate *models.CostEstimate) {
+	var bound, unbound, released []models.StorageCostInfo
+	for _, volume := range estimate.StorageCosts {
+		switch volume.Status {
+		case "Bound":
+			bound = append(bound, volume)
+		case "Released":
+			released = append(released, volume)
+		default:
+			unbound = append(unbound, volume)
+		}
+	}
+
+	fmt.Println("STORAGE COSTS:")
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tPVC\tSTORAGE CLASS\tSIZE\t$/GB-MONTH\t$/MONTH")
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+	for _, volume := range bound {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.0f GB\t$%.3f\t$%s\n",
+			volume.Namespace, volume.Name, valueOrDash(volume.StorageClass),
+			volume.SizeGB, volume.PricePerGBMonth, formatCurrency(volume.MonthlyCost))
+	}
+	w.Flush()
+	fmt.Println()
+
+	if len(released) > 0 {
+		fmt.Printf("♻️  Released volumes (claim deleted, disk still billed): $%s/month\n", formatCurrency(estimate.UnusedStorageCost))
+		for _, volume := range released {
+			fmt.Printf("   • %s (was %s/%s, %s, %.0f GB) - $%s/month\n",
+				volume.Volume, valueOrDash(volume.Namespace), valueOrDash(volume.Name),
+				valueOrDash(volume.StorageClass), volume.SizeGB, formatCurrency(volume.MonthlyCost))
+		}
+		fmt.Println()
+	}
+
+	if len(unbound) > 0 {
+		fmt.Println("⏳ Unbound claims (nothing provisioned yet, not billed):")
+		for _, volume := range unbound {
+			fmt.Printf("   • %s/%s (%s, %s, %.0f GB) - $%s/month once bound\n",
+				volume.Namespace, volume.Name, volume.Status, valueOrDash(volume.StorageClass),
+				volume.SizeGB, formatCurrency(volume.SizeGB*volume.PricePerGBMonth))
+		}
+		fmt.Println()
+	}
+}
+
+// printNetworkCosts prints LoadBalancer and Ingress fixed costs
+func printNetworkCosts(estimate *models.CostEstimate) {
+	fmt.Println("NETWORK COSTS:")
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tKIND\t$/MONTH")
+	fmt.Fprintln(w, strings.Repeat("─", 70))
+	for _, item := range estimate.NetworkCosts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t$%s\n", item.Namespace, item.Name, item.Kind, formatCurrency(item.MonthlyCost))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+// valueOrDash renders empty values as "-"
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
 // printScenario prints a single optimization scenario
 func printScenario(num int, scenario models.OptimizationScenario) {
 	fmt.Printf("SCENARIO %d: %s\n", num, scenario.Name)
@@ -118,13 +398,8 @@ func printScenario(num int, scenario models.OptimizationScenario) {
 }
 
 // printCostJSON outputs cost analysis as JSON
-func printCostJSON(estimate *models.CostEstimate) {
-	data, err := json.MarshalIndent(estimate, "", "  ")
-	if err != nil {
-		fmt.Printf("Error formatting JSON: %v\n", err)
-		return
-	}
-	fmt.Println(string(data))
+func printCostJSON(clusterName string, estimate *models.CostEstimate) {
+	models.PrintEnvelope("costs", clusterName, estimate)
 }
 
 // formatCurrency formats a float as currency
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+)
+
+// SetSecurityFindings attributes security audit findings to showback groups
+func (ca *CostAnalyzer) SetSecurityFindings(issues []models.SecurityIssue) {
+	ca.securityIssues = issues
+}
+
+// calculateGroupCosts prices showback groups; nodeGroupCosts holds node-priced compute (nil for the proportional split)
+func (ca *CostAnalyzer) calculateGroupCosts(estimate *models.CostEstimate, nodeGroupCosts map[string]float64) {
+	analysis := ca.resourceAnalysis
+	if analysis.GroupBy == "" {
+		return
+	}
+	estimate.GroupBy = analysis.GroupBy
+
+	findings := ca.groupFindings()
+
+	for _, group := range analysis.Groups {
+		info := models.GroupCostInfo{
+			Name:              group.Name,
+			Namespaces:        group.Namespaces,
+			PodCount:          group.PodCount,
+			CPUCoresRequested: group.CPUCoresRequested,
+			MemoryGBRequested: group.MemoryGBRequested,
+			IdlePods:          group.IdlePods,
+			SecurityFindings:  findings[group.Name],
+		}
+
+		// Compute: node rates when priced per node, otherwise the same capacity share as namespaces
+		if nodeGroupCosts != nil {
+			info.ComputeCost = nodeGroupCosts[group.Name]
+		} else if analysis.TotalCPUCores > 0 && analysis.TotalMemoryGB > 0 {
+			share := (group.CPUCoresRequested/analysis.TotalCPUCores + group.MemoryGBRequested/analysis.TotalMemoryGB) / 2
+			info.ComputeCost = estimate.ComputeCost * share
+		}
+
+		for storageClass, sizeGB := range group.StorageGB {
+			info.StorageCost += sizeGB * ca.pricing.StoragePrice(storageClass)
+		}
+		info.NetworkCost = float64(group.LoadBalancers)*ca.pricing.LoadBalancerPrice() + float64(group.Ingresses)*ca.pricing.IngressPrice()
+		info.TotalCost = info.ComputeCost + info.StorageCost + info.NetworkCost
+		if estimate.TotalClusterCost > 0 {
+			info.Share = info.TotalCost / estimate.TotalClusterCost
+		}
+
+		// Waste: requests above actual usage when metrics are available, otherwise idle pods' requests
+		if group.MetricsAvailable {
+			info.WasteCost = info.ComputeCost * requestFraction(group.UnusedCPUCores, group.UnusedMemoryGB, group)
+		} else {
+			info.WasteCost = info.ComputeCost * requestFraction(group.IdleCPUCores, group.IdleMemoryGB, group)
+		}
+
+		estimate.GroupCosts = append(estimate.GroupCosts, info)
+	}
+}
+
+// groupFindings counts security findings by group and severity
+func (ca *CostAnalyzer) groupFindings() map[string]map[string]int {
+	findings := make(map[string]map[string]int)
+
+	for _, issue := range ca.securityIssues {
+		// Container findings are named pod/container
+		podName, _, _ := strings.Cut(issue.Name, "/")
+		group, ok := ca.resourceAnalysis.PodGroups[issue.Namespace+"/"+podName]
+		if !ok {
+			group = unallocatedGroup
+		}
+		if findings[group] == nil {
+			findings[group] = make(map[string]int)
+		}
+		findings[group][issue.Severity]++
+	}
+
+	return findings
+}
+
+// requestFraction returns the 50/50 CPU/memory weighted fraction of a group's requests
+func requestFraction(cpu, memory float64, group models.GroupResourceUsage) float64 {
+	fraction := 0.0
+	if group.CPUCoresRequested > 0 {
+		fraction += cpu / group.CPUCoresRequested / 2
+	}
+	if group.MemoryGBRequested > 0 {
+		fraction += memory / group.MemoryGBRequested / 2
+	}
+	return fraction
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// unallocatedGroup collects everything without the group-by label or annotation
+const unallocatedGroup = "unallocated"
+
+// GroupKey selects how costs are grouped for showback: a label, an annotation, or the namespace itself
+type GroupKey struct {
+	Source string // "label", "annotation" or "namespace"
+	Key    string
+}
+
+// ParseGroupBy parses "label:team", "annotation:owner", "namespace", or a bare label key
+func ParseGroupBy(spec string) (GroupKey, error) {
+	if spec == "namespace" {
+		return GroupKey{Source: "namespace"}, nil
+	}
+
+	source, key, found := strings.Cut(spec, ":")
+	if !found {
+		source, key = "label", spec
+	}
+	if source != "label" && source != "annotation" {
+		return GroupKey{}, fmt.Errorf("invalid --group-by %q: use label:<key>, annotation:<key> or namespace", spec)
+	}
+	if key == "" {
+		return GroupKey{}, fmt.Errorf("invalid --group-by %q: missing %s key", spec, source)
+	}
+	return GroupKey{Source: source, Key: key}, nil
+}
+
+// String renders the key as accepted by ParseGroupBy
+func (k GroupKey) String() string {
+	if k.Source == "namespace" {
+		return "namespace"
+	}
+	return k.Source + ":" + k.Key
+}
+
+// SetGroupBy aggregates pods, storage and networking by a label or annotation value
+func (ra *ResourceAnalyzer) SetGroupBy(key GroupKey) {
+	ra.groupBy = &key
+}
+
+// groupTracker accumulates showback groups during resource analysis
+type groupTracker struct {
+	key             GroupKey
+	namespaceGroups map[string]string
+	groups          map[string]*models.GroupResourceUsage
+	namespaces      map[string]map[string]bool
+}
+
+// newGroupTracker loads namespace labels/annotations, used when an object has no value of its own
+func (ra *ResourceAnalyzer) newGroupTracker(namespace string) *groupTracker {
+	tracker := &groupTracker{
+		key:             *ra.groupBy,
+		namespaceGroups: make(map[string]string),
+		groups:          make(map[string]*models.GroupResourceUsage),
+		namespaces:      make(map[string]map[string]bool),
+	}
+
+	if tracker.key.Source == "namespace" {
+		return tracker
+	}
+
+	if namespace != "" {
+		if ns, err := ra.clientset.CoreV1().Namespaces().Get(ra.ctx, namespace, metav1.GetOptions{}); err == nil {
+			tracker.namespaceGroups[ns.Name] = tracker.value(ns.ObjectMeta)
+		}
+		return tracker
+	}
+	if nsList, err := ra.clientset.CoreV1().Namespaces().List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, ns := range nsList.Items {
+			tracker.namespaceGroups[ns.Name] = tracker.value(ns.ObjectMeta)
+		}
+	}
+	return tracker
+}
+
+// value returns the object's own label/annotation value ("" when unset)
+func (g *groupTracker) value(meta metav1.ObjectMeta) string {
+	if g.key.Source == "annotation" {
+		return meta.Annotations[g.key.Key]
+	}
+	return meta.Labels[g.key.Key]
+}
+
+// groupOf resolves an object's group: its own value, then its namespace's, then unallocated
+func (g *groupTracker) groupOf(meta metav1.ObjectMeta) string {
+	if g.key.Source == "namespace" && meta.Namespace != "" {
+		return meta.Namespace
+	}
+	if value := g.value(meta); value != "" {
+		return value
+	}
+	if value := g.namespaceGroups[meta.Namespace]; value != "" {
+		return value
+	}
+	return unallocatedGroup
+}
+
+// get returns the group, creating it on first use
+func (g *groupTracker) get(name, namespace string) *models.GroupResourceUsage {
+	group, exists := g.groups[name]
+	if !exists {
+		group = &models.GroupResourceUsage{Name: name, StorageGB: make(map[string]float64)}
+		g.groups[name] = group
+		g.namespaces[name] = make(map[string]bool)
+	}
+	if namespace != "" {
+		g.namespaces[name][namespace] = true
+	}
+	return group
+}
+
+// addPod records a pod's requests, usage and idleness against its group
+func (g *groupTracker) addPod(name, namespace string, requests, used models.ResourceCapacity, idle, metricsAvailable bool) {
+	group := g.get(name, namespace)
+	group.PodCount++
+	group.CPUCoresRequested += requests.CPU
+	group.MemoryGBRequested += requests.Memory
+	group.CPUCoresUsed += used.CPU
+	group.MemoryGBUsed += used.Memory
+	group.MetricsAvailable = metricsAvailable
+
+	if idle {
+		group.IdlePods++
+		group.IdleCPUCores += requests.CPU
+		group.IdleMemoryGB += requests.Memory
+	}
+	if metricsAvailable {
+		group.UnusedCPUCores += positive(requests.CPU - used.CPU)
+		group.UnusedMemoryGB += positive(requests.Memory - used.Memory)
+	}
+}
+
+// results returns the groups by CPU request, with unallocated last
+func (g *groupTracker) results() []models.GroupResourceUsage {
+	var groups []models.GroupResourceUsage
+	for name, group := range g.groups {
+		for ns := range g.namespaces[name] {
+			group.Namespaces = append(group.Namespaces, ns)
+		}
+		sort.Strings(group.Namespaces)
+		groups = append(groups, *group)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == unallocatedGroup) != (groups[j].Name == unallocatedGroup) {
+			return groups[j].Name == unallocatedGroup
+		}
+		if groups[i].CPUCoresRequested != groups[j].CPUCoresRequested {
+			return groups[i].CPUCoresRequested > groups[j].CPUCoresRequested
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// positive clamps negative values to zero
+func positive(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	return value
+}
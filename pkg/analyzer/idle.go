@@ -0,0 +1,247 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// idleCPUThreshold is the CPU usage (cores) below which a pod counts as idle
+	idleCPUThreshold = 0.005
+
+	// idleNamespaceDeleteDays is how long every pod in a namespace must be confirmed idle
+	// (by history, not a single sample) before deleting the namespace is recommended
+	idleNamespaceDeleteDays = 7
+)
+
+// idleVerdict explains why a pod is considered idle
+type idleVerdict struct {
+	days   int // -1 when only a point-in-time sample is available
+	reason string
+}
+
+// detectIdlePods returns idle verdicts keyed by namespace/name.
+// Finished pods are always idle; running pods need near-zero CPU from metrics-server,
+// confirmed over the history window when Prometheus is configured.
+func (ra *ResourceAnalyzer) detectIdlePods(namespace string, pods []corev1.Pod, podUsage map[string]models.ResourceCapacity, metricsAvailable bool) map[string]idleVerdict {
+	verdicts := make(map[string]idleVerdict)
+	now := time.Now()
+
+	var history map[string]float64
+	if metricsAvailable && ra.prometheus != nil {
+		history = ra.getPeakCPU(namespace)
+	}
+	exposure := ra.getExposure(namespace, pods)
+
+	for _, pod := range pods {
+		key := pod.Namespace + "/" + pod.Name
+
+		// System components are never idle candidates
+		if pod.Namespace == "kube-system" || pod.Namespace == "istio-system" {
+			continue
+		}
+
+		// Finished pods keep their objects around but do no work
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			finished := podFinishedAt(pod)
+			verdicts[key] = idleVerdict{
+				days:   int(now.Sub(finished).Hours() / 24),
+				reason: fmt.Sprintf("pod %s, finished %s", strings.ToLower(string(pod.Status.Phase)), finished.Format("2006-01-02")),
+			}
+			continue
+		}
+
+		if !metricsAvailable {
+			continue
+		}
+		usage, ok := podUsage[key]
+		if !ok || usage.CPU >= idleCPUThreshold {
+			continue
+		}
+
+		verdict := idleVerdict{
+			days:   -1,
+			reason: fmt.Sprintf("CPU %s now (metrics-server sample)", formatCPU(usage.CPU)),
+		}
+
+		// Confirm against the history window - a quiet moment is not an idle workload
+		if peak, ok := history[key]; ok {
+			if peak >= idleCPUThreshold {
+				continue
+			}
+			ageDays := int(now.Sub(pod.CreationTimestamp.Time).Hours() / 24)
+			verdict.days = windowDays(ra.historyWindow)
+			if ageDays < verdict.days {
+				verdict.days = ageDays
+			}
+			verdict.reason = fmt.Sprintf("peak CPU %s over %s", formatCPU(peak), ra.historyWindow)
+		}
+
+		if exposed := exposure[key]; exposed != "" {
+			verdict.reason += "; " + exposed
+		}
+		verdicts[key] = verdict
+	}
+
+	return verdicts
+}
+
+// countBatchWorkloads counts Jobs and CronJobs per namespace (nil when they cannot be listed)
+func (ra *ResourceAnalyzer) countBatchWorkloads(namespace string) map[string]int {
+	jobList, err := ra.clientset.BatchV1().Jobs(namespace).List(ra.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	cronList, err := ra.clientset.BatchV1().CronJobs(namespace).List(ra.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, job := range jobList.Items {
+		counts[job.Namespace]++
+	}
+	for _, cj := range cronList.Items {
+		counts[cj.Namespace]++
+	}
+	return counts
+}
+
+// getPeakCPU returns each pod's peak CPU over the history window (nil when unavailable)
+func (ra *ResourceAnalyzer) getPeakCPU(namespace string) map[string]float64 {
+	if !promWindowPattern.MatchString(ra.historyWindow) {
+		return nil
+	}
+
+	selector := `container!="",container!="POD"`
+	if namespace != "" {
+		selector += fmt.Sprintf(`,namespace=%q`, namespace)
+	}
+	query := fmt.Sprintf(`max_over_time(sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{%s}[5m]))[%s:%s])`,
+		selector, ra.historyWindow, promResolution)
+
+	samples, err := ra.prometheus.Query(ra.ctx, query)
+	if err != nil {
+		return nil
+	}
+
+	peaks := make(map[string]float64)
+	for _, sample := range samples {
+		peaks[sample.Labels["namespace"]+"/"+sample.Labels["pod"]] = sample.Value
+	}
+	return peaks
+}
+
+// getExposure describes how each pod is reachable (Service, Ingress, LoadBalancer)
+func (ra *ResourceAnalyzer) getExposure(namespace string, pods []corev1.Pod) map[string]string {
+	exposure := make(map[string]string)
+
+	svcList, err := ra.clientset.CoreV1().Services(namespace).List(ra.ctx, metav1.ListOptions{})
+	if err != nil {
+		return exposure
+	}
+
+	// Services referenced by an Ingress, keyed by namespace/name
+	ingressFor := make(map[string]string)
+	if ingList, err := ra.clientset.NetworkingV1().Ingresses(namespace).List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, ing := range ingList.Items {
+			if ing.Spec.DefaultBackend != nil && ing.Spec.DefaultBackend.Service != nil {
+				ingressFor[ing.Namespace+"/"+ing.Spec.DefaultBackend.Service.Name] = ing.Name
+			}
+			for _, rule := range ing.Spec.Rules {
+				if rule.HTTP == nil {
+					continue
+				}
+				for _, path := range rule.HTTP.Paths {
+					if path.Backend.Service != nil {
+						ingressFor[ing.Namespace+"/"+path.Backend.Service.Name] = ing.Name
+					}
+				}
+			}
+		}
+	}
+
+	for _, pod := range pods {
+		var internal []string
+		external := ""
+
+		for _, svc := range svcList.Items {
+			if svc.Namespace != pod.Namespace || len(svc.Spec.Selector) == 0 {
+				continue
+			}
+			if !labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+
+			if ing, ok := ingressFor[svc.Namespace+"/"+svc.Name]; ok {
+				external = fmt.Sprintf("reachable via Ingress %s - verify it gets no traffic", ing)
+			} else if svc.Spec.Type == corev1.ServiceTypeLoadBalancer || svc.Spec.Type == corev1.ServiceTypeNodePort {
+				external = fmt.Sprintf("exposed by %s Service %s - verify it gets no traffic", svc.Spec.Type, svc.Name)
+			} else {
+				internal = append(internal, svc.Name)
+			}
+		}
+
+		key := pod.Namespace + "/" + pod.Name
+		switch {
+		case external != "":
+			exposure[key] = external
+		case len(internal) > 0:
+			exposure[key] = fmt.Sprintf("Service %s has no Ingress", strings.Join(internal, ", "))
+		default:
+			exposure[key] = "not behind any Service"
+		}
+	}
+
+	return exposure
+}
+
+// podFinishedAt returns when the last container of a finished pod terminated
+func podFinishedAt(pod corev1.Pod) time.Time {
+	finished := pod.CreationTimestamp.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil && cs.State.Terminated.FinishedAt.After(finished) {
+			finished = cs.State.Terminated.FinishedAt.Time
+		}
+	}
+	return finished
+}
+
+// windowDays converts a Prometheus duration (7d, 36h, 2w) to whole days
+func windowDays(window string) int {
+	if !promWindowPattern.MatchString(window) {
+		return 0
+	}
+
+	value, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil {
+		return 0
+	}
+
+	switch window[len(window)-1] {
+	case 'h':
+		return value / 24
+	case 'd':
+		return value
+	case 'w':
+		return value * 7
+	case 'y':
+		return value * 365
+	}
+	return 0
+}
+
+// idleDurationLabel renders a namespace's idle duration for flags
+func idleDurationLabel(days int) string {
+	if days < 0 {
+		return "NOW"
+	}
+	return fmt.Sprintf("%dd", days)
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// metricsAPIPath is the metrics-server aggregated API
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// podMetricsList mirrors metrics.k8s.io/v1beta1 PodMetricsList (only the fields we use)
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Containers []struct {
+			Name  string            `json:"name"`
+			Usage map[string]string `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// nodeMetricsList mirrors metrics.k8s.io/v1beta1 NodeMetricsList (only the fields we use)
+type nodeMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Usage map[string]string `json:"usage"`
+	} `json:"items"`
+}
+
+// getPodUsage returns actual pod usage from metrics-server, keyed by namespace/name
+func (ra *ResourceAnalyzer) getPodUsage(namespace string) (map[string]models.ResourceCapacity, error) {
+	path := metricsAPIPath + "/pods"
+	if namespace != "" {
+		path = metricsAPIPath + "/namespaces/" + namespace + "/pods"
+	}
+
+	data, err := ra.clientset.Discovery().RESTClient().Get().AbsPath(path).DoRaw(ra.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("metrics.k8s.io not available: %w", err)
+	}
+
+	var list podMetricsList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metrics: %w", err)
+	}
+
+	usage := make(map[string]models.ResourceCapacity)
+	for _, item := range list.Items {
+		var podUsage models.ResourceCapacity
+		for _, container := range item.Containers {
+			containerUsage := parseUsage(container.Usage)
+			podUsage.CPU += containerUsage.CPU
+			podUsage.Memory += containerUsage.Memory
+		}
+		usage[item.Metadata.Namespace+"/"+item.Metadata.Name] = podUsage
+	}
+
+	return usage, nil
+}
+
+// getNodeUsage returns total actual node usage from metrics-server
+func (ra *ResourceAnalyzer) getNodeUsage() (models.ResourceCapacity, error) {
+	var total models.ResourceCapacity
+
+	data, err := ra.clientset.Discovery().RESTClient().Get().AbsPath(metricsAPIPath + "/nodes").DoRaw(ra.ctx)
+	if err != nil {
+		return total, fmt.Errorf("metrics.k8s.io not available: %w", err)
+	}
+
+	var list nodeMetricsList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return total, fmt.Errorf("failed to parse node metrics: %w", err)
+	}
+
+	for _, item := range list.Items {
+		nodeUsage := parseUsage(item.Usage)
+		total.CPU += nodeUsage.CPU
+		total.Memory += nodeUsage.Memory
+	}
+
+	return total, nil
+}
+
+// parseUsage converts a metrics usage map ("cpu": "250m", "memory": "128Mi") to cores and GB
+func parseUsage(usage map[string]string) models.ResourceCapacity {
+	var capacity models.ResourceCapacity
+
+	if cpu, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+		capacity.CPU = float64(cpu.MilliValue()) / 1000.0
+	}
+	if memory, err := resource.ParseQuantity(usage["memory"]); err == nil {
+		capacity.Memory = float64(memory.Value()) / (1024 * 1024 * 1024)
+	}
+
+	return capacity
+}
+
+// getPodResourceLimits calculates total resource limits for a pod
+func getPodResourceLimits(pod corev1.Pod) models.ResourceCapacity {
+	var resources models.ResourceCapacity
+
+	for _, container := range pod.Spec.Containers {
+		if cpuLimit, exists := container.Resources.Limits[corev1.ResourceCPU]; exists {
+			resources.CPU += float64(cpuLimit.MilliValue()) / 1000.0
+		}
+		if memLimit, exists := container.Resources.Limits[corev1.ResourceMemory]; exists {
+			resources.Memory += float64(memLimit.Value()) / (1024 * 1024 * 1024)
+		}
+	}
+
+	return resources
+}
+
+// efficiency returns usage as a percentage of requests (0 when nothing is requested)
+func efficiency(used, requested float64) float64 {
+	if requested <= 0 {
+		return 0
+	}
+	return used / requested * 100
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+)
+
+// SetPricing switches cost analysis from a single monthly total to per-node pricing
+func (ca *CostAnalyzer) SetPricing(pricing *config.Pricing) {
+	ca.pricing = pricing
+}
+
+// analyzeNodeCosts prices every node and attributes pod requests to the node they run on
+func (ca *CostAnalyzer) analyzeNodeCosts() (*models.CostEstimate, error) {
+	if len(ca.resourceAnalysis.Nodes) == 0 {
+		return nil, fmt.Errorf("no nodes found to price")
+	}
+
+	nodeCosts, namespaceCosts, groupCosts, unpriced := ca.priceNodes()
+
+	estimate := &models.CostEstimate{
+		Method:     "node_pricing",
+		Confidence: "high",
+		NodeCosts:  nodeCosts,
+	}
+	for _, node := range nodeCosts {
+		estimate.TotalClusterCost += node.MonthlyCost
+		estimate.AllocatedCost += node.Allocated
+		estimate.IdleCapacityCost += node.Idle
+	}
+	if estimate.TotalClusterCost <= 0 {
+		return nil, fmt.Errorf("no node matched the pricing file - add entries for %s or set default_hourly", strings.Join(unpriced, ", "))
+	}
+
+	estimate.Assumptions = []string{
+		"Node cost from the pricing file (hourly price x hours per month)",
+		"Each node's cost is split 50/50 between its allocatable CPU and memory",
+		"Pods are charged for their requests at the rates of the node they run on",
+		"Capacity not requested by any pod is reported as idle capacity, not allocated to namespaces",
+		"Storage (per StorageClass $/GB-month) and LoadBalancer/Ingress costs are added per namespace",
+		"Does NOT include: networking egress, public IPs",
+	}
+	estimate.Disclaimers = []string{
+		"⚠️  List prices from the pricing file - reserved instances and discounts are not applied",
+		"⚠️  Point-in-time allocation based on pods scheduled now",
+		"⚠️  Optimization savings are potential - results may vary",
+	}
+	if len(unpriced) > 0 {
+		estimate.Confidence = "medium"
+		estimate.Disclaimers = append(estimate.Disclaimers,
+			fmt.Sprintf("⚠️  %d nodes had no matching price and are excluded: %s", len(unpriced), formatList(unpriced)))
+	}
+
+	for _, ns := range ca.resourceAnalysis.Namespaces {
+		cost := namespaceCosts[ns.Name]
+		estimate.NamespaceCosts = append(estimate.NamespaceCosts, models.NamespaceCostInfo{
+			Name:          ns.Name,
+			EstimatedCost: models.CostRange{Low: cost, Best: cost, High: cost},
+			CPUShare:      ns.CPUPercent / 100.0,
+			MemoryShare:   ns.MemoryPercent / 100.0,
+		})
+	}
+	ca.addStorageAndNetworkCosts(estimate)
+	ca.calculateGroupCosts(estimate, groupCosts)
+
+	for i := range estimate.NamespaceCosts {
+		estimate.NamespaceCosts[i].WeightedShare = estimate.NamespaceCosts[i].EstimatedCost.Best / estimate.TotalClusterCost
+	}
+	sort.SliceStable(estimate.NamespaceCosts, func(i, j int) bool {
+		return estimate.NamespaceCosts[i].EstimatedCost.Best > estimate.NamespaceCosts[j].EstimatedCost.Best
+	})
+
+	estimate.OptimizationScenarios = ca.generateOptimizationScenarios(estimate.ComputeCost)
+	estimate.TotalSavingsPotential = ca.calculateTotalSavings(estimate.OptimizationScenarios)
+
+	return estimate, nil
+}
+
+// priceNodes returns per-node costs, allocated cost per namespace and showback group, and the nodes without a price
+func (ca *CostAnalyzer) priceNodes() ([]models.NodeCostInfo, map[string]float64, map[string]float64, []string) {
+	var nodeCosts []models.NodeCostInfo
+	var unpriced []string
+	namespaceCosts := make(map[string]float64)
+	groupCosts := make(map[string]float64)
+
+	for _, node := range ca.resourceAnalysis.Nodes {
+		attrs := ca.pricing.Attributes(node.Labels)
+		info := models.NodeCostInfo{
+			Name:         node.Name,
+			InstanceType: attrs.InstanceType,
+			CapacityType: attrs.CapacityType,
+			Zone:         attrs.Zone,
+		}
+
+		hourly, ok := ca.pricing.HourlyPrice(attrs)
+		if !ok || node.CPUCores <= 0 || node.MemoryGB <= 0 {
+			unpriced = append(unpriced, node.Name)
+			nodeCosts = append(nodeCosts, info)
+			continue
+		}
+
+		info.Priced = true
+		info.HourlyPrice = hourly
+		info.MonthlyCost = hourly * ca.pricing.HoursPerMonth
+
+		// Same 50/50 CPU/memory split as the proportional model, per node
+		costPerCore := info.MonthlyCost * 0.5 / node.CPUCores
+		costPerGB := info.MonthlyCost * 0.5 / node.MemoryGB
+
+		for _, requests := range node.NamespaceRequests {
+			info.Allocated += requests.CPU*costPerCore + requests.Memory*costPerGB
+		}
+
+		// Requests never exceed allocatable, but guard against stale node status: scale the
+		// namespace and group shares down so they add up to the node cost at most
+		scale := 1.0
+		if info.Allocated > info.MonthlyCost {
+			scale = info.MonthlyCost / info.Allocated
+			info.Allocated = info.MonthlyCost
+		}
+		info.Idle = info.MonthlyCost - info.Allocated
+
+		for ns, requests := range node.NamespaceRequests {
+			namespaceCosts[ns] += (requests.CPU*costPerCore + requests.Memory*costPerGB) * scale
+		}
+		for group, requests := range node.GroupRequests {
+			groupCosts[group] += (requests.CPU*costPerCore + requests.Memory*costPerGB) * scale
+		}
+
+		nodeCosts = append(nodeCosts, info)
+	}
+
+	sort.SliceStable(nodeCosts, func(i, j int) bool {
+		return nodeCosts[i].MonthlyCost > nodeCosts[j].MonthlyCost
+	})
+
+	return nodeCosts, namespaceCosts, groupCosts, unpriced
+}
@@ -0,0 +1,492 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// hpaTargetCPU is the average CPU utilization (% of requests) generated HPAs scale on
+	hpaTargetCPU = 70
+
+	// PatchSummaryFile lists every patch with its expected savings
+	PatchSummaryFile = "SUMMARY.md"
+)
+
+// spotNodeLabels identify spot node pools, per provider; the first one found on a spot node is targeted
+var spotNodeLabels = []struct{ key, value string }{
+	{"kubernetes.azure.com/scalesetpriority", "spot"},
+	{"karpenter.sh/capacity-type", "spot"},
+	{"eks.amazonaws.com/capacityType", "SPOT"},
+	{"cloud.google.com/gke-spot", "true"},
+}
+
+// hpaExcludedNamespaces host platform components that should not be autoscaled by a generated HPA
+var hpaExcludedNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+	"istio-system":    true,
+}
+
+// spotTarget is the node label and taints of the cluster's spot pool
+type spotTarget struct {
+	labelKey, labelValue string
+	tolerations          []corev1.Toleration
+	detected             bool // false when no spot nodes exist and AKS defaults are assumed
+}
+
+// ComputeRates returns the monthly cost of one requested CPU core and one GB of memory,
+// using the same 50/50 CPU/memory split as namespace allocation
+func ComputeRates(estimate *models.CostEstimate, analysis *models.ClusterResourceAnalysis) (float64, float64) {
+	if analysis.TotalCPUCores <= 0 || analysis.TotalMemoryGB <= 0 {
+		return 0, 0
+	}
+	return estimate.ComputeCost * 0.5 / analysis.TotalCPUCores, estimate.ComputeCost * 0.5 / analysis.TotalMemoryGB
+}
+
+// GeneratePatches builds patches for spot-eligible Deployments, right-sizing recommendations and
+// HPA candidates. costPerCore/costPerGB price the savings (0 reports freed resources only).
+// Savings stack per workload so they add up: right-sizing first, then spot on the right-sized
+// cost, then HPA on what spot leaves.
+func (ra *ResourceAnalyzer) GeneratePatches(namespace string, analysis *models.ClusterResourceAnalysis, costPerCore, costPerGB float64) ([]models.WorkloadPatch, error) {
+	deployList, err := ra.clientset.AppsV1().Deployments(namespace).List(ra.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+	deployments := make(map[string]*appsv1.Deployment)
+	for i := range deployList.Items {
+		d := &deployList.Items[i]
+		deployments[d.Namespace+"/"+d.Name] = d
+	}
+
+	price := func(cpu, memory float64) float64 {
+		return cpu*costPerCore + memory*costPerGB
+	}
+	// cost is each workload's monthly compute cost left after the patches so far
+	cost := make(map[string]float64)
+	for _, wl := range analysis.Workloads {
+		cost[wl.Namespace+"/"+wl.Kind+"/"+wl.Name] = price(wl.CPUCoresRequested, wl.MemoryGBRequested)
+	}
+	deduct := func(patches []models.WorkloadPatch) {
+		for _, patch := range patches {
+			key := patch.Namespace + "/" + patch.Kind + "/" + patch.Name
+			cost[key] = math.Max(0, cost[key]-patch.MonthlySavings)
+		}
+	}
+
+	patches, err := rightsizingPatches(analysis.Rightsizing, price)
+	if err != nil {
+		return nil, err
+	}
+	deduct(patches)
+
+	target, err := ra.spotTarget()
+	if err != nil {
+		return nil, err
+	}
+	spot, err := spotPatches(analysis.SpotEligibility, deployments, cost, target)
+	if err != nil {
+		return nil, err
+	}
+	deduct(spot)
+	patches = append(patches, spot...)
+
+	hpas, err := ra.hpaPatches(namespace, deployList.Items, cost)
+	if err != nil {
+		return nil, err
+	}
+	patches = append(patches, hpas...)
+
+	return patches, nil
+}
+
+// spotTarget finds the label and taints of existing spot nodes, defaulting to AKS spot pools
+func (ra *ResourceAnalyzer) spotTarget() (spotTarget, error) {
+	nodeList, err := ra.clientset.CoreV1().Nodes().List(ra.ctx, metav1.ListOptions{})
+	if err != nil {
+		return spotTarget{}, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	var target spotTarget
+	seen := make(map[string]bool)
+	for _, node := range nodeList.Items {
+		if !isSpotNode(node) {
+			continue
+		}
+		target.detected = true
+		if target.labelKey == "" {
+			for _, label := range spotNodeLabels {
+				if strings.EqualFold(node.Labels[label.key], label.value) {
+					target.labelKey, target.labelValue = label.key, node.Labels[label.key]
+					break
+				}
+			}
+		}
+		for _, taint := range node.Spec.Taints {
+			if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+				continue
+			}
+			key := taint.Key + "=" + taint.Value + ":" + string(taint.Effect)
+			if !seen[key] {
+				seen[key] = true
+				target.tolerations = append(target.tolerations, tolerationFor(taint))
+			}
+		}
+	}
+
+	if target.labelKey == "" {
+		target.labelKey, target.labelValue = spotNodeLabels[0].key, spotNodeLabels[0].value
+	}
+	if !target.detected {
+		// AKS spot pools are tainted kubernetes.azure.com/scalesetpriority=spot:NoSchedule
+		target.tolerations = []corev1.Toleration{tolerationFor(corev1.Taint{
+			Key: spotNodeLabels[0].key, Value: spotNodeLabels[0].value, Effect: corev1.TaintEffectNoSchedule,
+		})}
+	}
+	return target, nil
+}
+
+// spotPatches adds the spot tolerations and a preferred spot node affinity to eligible Deployments.
+// Tolerations and preferred terms are lists without a merge key, so existing entries are kept in the patch.
+func spotPatches(verdicts []models.PodSpotEligibility, deployments map[string]*appsv1.Deployment, cost map[string]float64, target spotTarget) ([]models.WorkloadPatch, error) {
+	var patches []models.WorkloadPatch
+	done := make(map[string]bool)
+
+	for _, v := range verdicts {
+		name, isDeployment := strings.CutPrefix(v.Workload, "Deployment/")
+		key := v.Namespace + "/" + name
+		if !v.Eligible || v.OnSpot || !isDeployment || done[key] {
+			continue
+		}
+		done[key] = true
+		d, ok := deployments[key]
+		if !ok {
+			continue
+		}
+
+		podSpec := d.Spec.Template.Spec
+		tolerations := append([]corev1.Toleration{}, podSpec.Tolerations...)
+		for _, toleration := range target.tolerations {
+			if !hasToleration(tolerations, toleration) {
+				tolerations = append(tolerations, toleration)
+			}
+		}
+
+		var terms []corev1.PreferredSchedulingTerm
+		if podSpec.Affinity != nil && podSpec.Affinity.NodeAffinity != nil {
+			terms = append(terms, podSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+		}
+		// Preferred, not required: pods fall back to on-demand when spot capacity is reclaimed
+		if !prefersLabel(terms, target.labelKey) {
+			terms = append(terms, corev1.PreferredSchedulingTerm{
+				Weight: 100,
+				Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key: target.labelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{target.labelValue},
+				}}},
+			})
+		}
+
+		content, err := marshalPatch(fmt.Sprintf("Prefer spot nodes (%s=%s) for %s/%s - %s",
+			target.labelKey, target.labelValue, d.Namespace, d.Name, v.Reason), map[string]any{
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+				"tolerations": tolerations,
+				"affinity": map[string]any{"nodeAffinity": map[string]any{
+					"preferredDuringSchedulingIgnoredDuringExecution": terms,
+				}},
+			}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		file := patchFile("spot", d.Namespace, "Deployment", d.Name)
+		description := fmt.Sprintf("Prefer spot nodes (%s)", v.Reason)
+		if !target.detected {
+			description += "; no spot nodes found - assumes an AKS spot pool"
+		}
+		patches = append(patches, models.WorkloadPatch{
+			Type:           "spot",
+			Namespace:      d.Namespace,
+			Kind:           "Deployment",
+			Name:           d.Name,
+			Description:    description,
+			File:           file,
+			Command:        patchCommand("Deployment", d.Name, d.Namespace, file),
+			MonthlySavings: cost[v.Namespace+"/Deployment/"+name] * spotSavingsRate,
+			Content:        content,
+		})
+	}
+	return patches, nil
+}
+
+// rightsizingPatches sets the recommended requests/limits, one patch per workload
+func rightsizingPatches(recs []models.ContainerRightsizing, price func(float64, float64) float64) ([]models.WorkloadPatch, error) {
+	var order []string
+	byWorkload := make(map[string][]models.ContainerRightsizing)
+	for _, rec := range recs {
+		// Bare pods and Jobs cannot change resources in place
+		if rec.Kind == "Pod" || rec.Kind == "Job" {
+			continue
+		}
+		key := rec.Namespace + "/" + rec.Kind + "/" + rec.Workload
+		if _, exists := byWorkload[key]; !exists {
+			order = append(order, key)
+		}
+		byWorkload[key] = append(byWorkload[key], rec)
+	}
+
+	var patches []models.WorkloadPatch
+	for _, key := range order {
+		containerRecs := byWorkload[key]
+		first := containerRecs[0]
+
+		var containers []map[string]any
+		var names []string
+		var cpuSaved, memorySaved float64
+		for _, rec := range containerRecs {
+			resources := map[string]any{
+				"requests": map[string]string{
+					"cpu":    formatCPU(rec.Recommended.CPURequest),
+					"memory": formatMemory(rec.Recommended.MemoryRequest),
+				},
+			}
+			limits := map[string]string{"memory": formatMemory(rec.Recommended.MemoryLimit)}
+			if rec.Recommended.CPULimit > 0 {
+				limits["cpu"] = formatCPU(rec.Recommended.CPULimit)
+			}
+			resources["limits"] = limits
+
+			containers = append(containers, map[string]any{"name": rec.Container, "resources": resources})
+			names = append(names, rec.Container)
+			cpuSaved += rec.CPUCoresSaved
+			memorySaved += rec.MemoryGBSaved
+		}
+
+		content, err := marshalPatch(fmt.Sprintf("Right-size %s/%s/%s to p95 usage plus headroom",
+			first.Namespace, strings.ToLower(first.Kind), first.Workload), map[string]any{
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"containers": containers}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		file := patchFile("rightsizing", first.Namespace, first.Kind, first.Workload)
+		patches = append(patches, models.WorkloadPatch{
+			Type:           "rightsizing",
+			Namespace:      first.Namespace,
+			Kind:           first.Kind,
+			Name:           first.Workload,
+			Description:    fmt.Sprintf("Set requests/limits of %s to usage history", strings.Join(names, ", ")),
+			File:           file,
+			Command:        patchCommand(first.Kind, first.Workload, first.Namespace, file),
+			CPUCoresSaved:  cpuSaved,
+			MemoryGBSaved:  memorySaved,
+			MonthlySavings: price(cpuSaved, memorySaved),
+			Content:        content,
+		})
+	}
+	return patches, nil
+}
+
+// hpaPatches creates HPA manifests for multi-replica Deployments with CPU requests and no autoscaler
+func (ra *ResourceAnalyzer) hpaPatches(namespace string, deployments []appsv1.Deployment, cost map[string]float64) ([]models.WorkloadPatch, error) {
+	hpaList, err := ra.clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ra.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing horizontal pod autoscalers: %w", err)
+	}
+	scaled := make(map[string]bool)
+	for _, hpa := range hpaList.Items {
+		scaled[hpa.Namespace+"/"+hpa.Spec.ScaleTargetRef.Kind+"/"+hpa.Spec.ScaleTargetRef.Name] = true
+	}
+
+	var patches []models.WorkloadPatch
+	for _, d := range deployments {
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		if hpaExcludedNamespaces[d.Namespace] || replicas < 2 || scaled[d.Namespace+"/Deployment/"+d.Name] || !hasCPURequests(d.Spec.Template.Spec) {
+			continue
+		}
+
+		minReplicas := int32(math.Max(2, math.Ceil(float64(replicas)/2)))
+		maxReplicas := replicas * 2
+
+		content, err := marshalPatch(fmt.Sprintf("Autoscale %s/%s between %d and %d replicas at %d%% CPU",
+			d.Namespace, d.Name, minReplicas, maxReplicas, hpaTargetCPU), map[string]any{
+			"apiVersion": "autoscaling/v2",
+			"kind":       "HorizontalPodAutoscaler",
+			"metadata":   map[string]any{"name": d.Name, "namespace": d.Namespace},
+			"spec": map[string]any{
+				"scaleTargetRef": map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": d.Name},
+				"minReplicas":    minReplicas,
+				"maxReplicas":    maxReplicas,
+				"metrics": []map[string]any{{
+					"type": "Resource",
+					"resource": map[string]any{
+						"name":   "cpu",
+						"target": map[string]any{"type": "Utilization", "averageUtilization": hpaTargetCPU},
+					},
+				}},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		file := patchFile("hpa", d.Namespace, "Deployment", d.Name)
+		patches = append(patches, models.WorkloadPatch{
+			Type:           "hpa",
+			Namespace:      d.Namespace,
+			Kind:           "Deployment",
+			Name:           d.Name,
+			Description:    fmt.Sprintf("Autoscale %d-%d replicas (now %d) at %d%% CPU", minReplicas, maxReplicas, replicas, hpaTargetCPU),
+			File:           file,
+			Command:        "kubectl apply -f " + file,
+			MonthlySavings: cost[d.Namespace+"/Deployment/"+d.Name] * hpaSavingsRate,
+			Content:        content,
+		})
+	}
+	return patches, nil
+}
+
+// WritePatches writes each patch and a summary of expected savings to dir
+func WritePatches(dir, clusterName string, patches []models.WorkloadPatch, priced bool) error {
+	for _, patch := range patches {
+		path := filepath.Join(dir, patch.File)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating patch directory: %w", err)
+		}
+		if err := os.WriteFile(path, patch.Content, 0644); err != nil {
+			return fmt.Errorf("writing patch: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating patch directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PatchSummaryFile), []byte(patchSummary(clusterName, patches, priced)), 0644); err != nil {
+		return fmt.Errorf("writing patch summary: %w", err)
+	}
+	return nil
+}
+
+// patchSummary renders the summary as Markdown, largest savings first within each type
+func patchSummary(clusterName string, patches []models.WorkloadPatch, priced bool) string {
+	sorted := append([]models.WorkloadPatch{}, patches...)
+	typeOrder := map[string]int{"rightsizing": 0, "spot": 1, "hpa": 2}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return typeOrder[sorted[i].Type] < typeOrder[sorted[j].Type]
+		}
+		return sorted[i].MonthlySavings > sorted[j].MonthlySavings
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Optimization patches - %s\n\n", clusterName)
+	fmt.Fprintf(&b, "Generated %s. Review each patch, then apply from this directory.\n\n", time.Now().Format("2006-01-02 15:04 MST"))
+
+	if len(sorted) == 0 {
+		b.WriteString("No patches - nothing spot-eligible, over-provisioned or missing an autoscaler.\n")
+		return b.String()
+	}
+
+	b.WriteString("| Type | Workload | Change | Freed requests | Est. savings/month | Apply |\n")
+	b.WriteString("|------|----------|--------|----------------|--------------------|-------|\n")
+	total := 0.0
+	for _, patch := range sorted {
+		freed := "-"
+		if patch.CPUCoresSaved != 0 || patch.MemoryGBSaved != 0 {
+			freed = fmt.Sprintf("%.2f CPU, %.2f GB", patch.CPUCoresSaved, patch.MemoryGBSaved)
+		}
+		savings := "n/a"
+		if priced {
+			savings = "$" + formatCurrency(patch.MonthlySavings)
+		}
+		total += patch.MonthlySavings
+		fmt.Fprintf(&b, "| %s | %s/%s/%s | %s | %s | %s | `%s` |\n",
+			patch.Type, patch.Namespace, strings.ToLower(patch.Kind), patch.Name,
+			strings.ReplaceAll(patch.Description, "|", "/"), freed, savings, patch.Command)
+	}
+	b.WriteString("\n")
+
+	if priced {
+		fmt.Fprintf(&b, "**Total: $%s/month.** Savings stack per workload without double counting: right-sizing at the freed requests' cost, then spot at %.0f%% off the right-sized compute, then HPA at %.0f%% off-peak savings on what remains.\n",
+			formatCurrency(total), spotSavingsRate*100, hpaSavingsRate*100)
+		b.WriteString("Freed requests only save money once the autoscaler (or a node pool resize) removes nodes.\n")
+	} else {
+		b.WriteString("Savings in dollars need a cost input: re-run with `--monthly-cost` or `--pricing`.\n")
+	}
+	return b.String()
+}
+
+// marshalPatch renders a patch as YAML behind a comment describing it
+func marshalPatch(comment string, patch any) ([]byte, error) {
+	data, err := yaml.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("encoding patch: %w", err)
+	}
+	return append([]byte("# "+comment+"\n"), data...), nil
+}
+
+// patchFile returns the patch path relative to the output directory
+func patchFile(patchType, namespace, kind, name string) string {
+	return filepath.Join(patchType, fmt.Sprintf("%s.%s.%s.yaml", namespace, strings.ToLower(kind), name))
+}
+
+// patchCommand returns the kubectl command applying a strategic merge patch file
+func patchCommand(kind, name, namespace, file string) string {
+	return fmt.Sprintf("kubectl patch %s %s -n %s --type strategic --patch-file %s", strings.ToLower(kind), name, namespace, file)
+}
+
+// tolerationFor tolerates a taint exactly (any value when the taint has none)
+func tolerationFor(taint corev1.Taint) corev1.Toleration {
+	if taint.Value == "" {
+		return corev1.Toleration{Key: taint.Key, Operator: corev1.TolerationOpExists, Effect: taint.Effect}
+	}
+	return corev1.Toleration{Key: taint.Key, Operator: corev1.TolerationOpEqual, Value: taint.Value, Effect: taint.Effect}
+}
+
+// hasToleration reports whether an existing toleration already covers the wanted one
+func hasToleration(existing []corev1.Toleration, want corev1.Toleration) bool {
+	for _, t := range existing {
+		if t.ToleratesTaint(&corev1.Taint{Key: want.Key, Value: want.Value, Effect: want.Effect}) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefersLabel reports whether a preferred term already selects on the label
+func prefersLabel(terms []corev1.PreferredSchedulingTerm, key string) bool {
+	for _, term := range terms {
+		for _, expr := range term.Preference.MatchExpressions {
+			if expr.Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasCPURequests reports whether every container requests CPU (HPA utilization needs it)
+func hasCPURequests(spec corev1.PodSpec) bool {
+	for _, container := range spec.Containers {
+		if _, ok := container.Resources.Requests[corev1.ResourceCPU]; !ok {
+			return false
+		}
+	}
+	return len(spec.Containers) > 0
+}
@@ -1,19 +1,22 @@
 package analyzer
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 )
 
+// maxWorkloadsShown limits the workload table in terminal output
+const maxWorkloadsShown = 15
+
 // PrintResourceAnalysis displays resource analysis in war room format
-func PrintResourceAnalysis(analysis *models.ClusterResourceAnalysis, format string) {
+func PrintResourceAnalysis(clusterName string, analysis *models.ClusterResourceAnalysis, format string) {
 	if format == "json" {
-		printResourceJSON(analysis)
+		printResourceJSON(clusterName, analysis)
 		return
 	}
 
@@ -35,12 +38,36 @@ func printResourceTable(analysis *models.ClusterResourceAnalysis) {
 		analysis.TotalCPURequested, analysis.CPUUtilization,
 		analysis.TotalMemoryRequested, analysis.MemoryUtilization)
 
+	// Actual usage (metrics-server) vs reservation
+	if analysis.MetricsAvailable {
+		fmt.Printf("Actual Usage:      %0.1f CPU cores (%0.1f%% of requests), %0.1f GB memory (%0.1f%% of requests)\n",
+			analysis.TotalCPUUsed, efficiency(analysis.TotalCPUUsed, analysis.TotalCPURequested),
+			analysis.TotalMemoryUsed, efficiency(analysis.TotalMemoryUsed, analysis.TotalMemoryRequested))
+		fmt.Printf("Node Utilization:  %0.1f%% CPU, %0.1f%% memory (metrics-server)\n\n",
+			analysis.CPUUsagePercent, analysis.MemoryUsagePercent)
+	} else {
+		fmt.Println("ℹ️  metrics-server not available - figures below are requests (reservations), not actual usage")
+		fmt.Printf("   (%s)\n\n", analysis.MetricsError)
+	}
+
+	// Usage history (Prometheus) for right-sizing
+	if analysis.HistorySource != "" {
+		fmt.Printf("Right-sizing:      %d recommendations from %s usage history (%s)\n\n",
+			len(analysis.Rightsizing), analysis.HistorySource, analysis.HistoryWindow)
+	} else if analysis.HistoryError != "" {
+		fmt.Printf("⚠️  Prometheus history unavailable - using request heuristics (%s)\n\n", analysis.HistoryError)
+	}
+
 	// Namespace table
 	fmt.Println("NAMESPACE RANKING:")
 	fmt.Println()
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAMESPACE\tCPU%\tMEMORY%\tPODS\tCPU REQ\tMEM REQ\tFLAGS")
+	if analysis.MetricsAvailable {
+		fmt.Fprintln(w, "NAMESPACE\tCPU%\tMEMORY%\tPODS\tCPU REQ/LIM/USED\tMEM REQ/LIM/USED\tEFF CPU/MEM\tFLAGS")
+	} else {
+		fmt.Fprintln(w, "NAMESPACE\tCPU%\tMEMORY%\tPODS\tCPU REQ\tMEM REQ\tFLAGS")
+	}
 	fmt.Fprintln(w, strings.Repeat("─", 100))
 
 	for _, ns := range analysis.Namespaces {
@@ -49,6 +76,19 @@ func printResourceTable(analysis *models.ClusterResourceAnalysis) {
 			flags = strings.Join(ns.Flags, ", ")
 		}
 
+		if analysis.MetricsAvailable {
+			fmt.Fprintf(w, "%s\t%0.1f%%\t%0.1f%%\t%d\t%0.2f/%0.2f/%0.2f\t%0.1f/%0.1f/%0.1f GB\t%0.0f%%/%0.0f%%\t%s\n",
+				ns.Name,
+				ns.CPUPercent,
+				ns.MemoryPercent,
+				ns.PodCount,
+				ns.CPUCoresRequested, ns.CPUCoresLimit, ns.CPUCoresUsed,
+				ns.MemoryGBRequested, ns.MemoryGBLimit, ns.MemoryGBUsed,
+				ns.CPUEfficiency, ns.MemoryEfficiency,
+				flags)
+			continue
+		}
+
 		fmt.Fprintf(w, "%s\t%0.1f%%\t%0.1f%%\t%d\t%0.1f\t%0.1f GB\t%s\n",
 			ns.Name,
 			ns.CPUPercent,
@@ -61,6 +101,14 @@ func printResourceTable(analysis *models.ClusterResourceAnalysis) {
 	w.Flush()
 	fmt.Println()
 
+	if analysis.MetricsAvailable && len(analysis.Workloads) > 0 {
+		printWorkloadUsage(analysis.Workloads)
+	}
+
+	if len(analysis.SpotEligibility) > 0 {
+		printSpotEligibility(analysis.SpotEligibility)
+	}
+
 	// Optimization opportunities
 	if len(analysis.Optimizations) > 0 {
 		fmt.Println("OPTIMIZATION OPPORTUNITIES:")
@@ -128,14 +176,116 @@ func printResourceTable(analysis *models.ClusterResourceAnalysis) {
 	}
 }
 
-// printResourceJSON outputs resource analysis as JSON
-func printResourceJSON(analysis *models.ClusterResourceAnalysis) {
-	data, err := json.MarshalIndent(analysis, "", "  ")
-	if err != nil {
-		fmt.Printf("Error formatting JSON: %v\n", err)
+// printSpotEligibility summarizes spot verdicts per workload, eligible first
+func printSpotEligibility(verdicts []models.PodSpotEligibility) {
+	type row struct {
+		namespace, workload, verdict, reason string
+		rank, pods                           int // rank: eligible, on spot, not eligible
+	}
+	var rows []*row
+	index := make(map[string]*row)
+	eligible, onSpot := 0, 0
+
+	for _, v := range verdicts {
+		verdict, rank := "❌ no", 2
+		switch {
+		case v.OnSpot:
+			verdict, rank = "☁️  on spot", 1
+			onSpot++
+		case v.Eligible:
+			verdict, rank = "✅ yes", 0
+			eligible++
+		}
+		key := v.Namespace + "/" + v.Workload + "/" + verdict + "/" + v.Reason
+		if _, exists := index[key]; !exists {
+			index[key] = &row{namespace: v.Namespace, workload: v.Workload, verdict: verdict, reason: v.Reason, rank: rank}
+			rows = append(rows, index[key])
+		}
+		index[key].pods++
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].rank < rows[j].rank
+	})
+
+	fmt.Println("SPOT ELIGIBILITY:")
+	fmt.Printf("   %d pods eligible, %d already on spot, %d not eligible\n\n",
+		eligible, onSpot, len(verdicts)-eligible-onSpot)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tWORKLOAD\tPODS\tSPOT\tREASON")
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+	for i, r := range rows {
+		if i == maxWorkloadsShown {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.namespace, r.workload, r.pods, r.verdict, r.reason)
+	}
+	w.Flush()
+
+	if len(rows) > maxWorkloadsShown {
+		fmt.Printf("... and %d more workloads (use --format json for every pod)\n", len(rows)-maxWorkloadsShown)
+	}
+	fmt.Println()
+}
+
+// printWorkloadUsage shows requests, limits and actual usage for the largest workloads
+func printWorkloadUsage(workloads []models.WorkloadResourceUsage) {
+	fmt.Println("TOP WORKLOADS (request vs actual usage):")
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tWORKLOAD\tPODS\tCPU REQ/LIM/USED\tMEM REQ/LIM/USED\tEFF CPU/MEM")
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+
+	for i, wl := range workloads {
+		if i == maxWorkloadsShown {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%s/%s\t%d\t%0.2f/%0.2f/%0.2f\t%0.2f/%0.2f/%0.2f GB\t%0.0f%%/%0.0f%%\n",
+			wl.Namespace,
+			wl.Kind, wl.Name,
+			wl.PodCount,
+			wl.CPUCoresRequested, wl.CPUCoresLimit, wl.CPUCoresUsed,
+			wl.MemoryGBRequested, wl.MemoryGBLimit, wl.MemoryGBUsed,
+			wl.CPUEfficiency, wl.MemoryEfficiency)
+	}
+	w.Flush()
+
+	if len(workloads) > maxWorkloadsShown {
+		fmt.Printf("... and %d more workloads (use --format json for the full list)\n", len(workloads)-maxWorkloadsShown)
+	}
+	fmt.Println()
+}
+
+// PrintRightsizingDiffs shows history-based recommendations with the YAML diff to apply
+func PrintRightsizingDiffs(analysis *models.ClusterResourceAnalysis) {
+	if analysis.HistoryError != "" {
+		fmt.Printf("⚠️  Prometheus history unavailable - right-sizing uses request heuristics (%s)\n", analysis.HistoryError)
 		return
 	}
-	fmt.Println(string(data))
+	if len(analysis.Rightsizing) == 0 {
+		if analysis.HistorySource != "" {
+			fmt.Printf("✅ Requests match %s usage history (%s) - no right-sizing needed\n", analysis.HistorySource, analysis.HistoryWindow)
+		}
+		return
+	}
+
+	fmt.Printf("📐 RIGHT-SIZING (p95 usage over %s, %s):\n", analysis.HistoryWindow, analysis.HistorySource)
+	fmt.Println(strings.Repeat("═", 80))
+	fmt.Println()
+
+	for _, rec := range analysis.Rightsizing {
+		fmt.Printf("%s/%s %s (container %s, %d replicas)\n", rec.Kind, rec.Workload, rec.Namespace, rec.Container, rec.Replicas)
+		fmt.Printf("└─ Frees %.2f CPU cores, %.2f GB\n", rec.CPUCoresSaved, rec.MemoryGBSaved)
+		fmt.Printf("└─ Command: %s\n", setResourcesCommand(rec))
+		fmt.Println()
+		fmt.Println(rec.Diff)
+	}
+}
+
+// printResourceJSON outputs resource analysis as JSON
+func printResourceJSON(clusterName string, analysis *models.ClusterResourceAnalysis) {
+	models.PrintEnvelope("resources", clusterName, analysis)
 }
 
 // PrintOptimizationSummary shows quick optimization check
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusTimeout bounds a single Prometheus query
+const prometheusTimeout = 60 * time.Second
+
+// PrometheusClient runs instant queries against the Prometheus HTTP API
+type PrometheusClient struct {
+	baseURL     string
+	bearerToken string
+	httpClient  *http.Client
+}
+
+// promSample is one series of an instant vector result
+type promSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// promResponse mirrors the /api/v1/query response envelope
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NewPrometheusClient creates a client for the Prometheus API at baseURL
+func NewPrometheusClient(baseURL, bearerToken string) *PrometheusClient {
+	return &PrometheusClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		bearerToken: bearerToken,
+		httpClient:  &http.Client{Timeout: prometheusTimeout},
+	}
+}
+
+// Query runs an instant PromQL query and returns the resulting vector
+func (p *PrometheusClient) Query(ctx context.Context, query string) ([]promSample, error) {
+	endpoint := p.baseURL + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prometheus request: %w", err)
+	}
+	if p.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.bearerToken)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus query failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prometheus response: %w", err)
+	}
+
+	var parsed promResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if parsed.Status != "success" {
+		return nil, fmt.Errorf("prometheus returned %s: %s", parsed.ErrorType, parsed.Error)
+	}
+	if parsed.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("unexpected prometheus result type %q", parsed.Data.ResultType)
+	}
+
+	samples := make([]promSample, 0, len(parsed.Data.Result))
+	for _, result := range parsed.Data.Result {
+		// Value is [ <unix time>, "<value>" ]
+		if len(result.Value) != 2 {
+			continue
+		}
+		raw, ok := result.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, promSample{Labels: result.Metric, Value: value})
+	}
+
+	return samples, nil
+}
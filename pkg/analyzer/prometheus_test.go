@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stubPrometheus serves one canned /api/v1/query response and records the request
+func stubPrometheus(t *testing.T, status int, body string) (*PrometheusClient, *http.Request) {
+	t.Helper()
+	received := &http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*received = *r.Clone(context.Background())
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewPrometheusClient(server.URL+"/", "secret"), received
+}
+
+func TestPrometheusQuery(t *testing.T) {
+	client, received := stubPrometheus(t, http.StatusOK, `{
+		"status": "success",
+		"data": {
+			"resultType": "vector",
+			"result": [
+				{"metric": {"namespace": "shop", "container": "api"}, "value": [1700000000, "0.25"]},
+				{"metric": {"container": "bad-value"}, "value": [1700000000, "NaN?"]},
+				{"metric": {"container": "short"}, "value": [1700000000]}
+			]
+		}
+	}`)
+
+	samples, err := client.Query(context.Background(), `sum(rate(container_cpu_usage_seconds_total[5m]))`)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if received.URL.Path != "/api/v1/query" {
+		t.Errorf("path = %q, want /api/v1/query", received.URL.Path)
+	}
+	if got := received.URL.Query().Get("query"); got != `sum(rate(container_cpu_usage_seconds_total[5m]))` {
+		t.Errorf("query = %q", got)
+	}
+	if got := received.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", got)
+	}
+
+	// Malformed values are skipped, not fatal
+	if len(samples) != 1 {
+		t.Fatalf("got %d samples, want 1: %+v", len(samples), samples)
+	}
+	if samples[0].Value != 0.25 || samples[0].Labels["container"] != "api" {
+		t.Errorf("sample = %+v", samples[0])
+	}
+}
+
+func TestPrometheusQueryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "api error",
+			status:  http.StatusBadRequest,
+			body:    `{"status": "error", "errorType": "bad_data", "error": "parse error at char 4"}`,
+			wantErr: "prometheus returned bad_data: parse error at char 4",
+		},
+		{
+			name:    "not json",
+			status:  http.StatusBadGateway,
+			body:    `<html>upstream unavailable</html>`,
+			wantErr: "HTTP 502",
+		},
+		{
+			name:    "matrix result",
+			status:  http.StatusOK,
+			body:    `{"status": "success", "data": {"resultType": "matrix", "result": []}}`,
+			wantErr: `unexpected prometheus result type "matrix"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := stubPrometheus(t, tt.status, tt.body)
+			_, err := client.Query(context.Background(), "up")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrometheusQueryUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := NewPrometheusClient(url, "").Query(context.Background(), "up")
+	if err == nil || !strings.Contains(err.Error(), "prometheus query failed") {
+		t.Fatalf("err = %v, want a query failure", err)
+	}
+}
@@ -3,8 +3,11 @@ package analyzer
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+	"github.com/opscart/opscart-k8s-watcher/pkg/kube"
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,13 +18,24 @@ import (
 type ResourceAnalyzer struct {
 	clientset *kubernetes.Clientset
 	ctx       context.Context
+
+	// Optional usage history for right-sizing (see SetPrometheus)
+	prometheus    *PrometheusClient
+	historyWindow string
+
+	// Optional showback grouping (see SetGroupBy)
+	groupBy *GroupKey
+
+	// Namespace environments for spot eligibility (see SetEnvironments)
+	environments config.EnvironmentConfig
 }
 
 // NewResourceAnalyzer creates a new resource analyzer
 func NewResourceAnalyzer(clientset *kubernetes.Clientset) *ResourceAnalyzer {
 	return &ResourceAnalyzer{
-		clientset: clientset,
-		ctx:       context.Background(),
+		clientset:    clientset,
+		ctx:          context.Background(),
+		environments: (&config.OpsCartConfig{}).EnvironmentSettings(),
 	}
 }
 
@@ -32,22 +46,59 @@ func (ra *ResourceAnalyzer) AnalyzeClusterResources(namespace string) (*models.C
 	}
 
 	// Get cluster capacity
-	capacity, err := ra.getClusterCapacity()
+	capacity, nodes, err := ra.getClusterCapacity()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cluster capacity: %w", err)
 	}
 	analysis.TotalCPUCores = capacity.CPU
 	analysis.TotalMemoryGB = capacity.Memory
 
+	nodeIndex := make(map[string]int)
+	for i, node := range nodes {
+		nodeIndex[node.Name] = i
+	}
+
 	// Get all pods
 	podList, err := ra.clientset.CoreV1().Pods(namespace).List(ra.ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 
-	// Analyze by namespace
+	// Actual usage from metrics-server; fall back to requests-only analysis when unavailable
+	podUsage, err := ra.getPodUsage(namespace)
+	if err == nil {
+		analysis.MetricsAvailable = true
+		if nodeUsage, err := ra.getNodeUsage(); err == nil {
+			analysis.CPUUsagePercent = (nodeUsage.CPU / analysis.TotalCPUCores) * 100
+			analysis.MemoryUsagePercent = (nodeUsage.Memory / analysis.TotalMemoryGB) * 100
+		}
+	} else {
+		analysis.MetricsError = err.Error()
+	}
+
+	// Idle verdicts from usage, history and exposure
+	idleVerdicts := ra.detectIdlePods(namespace, podList.Items, podUsage, analysis.MetricsAvailable)
+
+	// Analyze by namespace and workload
 	namespaceMap := make(map[string]*models.NamespaceResourceUsage)
+	workloadMap := make(map[string]*models.WorkloadResourceUsage)
+	var workloadOrder []string
 
+	// Pod templates per workload, used for history-based right-sizing
+	podWorkloads := make(map[string]string)
+	workloadSpecs := make(map[string]*workloadSpec)
+
+	// Spot verdicts need namespace environments, PDBs and replica counts
+	spot := ra.newSpotChecker(namespace, podList.Items, nodes)
+
+	var groups *groupTracker
+	if ra.groupBy != nil {
+		groups = ra.newGroupTracker(namespace)
+		analysis.GroupBy = ra.groupBy.String()
+		analysis.PodGroups = make(map[string]string)
+	}
+
+	idleSampled := make(map[string]bool)
 	for _, pod := range podList.Items {
 		ns := pod.Namespace
 
@@ -55,6 +106,7 @@ func (ra *ResourceAnalyzer) AnalyzeClusterResources(namespace string) (*models.C
 		if _, exists := namespaceMap[ns]; !exists {
 			namespaceMap[ns] = &models.NamespaceResourceUsage{
 				Name:     ns,
+				IdleDays: -1,
 				PodCount: 0,
 			}
 		}
@@ -66,13 +118,92 @@ func (ra *ResourceAnalyzer) AnalyzeClusterResources(namespace string) (*models.C
 		namespaceMap[ns].CPUCoresRequested += podResources.CPU
 		namespaceMap[ns].MemoryGBRequested += podResources.Memory
 
-		// Check if pod is idle (no restarts, old)
-		if isPodIdle(pod) {
-			namespaceMap[ns].IdlePods++
+		podLimits := getPodResourceLimits(pod)
+		namespaceMap[ns].CPUCoresLimit += podLimits.CPU
+		namespaceMap[ns].MemoryGBLimit += podLimits.Memory
+
+		group := ""
+		if groups != nil {
+			group = groups.groupOf(pod.ObjectMeta)
+			analysis.PodGroups[ns+"/"+pod.Name] = group
+		}
+
+		// Attribute requests to the node the pod runs on (finished pods hold no capacity)
+		finished := pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+		if i, ok := nodeIndex[pod.Spec.NodeName]; ok && !finished {
+			node := &nodes[i]
+			node.CPUCoresRequested += podResources.CPU
+			node.MemoryGBRequested += podResources.Memory
+			addRequests(node.NamespaceRequests, ns, podResources)
+			if group != "" {
+				if node.GroupRequests == nil {
+					node.GroupRequests = make(map[string]models.ResourceCapacity)
+				}
+				addRequests(node.GroupRequests, group, podResources)
+			}
+		}
+
+		used := podUsage[ns+"/"+pod.Name]
+		namespaceMap[ns].CPUCoresUsed += used.CPU
+		namespaceMap[ns].MemoryGBUsed += used.Memory
+
+		// Roll up to the owning workload
+		kind, name := kube.PodWorkload(pod)
+		workloadKey := ns + "/" + kind + "/" + name
+		if _, exists := workloadMap[workloadKey]; !exists {
+			workloadMap[workloadKey] = &models.WorkloadResourceUsage{Namespace: ns, Kind: kind, Name: name}
+			workloadOrder = append(workloadOrder, workloadKey)
 		}
+		workload := workloadMap[workloadKey]
+		workload.PodCount++
 
-		// Check if spot-eligible (no StatefulSet, no PVC)
-		if isSpotEligible(pod) {
+		podWorkloads[ns+"/"+pod.Name] = workloadKey
+		if _, exists := workloadSpecs[workloadKey]; !exists {
+			workloadSpecs[workloadKey] = &workloadSpec{namespace: ns, kind: kind, name: name, containers: pod.Spec.Containers}
+		}
+		workloadSpecs[workloadKey].replicas++
+		workload.CPUCoresRequested += podResources.CPU
+		workload.MemoryGBRequested += podResources.Memory
+		workload.CPUCoresLimit += podLimits.CPU
+		workload.MemoryGBLimit += podLimits.Memory
+		workload.CPUCoresUsed += used.CPU
+		workload.MemoryGBUsed += used.Memory
+
+		// Check if pod is idle (see detectIdlePods for the signals)
+		verdict, idle := idleVerdicts[ns+"/"+pod.Name]
+		if groups != nil {
+			groups.addPod(group, ns, podResources, used, idle && !finished, analysis.MetricsAvailable)
+		}
+		if idle && finished {
+			// Finished pods hold no capacity and say nothing about when the namespace was last active
+			nsUsage := namespaceMap[ns]
+			nsUsage.IdlePods++
+			nsUsage.FinishedPods++
+			nsUsage.IdleReasons = append(nsUsage.IdleReasons, fmt.Sprintf("%s: %s", pod.Name, verdict.reason))
+		} else if idle {
+			nsUsage := namespaceMap[ns]
+			// Running pods' reasons come first, ahead of finished ones
+			running := nsUsage.IdlePods - nsUsage.FinishedPods
+			reason := fmt.Sprintf("%s: %s", pod.Name, verdict.reason)
+			nsUsage.IdleReasons = append(nsUsage.IdleReasons[:running], append([]string{reason}, nsUsage.IdleReasons[running:]...)...)
+			nsUsage.IdlePods++
+			nsUsage.IdleCPUCores += podResources.CPU
+			nsUsage.IdleMemoryGB += podResources.Memory
+			// The namespace has been idle for as long as its most recently active pod, and is
+			// only sampled-idle if any of its idle pods is
+			if verdict.days < 0 {
+				idleSampled[ns] = true
+			} else if nsUsage.IdleDays < 0 || verdict.days < nsUsage.IdleDays {
+				nsUsage.IdleDays = verdict.days
+			}
+		}
+
+		// Spot eligibility, with the reason either way
+		spotVerdict := spot.evaluate(pod, kind, name)
+		analysis.SpotEligibility = append(analysis.SpotEligibility, spotVerdict)
+		if spotVerdict.OnSpot {
+			namespaceMap[ns].OnSpotPods++
+		} else if spotVerdict.Eligible {
 			namespaceMap[ns].SpotEligiblePods++
 		}
 	}
@@ -83,6 +214,14 @@ func (ra *ResourceAnalyzer) AnalyzeClusterResources(namespace string) (*models.C
 		// Calculate cluster percentage
 		nsUsage.CPUPercent = (nsUsage.CPUCoresRequested / analysis.TotalCPUCores) * 100
 		nsUsage.MemoryPercent = (nsUsage.MemoryGBRequested / analysis.TotalMemoryGB) * 100
+		if analysis.MetricsAvailable {
+			nsUsage.CPUEfficiency = efficiency(nsUsage.CPUCoresUsed, nsUsage.CPUCoresRequested)
+			nsUsage.MemoryEfficiency = efficiency(nsUsage.MemoryGBUsed, nsUsage.MemoryGBRequested)
+		}
+
+		if idleSampled[nsUsage.Name] {
+			nsUsage.IdleDays = -1
+		}
 
 		// Detect waste patterns
 		nsUsage.WasteScore = ra.calculateWasteScore(nsUsage)
@@ -91,6 +230,8 @@ func (ra *ResourceAnalyzer) AnalyzeClusterResources(namespace string) (*models.C
 		// Calculate totals
 		analysis.TotalCPURequested += nsUsage.CPUCoresRequested
 		analysis.TotalMemoryRequested += nsUsage.MemoryGBRequested
+		analysis.TotalCPUUsed += nsUsage.CPUCoresUsed
+		analysis.TotalMemoryUsed += nsUsage.MemoryGBUsed
 
 		namespaces = append(namespaces, *nsUsage)
 	}
@@ -98,26 +239,65 @@ func (ra *ResourceAnalyzer) AnalyzeClusterResources(namespace string) (*models.C
 	// Sort by resource consumption (CPU + Memory percentage)
 	sortNamespacesByUsage(namespaces)
 	analysis.Namespaces = namespaces
+	analysis.Nodes = nodes
+	ra.collectBillableResources(namespace, analysis, groups)
+	if groups != nil {
+		analysis.Groups = groups.results()
+	}
+
+	// Workloads, largest CPU request first
+	for _, key := range workloadOrder {
+		workload := workloadMap[key]
+		if analysis.MetricsAvailable {
+			workload.CPUEfficiency = efficiency(workload.CPUCoresUsed, workload.CPUCoresRequested)
+			workload.MemoryEfficiency = efficiency(workload.MemoryGBUsed, workload.MemoryGBRequested)
+		}
+		analysis.Workloads = append(analysis.Workloads, *workload)
+	}
+	sort.SliceStable(analysis.Workloads, func(i, j int) bool {
+		return analysis.Workloads[i].CPUCoresRequested > analysis.Workloads[j].CPUCoresRequested
+	})
 
 	// Calculate utilization percentages
 	analysis.CPUUtilization = (analysis.TotalCPURequested / analysis.TotalCPUCores) * 100
 	analysis.MemoryUtilization = (analysis.TotalMemoryRequested / analysis.TotalMemoryGB) * 100
 
 	// Generate optimization opportunities
-	analysis.Optimizations = ra.generateOptimizations(namespaces)
+	analysis.Optimizations = ra.generateOptimizations(namespaces, ra.countBatchWorkloads(namespace))
+
+	// Replace the requests-only right-sizing heuristic with history-based recommendations
+	if ra.prometheus != nil {
+		recs, err := ra.analyzeRightsizing(namespace, podWorkloads, workloadSpecs)
+		if err != nil {
+			analysis.HistoryError = err.Error()
+		} else {
+			analysis.HistorySource = "prometheus"
+			analysis.HistoryWindow = ra.historyWindow
+			analysis.Rightsizing = recs
+
+			var opts []models.Optimization
+			for _, opt := range analysis.Optimizations {
+				if opt.Type != "rightsizing" {
+					opts = append(opts, opt)
+				}
+			}
+			analysis.Optimizations = append(opts, rightsizingOptimizations(recs)...)
+		}
+	}
 
 	return analysis, nil
 }
 
-// getClusterCapacity calculates total cluster capacity
-func (ra *ResourceAnalyzer) getClusterCapacity() (models.ResourceCapacity, error) {
+// getClusterCapacity calculates total cluster capacity and per-node allocatable resources
+func (ra *ResourceAnalyzer) getClusterCapacity() (models.ResourceCapacity, []models.NodeResourceUsage, error) {
 	var capacity models.ResourceCapacity
 
 	nodeList, err := ra.clientset.CoreV1().Nodes().List(ra.ctx, metav1.ListOptions{})
 	if err != nil {
-		return capacity, err
+		return capacity, nil, err
 	}
 
+	nodes := make([]models.NodeResourceUsage, 0, len(nodeList.Items))
 	for _, node := range nodeList.Items {
 		// Get allocatable resources (what's available for pods)
 		cpuQuantity := node.Status.Allocatable[corev1.ResourceCPU]
@@ -129,9 +309,26 @@ func (ra *ResourceAnalyzer) getClusterCapacity() (models.ResourceCapacity, error
 
 		capacity.CPU += cpu
 		capacity.Memory += memory
+
+		nodes = append(nodes, models.NodeResourceUsage{
+			Name:              node.Name,
+			Labels:            node.Labels,
+			Spot:              isSpotNode(node),
+			CPUCores:          cpu,
+			MemoryGB:          memory,
+			NamespaceRequests: make(map[string]models.ResourceCapacity),
+		})
 	}
 
-	return capacity, nil
+	return capacity, nodes, nil
+}
+
+// addRequests adds a pod's requests to the entry for key
+func addRequests(requests map[string]models.ResourceCapacity, key string, pod models.ResourceCapacity) {
+	total := requests[key]
+	total.CPU += pod.CPU
+	total.Memory += pod.Memory
+	requests[key] = total
 }
 
 // getPodResourceRequests calculates total resource requests for a pod
@@ -155,62 +352,6 @@ func getPodResourceRequests(pod corev1.Pod) models.ResourceCapacity {
 	return resources
 }
 
-// isPodIdle checks if a pod appears to be idle
-func isPodIdle(pod corev1.Pod) bool {
-	// Pod is idle if:
-	// 1. Running for more than 7 days
-	// 2. No restarts
-	// 3. Not in kube-system or istio-system
-
-	age := time.Since(pod.CreationTimestamp.Time)
-	if age < 7*24*time.Hour {
-		return false
-	}
-
-	// Check if system namespace
-	if pod.Namespace == "kube-system" || pod.Namespace == "istio-system" {
-		return false
-	}
-
-	// Check restarts
-	for _, cs := range pod.Status.ContainerStatuses {
-		if cs.RestartCount > 0 {
-			return false // Has activity
-		}
-	}
-
-	return true
-}
-
-// isSpotEligible checks if a pod can run on spot instances
-func isSpotEligible(pod corev1.Pod) bool {
-	// Not spot-eligible if:
-	// 1. Has persistent volume claims
-	// 2. In production namespace
-	// 3. Part of a StatefulSet
-
-	// Check namespace
-	if pod.Namespace == "production" || pod.Namespace == "prod" {
-		return false
-	}
-
-	// Check for PVCs
-	for _, volume := range pod.Spec.Volumes {
-		if volume.PersistentVolumeClaim != nil {
-			return false
-		}
-	}
-
-	// Check if StatefulSet
-	for _, ownerRef := range pod.OwnerReferences {
-		if ownerRef.Kind == "StatefulSet" {
-			return false
-		}
-	}
-
-	return true
-}
-
 // calculateWasteScore calculates a waste score (0-100) for a namespace
 func (ra *ResourceAnalyzer) calculateWasteScore(ns *models.NamespaceResourceUsage) float64 {
 	score := 0.0
@@ -242,8 +383,10 @@ func (ra *ResourceAnalyzer) calculateWasteScore(ns *models.NamespaceResourceUsag
 func (ra *ResourceAnalyzer) generateFlags(ns *models.NamespaceResourceUsage) []string {
 	var flags []string
 
-	if ns.IdlePods > 0 {
-		flags = append(flags, fmt.Sprintf("IDLE-%dd", ns.IdlePods*7))
+	if idleNamespace(*ns) {
+		flags = append(flags, "IDLE-"+idleDurationLabel(ns.IdleDays))
+	} else if ns.IdlePods > 0 {
+		flags = append(flags, fmt.Sprintf("IDLE-PODS-%d/%d", ns.IdlePods, ns.PodCount))
 	}
 
 	if ns.SpotEligiblePods > 0 && float64(ns.SpotEligiblePods)/float64(ns.PodCount) > 0.5 {
@@ -260,23 +403,54 @@ func (ra *ResourceAnalyzer) generateFlags(ns *models.NamespaceResourceUsage) []s
 	return flags
 }
 
-// generateOptimizations creates optimization recommendations
-func (ra *ResourceAnalyzer) generateOptimizations(namespaces []models.NamespaceResourceUsage) []models.Optimization {
+// idleNamespace reports whether every running pod in the namespace is idle. Finished pods are
+// left out, so a namespace holding only completed Job pods is not idle.
+func idleNamespace(ns models.NamespaceResourceUsage) bool {
+	running := ns.PodCount - ns.FinishedPods
+	return running > 0 && ns.IdlePods-ns.FinishedPods == running
+}
+
+// generateOptimizations creates optimization recommendations. batchWorkloads counts Jobs and
+// CronJobs per namespace (nil when unknown); deletion is never recommended for a namespace with any.
+func (ra *ResourceAnalyzer) generateOptimizations(namespaces []models.NamespaceResourceUsage, batchWorkloads map[string]int) []models.Optimization {
 	var opts []models.Optimization
 
 	for _, ns := range namespaces {
-		// High impact: Idle namespaces
-		if ns.IdlePods > 0 && ns.WasteScore > 50 {
-			opts = append(opts, models.Optimization{
-				Priority:  "high",
+		// Idle namespaces (every running pod idle): deletion only once history confirms it,
+		// a metrics-server sample alone is a quiet moment worth investigating
+		if idleNamespace(ns) {
+			cpuPercent := 0.0
+			if ns.CPUCoresRequested > 0 {
+				cpuPercent = ns.CPUPercent * ns.IdleCPUCores / ns.CPUCoresRequested
+			}
+			opt := models.Optimization{
+				Priority:  "medium",
 				Type:      "idle_namespace",
 				Namespace: ns.Name,
-				Description: fmt.Sprintf("%s idle for %d+ days (%0.1f CPU, %0.1f GB)",
-					ns.Name, ns.IdlePods*7, ns.CPUCoresRequested, ns.MemoryGBRequested),
-				Action: fmt.Sprintf("kubectl delete namespace %s", ns.Name),
-				Impact: fmt.Sprintf("Frees %0.1f CPU, %0.1f GB (%0.1f%% of cluster)",
-					ns.CPUCoresRequested, ns.MemoryGBRequested, ns.CPUPercent),
-			})
+				Description: fmt.Sprintf("%s idle now (%0.1f CPU, %0.1f GB) - %s",
+					ns.Name, ns.IdleCPUCores, ns.IdleMemoryGB, ns.IdleReasons[0]),
+				Action: fmt.Sprintf("Investigate: confirm with the owners that %s is unused (kubectl get all -n %s); configure Prometheus to confirm idle history", ns.Name, ns.Name),
+				Impact: fmt.Sprintf("Could free %0.1f CPU, %0.1f GB (%0.1f%% of cluster)",
+					ns.IdleCPUCores, ns.IdleMemoryGB, cpuPercent),
+			}
+			if ns.IdleDays >= 0 {
+				opt.Description = fmt.Sprintf("%s idle for %d+ days (%0.1f CPU, %0.1f GB) - %s",
+					ns.Name, ns.IdleDays, ns.IdleCPUCores, ns.IdleMemoryGB, ns.IdleReasons[0])
+				opt.Action = fmt.Sprintf("Investigate: confirm with the owners that %s is unused (kubectl get all -n %s)", ns.Name, ns.Name)
+			}
+			switch {
+			case ns.IdleDays < idleNamespaceDeleteDays:
+				// Not confirmed idle for long enough - stays an investigation
+			case batchWorkloads == nil || batchWorkloads[ns.Name] > 0:
+				// Scheduled work would go with the namespace; idle pods are removed one by one instead
+				opt.Action = fmt.Sprintf("Investigate: %s still has Jobs or CronJobs (kubectl get jobs,cronjobs -n %s) - scale down or delete the idle workloads rather than the namespace", ns.Name, ns.Name)
+			default:
+				opt.Priority = "high"
+				opt.Action = fmt.Sprintf("kubectl delete namespace %s", ns.Name)
+				opt.Impact = fmt.Sprintf("Frees %0.1f CPU, %0.1f GB (%0.1f%% of cluster)",
+					ns.IdleCPUCores, ns.IdleMemoryGB, cpuPercent)
+			}
+			opts = append(opts, opt)
 		}
 
 		// Medium impact: Spot-eligible
@@ -0,0 +1,307 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// requestHeadroom is applied on top of p95 usage for recommended requests
+	requestHeadroom = 1.15
+
+	// limitHeadroom is applied on top of peak usage for recommended limits
+	limitHeadroom = 1.25
+
+	// Floors so recommendations never drop to zero for near-idle containers
+	minCPURequest    = 0.01        // 10m
+	minMemoryRequest = 16.0 / 1024 // 16Mi in GB
+
+	// rightsizeThreshold is the relative change below which a recommendation is not worth applying
+	rightsizeThreshold = 0.2
+
+	// promResolution is the subquery step for usage history
+	promResolution = "5m"
+
+	bytesPerGB = 1024 * 1024 * 1024
+)
+
+// promWindowPattern validates Prometheus durations such as 7d or 24h
+var promWindowPattern = regexp.MustCompile(`^[0-9]+[smhdwy]$`)
+
+// workloadSpec is the pod template of a workload as seen on one of its running pods
+type workloadSpec struct {
+	namespace  string
+	kind       string
+	name       string
+	replicas   int
+	containers []corev1.Container
+}
+
+// usageStats holds usage percentiles for one workload container
+type usageStats struct {
+	cpuP50, cpuP95, cpuMax float64
+	memP50, memP95, memMax float64
+}
+
+// SetPrometheus enables history-based right-sizing using container usage over the given window
+func (ra *ResourceAnalyzer) SetPrometheus(client *PrometheusClient, window string) {
+	ra.prometheus = client
+	ra.historyWindow = window
+}
+
+// analyzeRightsizing queries usage history and recommends requests/limits per workload container
+func (ra *ResourceAnalyzer) analyzeRightsizing(namespace string, podWorkloads map[string]string, workloads map[string]*workloadSpec) ([]models.ContainerRightsizing, error) {
+	if !promWindowPattern.MatchString(ra.historyWindow) {
+		return nil, fmt.Errorf("invalid prometheus window %q (expected e.g. 7d, 24h)", ra.historyWindow)
+	}
+
+	selector := `container!="",container!="POD"`
+	if namespace != "" {
+		selector += fmt.Sprintf(`,namespace=%q`, namespace)
+	}
+	cpuExpr := fmt.Sprintf(`sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total{%s}[5m]))`, selector)
+	memExpr := fmt.Sprintf(`sum by (namespace, pod, container) (container_memory_working_set_bytes{%s})`, selector)
+	rangeSuffix := fmt.Sprintf("[%s:%s]", ra.historyWindow, promResolution)
+
+	stats := make(map[string]*usageStats)
+	queries := []struct {
+		query string
+		set   func(s *usageStats, v float64)
+	}{
+		{fmt.Sprintf("quantile_over_time(0.5, %s%s)", cpuExpr, rangeSuffix), func(s *usageStats, v float64) { s.cpuP50 = math.Max(s.cpuP50, v) }},
+		{fmt.Sprintf("quantile_over_time(0.95, %s%s)", cpuExpr, rangeSuffix), func(s *usageStats, v float64) { s.cpuP95 = math.Max(s.cpuP95, v) }},
+		{fmt.Sprintf("max_over_time(%s%s)", cpuExpr, rangeSuffix), func(s *usageStats, v float64) { s.cpuMax = math.Max(s.cpuMax, v) }},
+		{fmt.Sprintf("quantile_over_time(0.5, %s%s)", memExpr, rangeSuffix), func(s *usageStats, v float64) { s.memP50 = math.Max(s.memP50, v/bytesPerGB) }},
+		{fmt.Sprintf("quantile_over_time(0.95, %s%s)", memExpr, rangeSuffix), func(s *usageStats, v float64) { s.memP95 = math.Max(s.memP95, v/bytesPerGB) }},
+		{fmt.Sprintf("max_over_time(%s%s)", memExpr, rangeSuffix), func(s *usageStats, v float64) { s.memMax = math.Max(s.memMax, v/bytesPerGB) }},
+	}
+
+	for _, q := range queries {
+		samples, err := ra.prometheus.Query(ra.ctx, q.query)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fold pods into their workload, keeping the highest value across replicas
+		for _, sample := range samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			workloadKey, ok := podWorkloads[sample.Labels["namespace"]+"/"+sample.Labels["pod"]]
+			if !ok {
+				continue
+			}
+			key := workloadKey + "/" + sample.Labels["container"]
+			if stats[key] == nil {
+				stats[key] = &usageStats{}
+			}
+			q.set(stats[key], sample.Value)
+		}
+	}
+
+	keys := make([]string, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var recs []models.ContainerRightsizing
+	for _, key := range keys {
+		workload := workloads[key]
+		for _, container := range workload.containers {
+			usage, ok := stats[key+"/"+container.Name]
+			if !ok {
+				continue
+			}
+			if rec, ok := recommendResources(workload, container, usage, ra.historyWindow); ok {
+				recs = append(recs, rec)
+			}
+		}
+	}
+
+	// Biggest wins first
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].CPUCoresSaved+recs[i].MemoryGBSaved > recs[j].CPUCoresSaved+recs[j].MemoryGBSaved
+	})
+
+	return recs, nil
+}
+
+// recommendResources derives requests/limits from usage; ok is false when the current values are close enough
+func recommendResources(workload *workloadSpec, container corev1.Container, usage *usageStats, window string) (models.ContainerRightsizing, bool) {
+	current := containerResources(container)
+
+	recommended := models.ContainerResources{
+		CPURequest:    roundCPU(math.Max(usage.cpuP95*requestHeadroom, minCPURequest)),
+		MemoryRequest: roundMemory(math.Max(usage.memP95*requestHeadroom, minMemoryRequest)),
+	}
+	recommended.MemoryLimit = roundMemory(math.Max(usage.memMax*limitHeadroom, recommended.MemoryRequest))
+
+	// Only recommend a CPU limit where one is already set (CPU limits cause throttling)
+	if current.CPULimit > 0 {
+		recommended.CPULimit = roundCPU(math.Max(usage.cpuMax*limitHeadroom, recommended.CPURequest))
+	}
+
+	if !significantChange(current.CPURequest, recommended.CPURequest) &&
+		!significantChange(current.MemoryRequest, recommended.MemoryRequest) &&
+		!significantChange(current.MemoryLimit, recommended.MemoryLimit) {
+		return models.ContainerRightsizing{}, false
+	}
+
+	rec := models.ContainerRightsizing{
+		Namespace:     workload.namespace,
+		Kind:          workload.kind,
+		Workload:      workload.name,
+		Container:     container.Name,
+		Replicas:      workload.replicas,
+		CPUP50:        usage.cpuP50,
+		CPUP95:        usage.cpuP95,
+		CPUMax:        usage.cpuMax,
+		MemoryP50:     usage.memP50,
+		MemoryP95:     usage.memP95,
+		MemoryMax:     usage.memMax,
+		Current:       current,
+		Recommended:   recommended,
+		CPUCoresSaved: (current.CPURequest - recommended.CPURequest) * float64(workload.replicas),
+		MemoryGBSaved: (current.MemoryRequest - recommended.MemoryRequest) * float64(workload.replicas),
+	}
+	rec.Diff = buildResourcesDiff(rec, window)
+
+	return rec, true
+}
+
+// significantChange reports whether moving from current to recommended is worth doing
+func significantChange(current, recommended float64) bool {
+	if current <= 0 {
+		return true // unset - always worth setting
+	}
+	return math.Abs(current-recommended)/current >= rightsizeThreshold
+}
+
+// containerResources reads a container's requests and limits
+func containerResources(container corev1.Container) models.ContainerResources {
+	var res models.ContainerResources
+
+	if q, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+		res.CPURequest = float64(q.MilliValue()) / 1000.0
+	}
+	if q, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+		res.CPULimit = float64(q.MilliValue()) / 1000.0
+	}
+	if q, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+		res.MemoryRequest = float64(q.Value()) / bytesPerGB
+	}
+	if q, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+		res.MemoryLimit = float64(q.Value()) / bytesPerGB
+	}
+
+	return res
+}
+
+// buildResourcesDiff renders the change to the container's resources block as a YAML diff
+func buildResourcesDiff(rec models.ContainerRightsizing, window string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s/%s/%s container %q (%s: p95 CPU %s, p95 memory %s, peak memory %s)\n",
+		rec.Namespace, strings.ToLower(rec.Kind), rec.Workload, rec.Container, window,
+		formatCPU(rec.CPUP95), formatMemory(rec.MemoryP95), formatMemory(rec.MemoryMax))
+	b.WriteString(" resources:\n")
+	b.WriteString("   requests:\n")
+	writeDiffLine(&b, "cpu", rec.Current.CPURequest, rec.Recommended.CPURequest, formatCPU)
+	writeDiffLine(&b, "memory", rec.Current.MemoryRequest, rec.Recommended.MemoryRequest, formatMemory)
+	b.WriteString("   limits:\n")
+	writeDiffLine(&b, "cpu", rec.Current.CPULimit, rec.Recommended.CPULimit, formatCPU)
+	writeDiffLine(&b, "memory", rec.Current.MemoryLimit, rec.Recommended.MemoryLimit, formatMemory)
+
+	return b.String()
+}
+
+// writeDiffLine writes one -/+ pair (or an unchanged line) for a resource value
+func writeDiffLine(b *strings.Builder, name string, current, recommended float64, format func(float64) string) {
+	switch {
+	case current == 0 && recommended == 0:
+		return
+	case current == recommended:
+		fmt.Fprintf(b, "     %s: %s\n", name, format(current))
+		return
+	}
+	if current > 0 {
+		fmt.Fprintf(b, "-    %s: %s\n", name, format(current))
+	}
+	if recommended > 0 {
+		fmt.Fprintf(b, "+    %s: %s\n", name, format(recommended))
+	}
+}
+
+// setResourcesCommand returns the kubectl command applying a recommendation
+func setResourcesCommand(rec models.ContainerRightsizing) string {
+	if rec.Kind == "Pod" {
+		return fmt.Sprintf("Update the resources of container %s in the manifest for pod %s/%s", rec.Container, rec.Namespace, rec.Workload)
+	}
+
+	requests := fmt.Sprintf("cpu=%s,memory=%s", formatCPU(rec.Recommended.CPURequest), formatMemory(rec.Recommended.MemoryRequest))
+	limits := "memory=" + formatMemory(rec.Recommended.MemoryLimit)
+	if rec.Recommended.CPULimit > 0 {
+		limits = "cpu=" + formatCPU(rec.Recommended.CPULimit) + "," + limits
+	}
+
+	return fmt.Sprintf("kubectl set resources %s/%s -n %s -c %s --requests=%s --limits=%s",
+		strings.ToLower(rec.Kind), rec.Workload, rec.Namespace, rec.Container, requests, limits)
+}
+
+// rightsizingOptimizations turns history-based recommendations into optimization entries
+func rightsizingOptimizations(recs []models.ContainerRightsizing) []models.Optimization {
+	var opts []models.Optimization
+
+	for _, rec := range recs {
+		if rec.CPUCoresSaved <= 0 && rec.MemoryGBSaved <= 0 {
+			continue
+		}
+
+		priority := "medium"
+		if rec.CPUCoresSaved >= 1 || rec.MemoryGBSaved >= 2 {
+			priority = "high"
+		}
+
+		opts = append(opts, models.Optimization{
+			Priority:  priority,
+			Type:      "rightsizing",
+			Namespace: rec.Namespace,
+			Description: fmt.Sprintf("%s/%s container %s uses p95 %s CPU / %s memory but requests %s / %s",
+				rec.Kind, rec.Workload, rec.Container,
+				formatCPU(rec.CPUP95), formatMemory(rec.MemoryP95),
+				formatCPU(rec.Current.CPURequest), formatMemory(rec.Current.MemoryRequest)),
+			Action: setResourcesCommand(rec),
+			Impact: fmt.Sprintf("Frees %.2f CPU cores, %.2f GB across %d replicas",
+				math.Max(rec.CPUCoresSaved, 0), math.Max(rec.MemoryGBSaved, 0), rec.Replicas),
+		})
+	}
+
+	return opts
+}
+
+// roundCPU rounds cores up to whole millicores
+func roundCPU(cores float64) float64 {
+	return math.Ceil(cores*1000) / 1000
+}
+
+// roundMemory rounds GB up to whole MiB
+func roundMemory(gb float64) float64 {
+	return math.Ceil(gb*1024) / 1024
+}
+
+// formatCPU renders cores as a Kubernetes quantity in millicores (e.g. 250m)
+func formatCPU(cores float64) string {
+	return fmt.Sprintf("%dm", int64(math.Round(cores*1000)))
+}
+
+// formatMemory renders GB as a Kubernetes quantity in MiB (e.g. 512Mi)
+func formatMemory(gb float64) string {
+	return fmt.Sprintf("%dMi", int64(math.Round(gb*1024)))
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// testContainer builds a container from quantity strings; empty values are left unset
+func testContainer(cpuRequest, cpuLimit, memoryRequest, memoryLimit string) corev1.Container {
+	container := corev1.Container{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{},
+			Limits:   corev1.ResourceList{},
+		},
+	}
+	set := func(list corev1.ResourceList, name corev1.ResourceName, value string) {
+		if value != "" {
+			list[name] = resource.MustParse(value)
+		}
+	}
+	set(container.Resources.Requests, corev1.ResourceCPU, cpuRequest)
+	set(container.Resources.Limits, corev1.ResourceCPU, cpuLimit)
+	set(container.Resources.Requests, corev1.ResourceMemory, memoryRequest)
+	set(container.Resources.Limits, corev1.ResourceMemory, memoryLimit)
+	return container
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRecommendResources(t *testing.T) {
+	workload := &workloadSpec{namespace: "shop", kind: "Deployment", name: "api", replicas: 3}
+	usage := &usageStats{cpuP95: 0.25, cpuMax: 0.5, memP95: 0.5, memMax: 1.0}
+
+	tests := []struct {
+		name         string
+		container    corev1.Container
+		wantCPULimit float64
+		wantCPUSaved float64
+	}{
+		{
+			name:         "no cpu limit stays unset",
+			container:    testContainer("1", "", "2Gi", "4Gi"),
+			wantCPULimit: 0,
+			wantCPUSaved: (1 - 0.288) * 3,
+		},
+		{
+			name:         "existing cpu limit is right-sized",
+			container:    testContainer("1", "2", "2Gi", "4Gi"),
+			wantCPULimit: 0.625, // 0.5 peak * 1.25
+			wantCPUSaved: (1 - 0.288) * 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, ok := recommendResources(workload, tt.container, usage, "7d")
+			if !ok {
+				t.Fatal("expected a recommendation")
+			}
+
+			// p95 * 1.15 headroom, rounded up to whole millicores / MiB
+			if !approxEqual(rec.Recommended.CPURequest, 0.288) {
+				t.Errorf("CPURequest = %v, want 0.288 (0.25 * 1.15 = 0.2875, rounded up)", rec.Recommended.CPURequest)
+			}
+			if !approxEqual(rec.Recommended.MemoryRequest, 589.0/1024) {
+				t.Errorf("MemoryRequest = %v, want 589Mi (512Mi * 1.15 = 588.8Mi, rounded up)", rec.Recommended.MemoryRequest*1024)
+			}
+			// Peak * 1.25 headroom
+			if !approxEqual(rec.Recommended.MemoryLimit, 1.25) {
+				t.Errorf("MemoryLimit = %v, want 1.25", rec.Recommended.MemoryLimit)
+			}
+			if !approxEqual(rec.Recommended.CPULimit, tt.wantCPULimit) {
+				t.Errorf("CPULimit = %v, want %v", rec.Recommended.CPULimit, tt.wantCPULimit)
+			}
+			if !approxEqual(rec.CPUCoresSaved, tt.wantCPUSaved) {
+				t.Errorf("CPUCoresSaved = %v, want %v", rec.CPUCoresSaved, tt.wantCPUSaved)
+			}
+			if rec.Diff == "" {
+				t.Error("expected a YAML diff")
+			}
+		})
+	}
+}
+
+func TestRecommendResourcesFloors(t *testing.T) {
+	workload := &workloadSpec{namespace: "shop", kind: "Deployment", name: "idle", replicas: 1}
+	usage := &usageStats{cpuP95: 0.0001, cpuMax: 0.0002, memP95: 0.001, memMax: 0.002}
+
+	rec, ok := recommendResources(workload, testContainer("500m", "", "1Gi", ""), usage, "7d")
+	if !ok {
+		t.Fatal("expected a recommendation")
+	}
+	if !approxEqual(rec.Recommended.CPURequest, minCPURequest) {
+		t.Errorf("CPURequest = %v, want the %v floor", rec.Recommended.CPURequest, minCPURequest)
+	}
+	if !approxEqual(rec.Recommended.MemoryRequest, minMemoryRequest) {
+		t.Errorf("MemoryRequest = %v, want the %v floor", rec.Recommended.MemoryRequest, minMemoryRequest)
+	}
+	// The memory limit never drops below the request
+	if rec.Recommended.MemoryLimit < rec.Recommended.MemoryRequest {
+		t.Errorf("MemoryLimit %v below MemoryRequest %v", rec.Recommended.MemoryLimit, rec.Recommended.MemoryRequest)
+	}
+}
+
+func TestRecommendResourcesSmallChange(t *testing.T) {
+	workload := &workloadSpec{namespace: "shop", kind: "Deployment", name: "api", replicas: 2}
+	usage := &usageStats{cpuP95: 0.25, cpuMax: 0.5, memP95: 0.5, memMax: 1.0}
+
+	// Within rightsizeThreshold of 288m / 589Mi / 1.25Gi
+	if _, ok := recommendResources(workload, testContainer("300m", "", "600Mi", "1280Mi"), usage, "7d"); ok {
+		t.Error("expected no recommendation for a change below the threshold")
+	}
+}
+
+func TestRounding(t *testing.T) {
+	if got := roundCPU(0.2501); !approxEqual(got, 0.251) {
+		t.Errorf("roundCPU(0.2501) = %v, want 0.251", got)
+	}
+	if got := roundMemory(0.5 + 1e-6); !approxEqual(got, 513.0/1024) {
+		t.Errorf("roundMemory(512Mi + a bit) = %v, want 513Mi", got*1024)
+	}
+	if got := formatCPU(0.288); got != "288m" {
+		t.Errorf("formatCPU(0.288) = %q", got)
+	}
+	if got := formatMemory(589.0 / 1024); got != "589Mi" {
+		t.Errorf("formatMemory(589Mi) = %q", got)
+	}
+}
@@ -2,10 +2,12 @@ package analyzer
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+	"github.com/opscart/opscart-k8s-watcher/pkg/kube"
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,8 +16,11 @@ import (
 
 // SecurityAuditor performs security analysis on cluster workloads
 type SecurityAuditor struct {
-	clientset *kubernetes.Clientset
-	ctx       context.Context
+	clientset    *kubernetes.Clientset
+	ctx          context.Context
+	suppressions *config.Suppressions // Accepted-risk exceptions (see SetSuppressions)
+	baseline     *models.SecurityBaseline
+	known        map[string]bool // Baseline fingerprints
 }
 
 // NewSecurityAuditor creates a new security auditor
@@ -26,6 +31,21 @@ func NewSecurityAuditor(clientset *kubernetes.Clientset) *SecurityAuditor {
 	}
 }
 
+// SetSuppressions excludes findings covered by accepted-risk exceptions from the audit
+func (sa *SecurityAuditor) SetSuppressions(suppressions *config.Suppressions) {
+	sa.suppressions = suppressions
+}
+
+// SetBaseline reports only findings missing from the baseline, plus baseline findings
+// that have been fixed since
+func (sa *SecurityAuditor) SetBaseline(baseline *models.SecurityBaseline) {
+	sa.baseline = baseline
+	sa.known = make(map[string]bool)
+	for _, finding := range baseline.Findings {
+		sa.known[finding.Fingerprint] = true
+	}
+}
+
 // AuditClusterSecurity performs comprehensive security audit
 func (sa *SecurityAuditor) AuditClusterSecurity(namespace string) (*models.SecurityAudit, error) {
 	audit := &models.SecurityAudit{
@@ -42,23 +62,74 @@ func (sa *SecurityAuditor) AuditClusterSecurity(namespace string) (*models.Secur
 
 	audit.TotalPodsAudited = len(podList.Items)
 
+	// Pods of a CronJob's Jobs are tracked as the CronJob, so findings survive each run
+	var cronJobs map[string]string
+	if jobList, err := sa.clientset.BatchV1().Jobs(namespace).List(sa.ctx, metav1.ListOptions{}); err == nil {
+		cronJobs = kube.JobCronJobs(jobList.Items)
+	}
+
 	// Audit each pod
+	now := time.Now()
+	seen := make(map[string]bool)
+	existing := 0
 	for _, pod := range podList.Items {
-		issues := sa.auditPod(pod)
-		audit.Issues = append(audit.Issues, issues...)
+		for _, issue := range sa.auditPod(pod) {
+			issue.Fingerprint = findingFingerprint(pod, cronJobs, issue)
+			seen[issue.Fingerprint] = true
+			if sa.suppress(audit, pod, cronJobs, issue, now) {
+				continue
+			}
+			if sa.known[issue.Fingerprint] {
+				existing++
+				continue
+			}
+			audit.Issues = append(audit.Issues, issue)
 
-		// Count risks
-		for _, issue := range issues {
+			// Count risks
 			sa.incrementRiskCounter(audit, issue.Type)
 		}
 	}
 
+	if sa.baseline != nil {
+		audit.Baseline = &models.BaselineComparison{
+			CreatedAt: sa.baseline.CreatedAt,
+			Existing:  existing,
+			Fixed:     fixedSinceBaseline(sa.baseline, namespace, seen),
+		}
+	}
+
 	// Generate priority actions
 	audit.PriorityActions = sa.generatePriorityActions(audit)
 
 	return audit, nil
 }
 
+// suppress moves a finding covered by an active exception to audit.Suppressed. A finding whose
+// exception has expired is kept (and counted) and also listed in audit.ExpiredSuppressions.
+func (sa *SecurityAuditor) suppress(audit *models.SecurityAudit, pod corev1.Pod, cronJobs map[string]string, issue models.SecurityIssue, now time.Time) bool {
+	if sa.suppressions == nil {
+		return false
+	}
+	_, workload := kube.PodWorkloadWithCronJobs(pod, cronJobs)
+	rule := sa.suppressions.Match(issue.Type, pod.Namespace, []string{workload, pod.Name}, pod.Labels, now)
+	if rule == nil {
+		return false
+	}
+
+	suppressed := models.SuppressedIssue{
+		SecurityIssue: issue,
+		Reason:        rule.Reason,
+		Owner:         rule.Owner,
+		Expires:       rule.Expires,
+	}
+	if rule.Expired(now) {
+		audit.ExpiredSuppressions = append(audit.ExpiredSuppressions, suppressed)
+		return false
+	}
+	audit.Suppressed = append(audit.Suppressed, suppressed)
+	return true
+}
+
 // auditPod checks a single pod for security issues
 func (sa *SecurityAuditor) auditPod(pod corev1.Pod) []models.SecurityIssue {
 	var issues []models.SecurityIssue
@@ -416,9 +487,9 @@ func countByEnvironment(issues []models.SecurityIssue, issueType string) map[str
 // ===================================================================
 
 // PrintSecurityAudit displays security audit results with CIS compliance
-func PrintSecurityAudit(audit *models.SecurityAudit, format string) {
+func PrintSecurityAudit(clusterName string, audit *models.SecurityAudit, format string) {
 	if format == "json" {
-		PrintSecurityAuditJSON(audit)
+		PrintSecurityAuditJSON(clusterName, audit)
 		return
 	}
 
@@ -435,6 +506,12 @@ func PrintSecurityAudit(audit *models.SecurityAudit, format string) {
 	// Print detailed findings with specific resources
 	printDetailedFindings(audit)
 
+	// Accepted risks and lapsed exceptions
+	printSuppressions(audit)
+
+	// Baseline findings that are gone
+	printFixedSinceBaseline(audit)
+
 	// Print recommendations
 	printRecommendations(audit)
 
@@ -460,7 +537,17 @@ func printClusterSummary(audit *models.SecurityAudit) {
 	fmt.Println("CLUSTER SECURITY SUMMARY")
 	fmt.Println("═══════════════════════════════════════════════════════════")
 	fmt.Printf("Pods Scanned: %d\n", audit.TotalPodsAudited)
-	fmt.Printf("Issues Found: %d\n", len(audit.Issues))
+	if audit.Baseline != nil {
+		fmt.Printf("Baseline:     %s\n", audit.Baseline.CreatedAt.Local().Format("2006-01-02 15:04"))
+		fmt.Printf("New Issues:   %d\n", len(audit.Issues))
+		fmt.Printf("In Baseline:  %d (not reported, not scored)\n", audit.Baseline.Existing)
+		fmt.Printf("Fixed:        %d\n", len(audit.Baseline.Fixed))
+	} else {
+		fmt.Printf("Issues Found: %d\n", len(audit.Issues))
+	}
+	if len(audit.Suppressed) > 0 {
+		fmt.Printf("Suppressed:   %d (accepted risk, not scored)\n", len(audit.Suppressed))
+	}
 	fmt.Println()
 }
 
@@ -584,6 +671,73 @@ func printFindingWithResources(name string, count int, risk string, allIssues []
 	}
 }
 
+// printSuppressions lists suppressed findings and findings whose exception has expired,
+// grouped by exception
+func printSuppressions(audit *models.SecurityAudit) {
+	if len(audit.Suppressed) == 0 && len(audit.ExpiredSuppressions) == 0 {
+		return
+	}
+
+	fmt.Println("\n═══════════════════════════════════════════════════════════")
+	fmt.Println("ACCEPTED RISKS")
+	fmt.Println("═══════════════════════════════════════════════════════════")
+
+	if len(audit.ExpiredSuppressions) > 0 {
+		fmt.Printf("\n⏰ EXPIRED EXCEPTIONS (%d findings counted again - fix or renew):\n", len(audit.ExpiredSuppressions))
+		printSuppressionGroups(audit.ExpiredSuppressions)
+	}
+	if len(audit.Suppressed) > 0 {
+		fmt.Printf("\n🔕 SUPPRESSED (%d findings, excluded from the score):\n", len(audit.Suppressed))
+		printSuppressionGroups(audit.Suppressed)
+	}
+	fmt.Println()
+}
+
+// printSuppressionGroups prints one entry per exception with its top 5 resources
+func printSuppressionGroups(issues []models.SuppressedIssue) {
+	var order []string
+	groups := make(map[string][]models.SuppressedIssue)
+	for _, issue := range issues {
+		key := issue.Reason + "|" + issue.Owner + "|" + issue.Expires
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], issue)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		first := group[0]
+		fmt.Printf("  • %s: %d (owner %s, expires %s)\n", first.Reason, len(group), first.Owner, first.Expires)
+		for i, issue := range group {
+			if i == 5 {
+				fmt.Printf("      ... and %d more\n", len(group)-5)
+				break
+			}
+			fmt.Printf("      %d. %s: %s in namespace %s\n", i+1, issue.Type, issue.Name, issue.Namespace)
+		}
+	}
+}
+
+// printFixedSinceBaseline lists baseline findings no longer present
+func printFixedSinceBaseline(audit *models.SecurityAudit) {
+	if audit.Baseline == nil || len(audit.Baseline.Fixed) == 0 {
+		return
+	}
+
+	fmt.Println("\n═══════════════════════════════════════════════════════════")
+	fmt.Println("FIXED SINCE BASELINE")
+	fmt.Println("═══════════════════════════════════════════════════════════")
+	for i, issue := range audit.Baseline.Fixed {
+		if i == 10 {
+			fmt.Printf("  ... and %d more\n", len(audit.Baseline.Fixed)-10)
+			break
+		}
+		fmt.Printf("  ✅ %s: %s in namespace %s\n", issue.Type, issue.Name, issue.Namespace)
+	}
+	fmt.Println()
+}
+
 func printFinding(name string, count int, risk string) {
 	if count > 0 {
 		fmt.Printf("  • %s: %d (%s)\n", name, count, risk)
@@ -672,33 +826,59 @@ func validateCounting(audit *models.SecurityAudit) {
 	fmt.Println()
 }
 
+// SecurityAuditOutput is the data of `security --format json`
+type SecurityAuditOutput struct {
+	Disclaimer  string                 `json:"disclaimer"`
+	PodsScanned int                    `json:"pods_scanned"`
+	IssuesFound int                    `json:"issues_found"`
+	CISScore    int                    `json:"cis_score"`
+	CISPassed   int                    `json:"cis_passed"`
+	CISFailed   int                    `json:"cis_failed"`
+	CISControls []CISControl           `json:"cis_controls"`
+	Risks       models.SecurityRisks   `json:"risks"`
+	Issues      []models.SecurityIssue `json:"issues"`
+	Actions     []string               `json:"priority_actions"`
+
+	Suppressed          []models.SuppressedIssue `json:"suppressed"`
+	ExpiredSuppressions []models.SuppressedIssue `json:"expired_suppressions"`
+
+	Baseline *models.BaselineComparison `json:"baseline,omitempty"`
+}
+
 // PrintSecurityAuditJSON outputs security audit in JSON format
-func PrintSecurityAuditJSON(audit *models.SecurityAudit) {
+func PrintSecurityAuditJSON(clusterName string, audit *models.SecurityAudit) {
 	// Calculate CIS score
 	cisResult := CalculateCISScore(audit)
 
-	output := struct {
-		Disclaimer  string                 `json:"disclaimer"`
-		PodsScanned int                    `json:"pods_scanned"`
-		IssuesFound int                    `json:"issues_found"`
-		CISScore    int                    `json:"cis_score"`
-		CISPassed   int                    `json:"cis_passed"`
-		CISFailed   int                    `json:"cis_failed"`
-		Risks       models.SecurityRisks   `json:"risks"`
-		Issues      []models.SecurityIssue `json:"issues"`
-		Actions     []string               `json:"priority_actions"`
-	}{
+	output := SecurityAuditOutput{
 		Disclaimer:  "Security awareness tool - not for compliance auditing. Use kube-bench for complete CIS assessment.",
 		PodsScanned: audit.TotalPodsAudited,
 		IssuesFound: len(audit.Issues),
 		CISScore:    cisResult.Score,
 		CISPassed:   cisResult.PassedChecks,
 		CISFailed:   cisResult.FailedChecks,
+		CISControls: cisResult.Controls,
 		Risks:       audit.Risks,
 		Issues:      audit.Issues,
 		Actions:     audit.PriorityActions,
+
+		Suppressed:          audit.Suppressed,
+		ExpiredSuppressions: audit.ExpiredSuppressions,
+
+		Baseline: audit.Baseline,
+	}
+	if output.Issues == nil {
+		output.Issues = []models.SecurityIssue{}
+	}
+	if output.Actions == nil {
+		output.Actions = []string{}
+	}
+	if output.Suppressed == nil {
+		output.Suppressed = []models.SuppressedIssue{}
+	}
+	if output.ExpiredSuppressions == nil {
+		output.ExpiredSuppressions = []models.SuppressedIssue{}
 	}
 
-	jsonData, _ := json.MarshalIndent(output, "", "  ")
-	fmt.Println(string(jsonData))
+	models.PrintEnvelope("security", clusterName, output)
 }
@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+	"github.com/opscart/opscart-k8s-watcher/pkg/kube"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Eviction annotations honored by cluster-autoscaler and Karpenter
+const (
+	safeToEvictAnnotation  = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	doNotDisruptAnnotation = "karpenter.sh/do-not-disrupt"
+	doNotEvictAnnotation   = "karpenter.sh/do-not-evict" // Karpenter < v0.32
+)
+
+// SetEnvironments replaces the default namespace environment classifier
+func (ra *ResourceAnalyzer) SetEnvironments(environments config.EnvironmentConfig) {
+	ra.environments = environments
+}
+
+// spotChecker holds the cluster state spot verdicts depend on
+type spotChecker struct {
+	environments  config.EnvironmentConfig
+	namespaceEnvs map[string]string
+	spotNodes     map[string]bool
+	pdbs          map[string][]policyv1.PodDisruptionBudget
+	replicas      map[string]int // namespace/kind/name -> running pods
+}
+
+// newSpotChecker loads namespace environments and PDBs. Best-effort: a failed list skips that check.
+func (ra *ResourceAnalyzer) newSpotChecker(namespace string, pods []corev1.Pod, nodes []models.NodeResourceUsage) *spotChecker {
+	checker := &spotChecker{
+		environments:  ra.environments,
+		namespaceEnvs: make(map[string]string),
+		spotNodes:     make(map[string]bool),
+		pdbs:          make(map[string][]policyv1.PodDisruptionBudget),
+		replicas:      make(map[string]int),
+	}
+
+	if namespace != "" {
+		if ns, err := ra.clientset.CoreV1().Namespaces().Get(ra.ctx, namespace, metav1.GetOptions{}); err == nil {
+			checker.namespaceEnvs[ns.Name] = checker.environments.Classify(ns.Name, ns.Labels)
+		}
+	} else if nsList, err := ra.clientset.CoreV1().Namespaces().List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, ns := range nsList.Items {
+			checker.namespaceEnvs[ns.Name] = checker.environments.Classify(ns.Name, ns.Labels)
+		}
+	}
+
+	for _, node := range nodes {
+		checker.spotNodes[node.Name] = node.Spot
+	}
+
+	if pdbList, err := ra.clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ra.ctx, metav1.ListOptions{}); err == nil {
+		for _, pdb := range pdbList.Items {
+			checker.pdbs[pdb.Namespace] = append(checker.pdbs[pdb.Namespace], pdb)
+		}
+	}
+
+	for _, pod := range pods {
+		if podFinished(pod) {
+			continue
+		}
+		kind, name := kube.PodWorkload(pod)
+		checker.replicas[pod.Namespace+"/"+kind+"/"+name]++
+	}
+
+	return checker
+}
+
+// evaluate returns the pod's spot verdict and the reason for it
+func (c *spotChecker) evaluate(pod corev1.Pod, kind, name string) models.PodSpotEligibility {
+	verdict := models.PodSpotEligibility{
+		Namespace:   pod.Namespace,
+		Pod:         pod.Name,
+		Workload:    kind + "/" + name,
+		Node:        pod.Spec.NodeName,
+		Environment: c.namespaceEnvs[pod.Namespace],
+		OnSpot:      c.spotNodes[pod.Spec.NodeName],
+	}
+	if c.namespaceEnvs[pod.Namespace] == "" {
+		// Namespace not listed (e.g. no permission) - classify by name alone
+		verdict.Environment = c.environments.Classify(pod.Namespace, nil)
+	}
+
+	blocker := c.blocker(pod, kind, name, verdict.Environment)
+	verdict.Eligible = blocker == ""
+
+	switch {
+	case verdict.OnSpot && verdict.Eligible:
+		verdict.Reason = "already on spot capacity"
+	case verdict.OnSpot:
+		verdict.Reason = "already on spot capacity, but at risk: " + blocker
+	case verdict.Eligible:
+		verdict.Reason = c.eligibleReason(pod, kind, name)
+	default:
+		verdict.Reason = blocker
+	}
+	return verdict
+}
+
+// blocker returns why the pod should not run on spot ("" when nothing blocks it)
+func (c *spotChecker) blocker(pod corev1.Pod, kind, name, environment string) string {
+	if podFinished(pod) {
+		return "finished - holds no capacity"
+	}
+	if kind == "DaemonSet" {
+		return "DaemonSet - runs on every node, spot or not"
+	}
+	if pod.Annotations[safeToEvictAnnotation] == "false" {
+		return safeToEvictAnnotation + "=false"
+	}
+	if pod.Annotations[doNotDisruptAnnotation] == "true" {
+		return doNotDisruptAnnotation + "=true"
+	}
+	if pod.Annotations[doNotEvictAnnotation] == "true" {
+		return doNotEvictAnnotation + "=true"
+	}
+	if environment != "" && !c.environments.SpotAllowed(environment) {
+		return fmt.Sprintf("namespace classified as %s", environment)
+	}
+	if kind == "StatefulSet" {
+		return "StatefulSet - stable identity and storage"
+	}
+	if kind == "Pod" {
+		return "no controller - would not be recreated after an interruption"
+	}
+
+	safeToEvict := pod.Annotations[safeToEvictAnnotation] == "true"
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return fmt.Sprintf("mounts PVC %s (zonal disk must follow the pod)", volume.PersistentVolumeClaim.ClaimName)
+		}
+		// Same rule as cluster-autoscaler: local storage blocks eviction unless marked safe
+		if volume.EmptyDir != nil && !safeToEvict {
+			return fmt.Sprintf("emptyDir %s - local data lost on interruption (set %s=true if disposable)", volume.Name, safeToEvictAnnotation)
+		}
+		if volume.HostPath != nil && !safeToEvict {
+			return fmt.Sprintf("hostPath %s - tied to the node", volume.Name)
+		}
+	}
+
+	// Jobs are retried after an interruption; everything else needs a second replica to stay up
+	if kind != "Job" && c.replicas[pod.Namespace+"/"+kind+"/"+name] < 2 {
+		return "single replica - an interruption means downtime"
+	}
+
+	if pdb := c.matchingPDB(pod); pdb != nil && pdb.Status.ExpectedPods > 0 && pdb.Status.DisruptionsAllowed == 0 {
+		return fmt.Sprintf("PDB %s allows no disruptions", pdb.Name)
+	}
+
+	return ""
+}
+
+// eligibleReason summarizes why an eligible pod can tolerate interruptions
+func (c *spotChecker) eligibleReason(pod corev1.Pod, kind, name string) string {
+	var parts []string
+	if kind == "Job" {
+		parts = append(parts, "Job - retried after interruption")
+	} else {
+		parts = append(parts, fmt.Sprintf("%d replicas", c.replicas[pod.Namespace+"/"+kind+"/"+name]))
+	}
+	if pod.Annotations[safeToEvictAnnotation] == "true" {
+		parts = append(parts, safeToEvictAnnotation+"=true")
+	} else {
+		parts = append(parts, "no local state")
+	}
+	if pdb := c.matchingPDB(pod); pdb != nil {
+		noun := "disruptions"
+		if pdb.Status.DisruptionsAllowed == 1 {
+			noun = "disruption"
+		}
+		parts = append(parts, fmt.Sprintf("PDB %s allows %d %s", pdb.Name, pdb.Status.DisruptionsAllowed, noun))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matchingPDB returns the first PDB in the pod's namespace selecting it
+func (c *spotChecker) matchingPDB(pod corev1.Pod) *policyv1.PodDisruptionBudget {
+	for i := range c.pdbs[pod.Namespace] {
+		pdb := &c.pdbs[pod.Namespace][i]
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return pdb
+		}
+	}
+	return nil
+}
+
+// isSpotNode detects spot/preemptible nodes from capacity-type labels or spot taints
+func isSpotNode(node corev1.Node) bool {
+	if (&config.Pricing{}).Attributes(node.Labels).CapacityType == config.CapacitySpot {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		key := strings.ToLower(taint.Key)
+		value := strings.ToLower(taint.Value)
+		if value == "spot" || value == "preemptible" ||
+			((strings.Contains(key, "spot") || strings.Contains(key, "preemptible")) && value != "false") {
+			return true
+		}
+	}
+	return false
+}
+
+// podFinished reports whether the pod has completed and no longer holds capacity
+func podFinished(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// addStorageAndNetworkCosts prices volumes, LoadBalancers and Ingresses and adds them
+// to the cluster total and to the namespaces that own them
+func (ca *CostAnalyzer) addStorageAndNetworkCosts(estimate *models.CostEstimate) {
+	estimate.ComputeCost = estimate.TotalClusterCost
+	for i := range estimate.NamespaceCosts {
+		estimate.NamespaceCosts[i].ComputeCost = estimate.NamespaceCosts[i].EstimatedCost.Best
+	}
+
+	storage := make(map[string]float64)
+	for _, volume := range ca.resourceAnalysis.Volumes {
+		info := models.StorageCostInfo{
+			Namespace:       volume.Namespace,
+			Name:            volume.Name,
+			Volume:          volume.VolumeName,
+			StorageClass:    volume.StorageClass,
+			Status:          volume.Status,
+			PricePerGBMonth: ca.pricing.StoragePrice(volume.StorageClass),
+		}
+		if size, err := resource.ParseQuantity(volume.Size); err == nil {
+			info.SizeGB = float64(size.Value()) / (1024 * 1024 * 1024)
+		}
+
+		// Unbound claims have nothing provisioned yet, so nothing is billed
+		if volume.Status == string(corev1.ClaimBound) || volume.Status == string(corev1.VolumeReleased) {
+			info.MonthlyCost = info.SizeGB * info.PricePerGBMonth
+		}
+		if volume.Status == string(corev1.VolumeReleased) {
+			estimate.UnusedStorageCost += info.MonthlyCost
+		}
+
+		estimate.StorageCost += info.MonthlyCost
+		storage[volume.Namespace] += info.MonthlyCost
+		estimate.StorageCosts = append(estimate.StorageCosts, info)
+	}
+	sort.SliceStable(estimate.StorageCosts, func(i, j int) bool {
+		return estimate.StorageCosts[i].MonthlyCost > estimate.StorageCosts[j].MonthlyCost
+	})
+
+	network := make(map[string]float64)
+	for _, lb := range ca.resourceAnalysis.LoadBalancers {
+		cost := ca.pricing.LoadBalancerPrice()
+		estimate.NetworkCosts = append(estimate.NetworkCosts, models.NetworkCostInfo{
+			Namespace: lb.Namespace, Name: lb.Name, Kind: "LoadBalancer", MonthlyCost: cost,
+		})
+		estimate.NetworkCost += cost
+		network[lb.Namespace] += cost
+	}
+	if ingressCost := ca.pricing.IngressPrice(); ingressCost > 0 {
+		for _, ing := range ca.resourceAnalysis.Ingresses {
+			estimate.NetworkCosts = append(estimate.NetworkCosts, models.NetworkCostInfo{
+				Namespace: ing.Namespace, Name: ing.Name, Kind: "Ingress", MonthlyCost: ingressCost,
+			})
+			estimate.NetworkCost += ingressCost
+			network[ing.Namespace] += ingressCost
+		}
+	}
+
+	// Allocate to namespaces; storage/network are fixed costs, so they shift the whole range
+	seen := make(map[string]bool)
+	for i := range estimate.NamespaceCosts {
+		nsCost := &estimate.NamespaceCosts[i]
+		seen[nsCost.Name] = true
+		addFixedCost(nsCost, storage[nsCost.Name], network[nsCost.Name])
+	}
+	for _, ns := range sortedKeys(storage, network) {
+		if seen[ns] || ns == "" {
+			continue
+		}
+		nsCost := models.NamespaceCostInfo{Name: ns}
+		addFixedCost(&nsCost, storage[ns], network[ns])
+		estimate.NamespaceCosts = append(estimate.NamespaceCosts, nsCost)
+	}
+
+	estimate.TotalClusterCost += estimate.StorageCost + estimate.NetworkCost
+}
+
+// addFixedCost adds a namespace's storage and network cost to its estimate
+func addFixedCost(nsCost *models.NamespaceCostInfo, storage, network float64) {
+	nsCost.StorageCost = storage
+	nsCost.NetworkCost = network
+	nsCost.EstimatedCost.Low += storage + network
+	nsCost.EstimatedCost.Best += storage + network
+	nsCost.EstimatedCost.High += storage + network
+}
+
+// sortedKeys returns the union of the maps' keys in sorted order
+func sortedKeys(maps ...map[string]float64) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,21 +4,104 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// defaultPrometheusWindow is the history window used for right-sizing when none is configured
+const defaultPrometheusWindow = "7d"
+
+// defaultJumpThreshold is the week-over-week cost increase (percent) flagged when none is configured
+const defaultJumpThreshold = 20
+
 // ClusterConfig represents a single cluster entry
 type ClusterConfig struct {
-	Name    string `yaml:"name"`
-	Context string `yaml:"context"`
-	Group   string `yaml:"group"`
+	Name          string `yaml:"name"`
+	Context       string `yaml:"context"`
+	Group         string `yaml:"group"`
+	PrometheusURL string `yaml:"prometheus_url,omitempty"` // Overrides prometheus.url for this cluster
+}
+
+// PrometheusConfig points at a Prometheus API used for historical usage
+type PrometheusConfig struct {
+	URL         string `yaml:"url"`
+	Window      string `yaml:"window"`       // History window, e.g. 7d, 14d
+	BearerToken string `yaml:"bearer_token"` // Optional, sent as Authorization: Bearer
+}
+
+// BudgetConfig sets monthly cost ceilings per namespace or label value
+type BudgetConfig struct {
+	JumpThreshold float64                       `yaml:"jump_threshold"` // Percent increase vs last week's run
+	Namespaces    map[string]float64            `yaml:"namespaces"`
+	Labels        map[string]map[string]float64 `yaml:"labels"` // label key -> value -> monthly ceiling
 }
 
 // OpsCartConfig represents the full config file
 type OpsCartConfig struct {
-	Clusters []ClusterConfig     `yaml:"clusters"`
-	Groups   map[string][]string `yaml:"groups"`
+	Clusters   []ClusterConfig     `yaml:"clusters"`
+	Groups     map[string][]string `yaml:"groups"`
+	Prometheus PrometheusConfig    `yaml:"prometheus"`
+
+	// Optional node pricing file for the costs command (see LoadPricing)
+	PricingFile string `yaml:"pricing_file"`
+
+	Budgets BudgetConfig `yaml:"budgets"`
+
+	// Namespace environments, used to keep e.g. production off spot (see EnvironmentSettings)
+	Environments EnvironmentConfig `yaml:"environments"`
+
+	// Where report files go and how long they are kept
+	Reports ReportsConfig `yaml:"reports"`
+}
+
+// ReportsConfig sets the report output location, file naming and retention
+type ReportsConfig struct {
+	Dir              string `yaml:"dir"`               // Default: ./reports
+	FilenameTemplate string `yaml:"filename_template"` // Relative to Dir, default {date}/{cluster}-{kind}-{timestamp}.{ext}
+	RetentionDays    int    `yaml:"retention_days"`    // Reports older than this are deleted after each run; 0 keeps them
+
+	Template string         `yaml:"template"` // Custom html/template for HTML reports (--template overrides)
+	Branding BrandingConfig `yaml:"branding"`
+}
+
+// BrandingConfig puts a company's name, logo and colors on HTML reports
+type BrandingConfig struct {
+	CompanyName    string `yaml:"company_name"`
+	Logo           string `yaml:"logo"`            // Image URL or local file (embedded in the report)
+	PrimaryColor   string `yaml:"primary_color"`   // Hex, e.g. "#0b5fff"
+	SecondaryColor string `yaml:"secondary_color"` // Gradient end; defaults to primary_color
+}
+
+// ExpandHome resolves a leading ~/ in paths read from the config
+func ExpandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// WriteFileAtomic writes data to path (mode 0644) through a uniquely named temp file in the
+// same directory and a rename, so an interrupted or concurrent run never leaves it partial
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // ConfigPaths returns global and local config paths
@@ -123,6 +206,27 @@ func (c *OpsCartConfig) GetClustersByGroup(group string) ([]ClusterConfig, error
 	return clusters, nil
 }
 
+// PrometheusFor returns the Prometheus settings for a cluster (URL empty when not configured)
+func (c *OpsCartConfig) PrometheusFor(clusterName string) PrometheusConfig {
+	prom := c.Prometheus
+	if cluster, err := c.GetClusterByName(clusterName); err == nil && cluster.PrometheusURL != "" {
+		prom.URL = cluster.PrometheusURL
+	}
+	if prom.Window == "" {
+		prom.Window = defaultPrometheusWindow
+	}
+	return prom
+}
+
+// BudgetSettings returns the budget config with defaults applied
+func (c *OpsCartConfig) BudgetSettings() BudgetConfig {
+	budgets := c.Budgets
+	if budgets.JumpThreshold <= 0 {
+		budgets.JumpThreshold = defaultJumpThreshold
+	}
+	return budgets
+}
+
 // GetAllClusters returns all configured clusters
 func (c *OpsCartConfig) GetAllClusters() []ClusterConfig {
 	return c.Clusters
@@ -187,6 +291,74 @@ clusters:
 #   critical:           # custom group — can mix environments
 #     - prod-aks-01
 #     - staging-aks-01
+
+# Optional: Prometheus for history-based right-sizing (optimize/costs/resources)
+# Per-cluster endpoints can be set with 'prometheus_url' on a cluster entry.
+#
+# prometheus:
+#   url: http://prometheus.monitoring:9090
+#   window: 7d
+#   bearer_token: ""
+
+# Optional: node pricing file for 'costs' (used instead of --monthly-cost)
+# Maps instance type / capacity type / zone labels to hourly prices:
+#
+#   hours_per_month: 730
+#   default_hourly: 0.10
+#   nodes:
+#     - instance_type: Standard_D4s_v5
+#       capacity_type: on-demand
+#       hourly: 0.192
+#     - instance_type: Standard_D4s_v5
+#       capacity_type: spot
+#       hourly: 0.038
+#   storage:              # $/GB-month per StorageClass
+#     managed-premium: 0.15
+#   load_balancer: 18     # $/month per LoadBalancer Service
+#   ingress: 0            # $/month per Ingress
+#
+# pricing_file: ~/.opscart/pricing.yaml
+
+# Optional: monthly budgets checked by 'costs' (use --fail-on-budget in CI)
+# Each run is stored and compared with last week's run; jumps above
+# jump_threshold percent are flagged.
+#
+# budgets:
+#   jump_threshold: 20
+#   namespaces:
+#     payments: 1500
+#   labels:
+#     team:              # applies with --group-by label:team
+#       checkout: 3000
+
+# Optional: namespace environments for spot recommendations (first rule wins).
+# Without rules, namespaces named production/prod or labelled
+# environment=production are production; production is never recommended for spot.
+#
+# environments:
+#   rules:
+#     - name: production
+#       namespaces: ["prod", "*-prod", "prod-*"]
+#     - name: production
+#       labels:
+#         env: prd
+#     - name: staging
+#       namespaces: ["*-staging"]
+#   spot_excluded: [production]
+
+# Optional: report output for 'report' and 'security --format=html'.
+# Placeholders: {cluster} {group} {kind} {format} {ext} {date} {timestamp}
+#
+# reports:
+#   dir: /var/lib/opscart/reports
+#   filename_template: "{group}/{date}/{cluster}-{kind}-{timestamp}.{ext}"
+#   retention_days: 30
+#   template: ~/.opscart/report.html.tmpl   # custom html/template, same data as the built-in report
+#   branding:
+#     company_name: Acme Corp
+#     logo: ~/.opscart/logo.png             # or https://...
+#     primary_color: "#0b5fff"
+#     secondary_color: "#00b3a4"
 `
 
 	if err := os.WriteFile(globalPath, []byte(sample), 0644); err != nil {
@@ -0,0 +1,79 @@
+package config
+
+import "path"
+
+// EnvironmentRule classifies namespaces into an environment by name pattern and/or namespace labels
+type EnvironmentRule struct {
+	Name       string            `yaml:"name"`
+	Namespaces []string          `yaml:"namespaces"` // Glob patterns, e.g. prod, *-prod, prod-*
+	Labels     map[string]string `yaml:"labels"`     // Namespace labels that must all match
+}
+
+// EnvironmentConfig classifies namespaces into environments (first matching rule wins)
+type EnvironmentConfig struct {
+	Rules        []EnvironmentRule `yaml:"rules"`
+	SpotExcluded []string          `yaml:"spot_excluded"` // Environments never recommended for spot
+}
+
+// defaultEnvironmentRules keeps the historical production/prod names and adds the common label
+var defaultEnvironmentRules = []EnvironmentRule{
+	{Name: "production", Namespaces: []string{"production", "prod"}},
+	{Name: "production", Labels: map[string]string{"environment": "production"}},
+}
+
+// EnvironmentSettings returns the environment classifier with defaults applied
+func (c *OpsCartConfig) EnvironmentSettings() EnvironmentConfig {
+	envs := c.Environments
+	if len(envs.Rules) == 0 {
+		envs.Rules = defaultEnvironmentRules
+	}
+	if envs.SpotExcluded == nil {
+		envs.SpotExcluded = []string{"production"}
+	}
+	return envs
+}
+
+// Classify returns the environment of a namespace ("" when no rule matches)
+func (e EnvironmentConfig) Classify(namespace string, labels map[string]string) string {
+	for _, rule := range e.Rules {
+		if rule.matches(namespace, labels) {
+			return rule.Name
+		}
+	}
+	return ""
+}
+
+// SpotAllowed reports whether workloads in an environment may be recommended for spot
+func (e EnvironmentConfig) SpotAllowed(environment string) bool {
+	for _, excluded := range e.SpotExcluded {
+		if excluded == environment {
+			return false
+		}
+	}
+	return true
+}
+
+// matches requires every condition the rule sets; a rule without conditions matches nothing
+func (r EnvironmentRule) matches(namespace string, labels map[string]string) bool {
+	if len(r.Namespaces) == 0 && len(r.Labels) == 0 {
+		return false
+	}
+	if len(r.Namespaces) > 0 {
+		matched := false
+		for _, pattern := range r.Namespaces {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for key, value := range r.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
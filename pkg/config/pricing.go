@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultHoursPerMonth converts hourly node prices to monthly cost
+const defaultHoursPerMonth = 730
+
+// Capacity types after normalization
+const (
+	CapacitySpot     = "spot"
+	CapacityOnDemand = "on-demand"
+)
+
+// Well-known node labels, checked in order when the pricing file does not override them
+var (
+	defaultInstanceTypeLabels = []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"}
+	defaultZoneLabels         = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+	defaultCapacityTypeLabels = []string{
+		"karpenter.sh/capacity-type",
+		"eks.amazonaws.com/capacityType",
+		"kubernetes.azure.com/scalesetpriority",
+		"cloud.google.com/gke-spot",
+		"cloud.google.com/gke-preemptible",
+	}
+)
+
+// Pricing is a node pricing file mapping instance type, capacity type and zone to hourly prices
+type Pricing struct {
+	HoursPerMonth float64       `yaml:"hours_per_month"` // Default 730
+	DefaultHourly float64       `yaml:"default_hourly"`  // Used for nodes no entry matches (0 = unpriced)
+	Labels        PricingLabels `yaml:"labels"`
+	Nodes         []NodePrice   `yaml:"nodes"`
+
+	// Storage and networking (0 = built-in default)
+	Storage        map[string]float64 `yaml:"storage"`         // StorageClass -> $/GB-month
+	DefaultStorage float64            `yaml:"default_storage"` // $/GB-month for classes not listed
+	LoadBalancer   float64            `yaml:"load_balancer"`   // $/month per LoadBalancer Service
+	Ingress        float64            `yaml:"ingress"`         // $/month per Ingress (e.g. cloud ALB/App Gateway)
+}
+
+// Typical cloud list prices, used when the pricing file does not set them
+const (
+	DefaultStoragePerGBMonth   = 0.10
+	DefaultLoadBalancerMonthly = 18.0
+)
+
+// StoragePrice returns the $/GB-month for a StorageClass (built-in default without a pricing file)
+func (p *Pricing) StoragePrice(storageClass string) float64 {
+	if p != nil {
+		if price, ok := p.Storage[storageClass]; ok && price > 0 {
+			return price
+		}
+		if p.DefaultStorage > 0 {
+			return p.DefaultStorage
+		}
+	}
+	return DefaultStoragePerGBMonth
+}
+
+// LoadBalancerPrice returns the monthly cost of one LoadBalancer Service
+func (p *Pricing) LoadBalancerPrice() float64 {
+	if p != nil && p.LoadBalancer > 0 {
+		return p.LoadBalancer
+	}
+	return DefaultLoadBalancerMonthly
+}
+
+// IngressPrice returns the monthly cost of one Ingress (0 unless priced - most share the controller's LoadBalancer)
+func (p *Pricing) IngressPrice() float64 {
+	if p != nil {
+		return p.Ingress
+	}
+	return 0
+}
+
+// PricingLabels overrides which node labels identify instance type, capacity type and zone
+type PricingLabels struct {
+	InstanceType string `yaml:"instance_type"`
+	CapacityType string `yaml:"capacity_type"`
+	Zone         string `yaml:"zone"`
+}
+
+// NodePrice is one pricing entry; empty fields match any node
+type NodePrice struct {
+	InstanceType string  `yaml:"instance_type"`
+	CapacityType string  `yaml:"capacity_type"` // spot or on-demand
+	Zone         string  `yaml:"zone"`
+	Hourly       float64 `yaml:"hourly"`
+}
+
+// NodeAttributes identifies a node for pricing from its labels
+type NodeAttributes struct {
+	InstanceType string
+	CapacityType string
+	Zone         string
+}
+
+// LoadPricing reads and validates a pricing file
+func LoadPricing(path string) (*Pricing, error) {
+	path = ExpandHome(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pricing file (%s): %w", path, err)
+	}
+
+	pricing := &Pricing{}
+	if err := yaml.Unmarshal(data, pricing); err != nil {
+		return nil, fmt.Errorf("error parsing pricing file (%s): %w", path, err)
+	}
+
+	if pricing.HoursPerMonth <= 0 {
+		pricing.HoursPerMonth = defaultHoursPerMonth
+	}
+	for i, entry := range pricing.Nodes {
+		if entry.Hourly <= 0 {
+			return nil, fmt.Errorf("pricing file (%s): nodes[%d] needs an hourly price greater than 0", path, i)
+		}
+		if entry.CapacityType != "" {
+			pricing.Nodes[i].CapacityType = normalizeCapacityType(entry.CapacityType)
+		}
+	}
+	if len(pricing.Nodes) == 0 && pricing.DefaultHourly <= 0 {
+		return nil, fmt.Errorf("pricing file (%s) has no node prices and no default_hourly", path)
+	}
+
+	return pricing, nil
+}
+
+// Attributes reads instance type, capacity type and zone from node labels
+func (p *Pricing) Attributes(labels map[string]string) NodeAttributes {
+	return NodeAttributes{
+		InstanceType: firstLabel(labels, p.Labels.InstanceType, defaultInstanceTypeLabels),
+		CapacityType: normalizeCapacityType(firstLabel(labels, p.Labels.CapacityType, defaultCapacityTypeLabels)),
+		Zone:         firstLabel(labels, p.Labels.Zone, defaultZoneLabels),
+	}
+}
+
+// HourlyPrice returns the most specific matching price; false when nothing (not even the default) applies
+func (p *Pricing) HourlyPrice(attrs NodeAttributes) (float64, bool) {
+	best := -1
+	price := 0.0
+
+	for _, entry := range p.Nodes {
+		specificity := 0
+		if !matchField(entry.InstanceType, attrs.InstanceType, &specificity) ||
+			!matchField(entry.CapacityType, attrs.CapacityType, &specificity) ||
+			!matchField(entry.Zone, attrs.Zone, &specificity) {
+			continue
+		}
+		if specificity > best {
+			best = specificity
+			price = entry.Hourly
+		}
+	}
+
+	if best >= 0 {
+		return price, true
+	}
+	if p.DefaultHourly > 0 {
+		return p.DefaultHourly, true
+	}
+	return 0, false
+}
+
+// matchField matches an entry field against a node value; empty entries are wildcards
+func matchField(want, have string, specificity *int) bool {
+	if want == "" {
+		return true
+	}
+	if !strings.EqualFold(want, have) {
+		return false
+	}
+	*specificity++
+	return true
+}
+
+// firstLabel returns the override label's value, or the first well-known label present
+func firstLabel(labels map[string]string, override string, defaults []string) string {
+	if override != "" {
+		return labels[override]
+	}
+	for _, key := range defaults {
+		if value, ok := labels[key]; ok {
+			// GKE marks spot/preemptible nodes with "true"
+			if value == "true" && strings.HasPrefix(key, "cloud.google.com/") {
+				return CapacitySpot
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// normalizeCapacityType maps provider spellings (SPOT, ON_DEMAND, Regular, true) to spot or on-demand
+func normalizeCapacityType(value string) string {
+	switch strings.ToLower(strings.ReplaceAll(value, "_", "-")) {
+	case "spot", "preemptible", "true":
+		return CapacitySpot
+	default:
+		return CapacityOnDemand
+	}
+}
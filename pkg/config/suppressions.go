@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultSuppressionsFile is read from the working directory when no --ignore-file is given
+const DefaultSuppressionsFile = ".opscart-ignore.yaml"
+
+// Suppression accepts the risk of matching security findings until it expires. Every
+// condition it sets must match; each list matches when any of its entries does.
+type Suppression struct {
+	Types      []string      `yaml:"types"`      // Issue types, e.g. host_network
+	Namespaces []string      `yaml:"namespaces"` // Glob patterns
+	Workloads  []string      `yaml:"workloads"`  // Glob patterns on the owning workload or the pod name
+	Labels     LabelSelector `yaml:"labels"`     // Pod label selector
+	Reason     string        `yaml:"reason"`
+	Owner      string        `yaml:"owner"`
+	Expires    string        `yaml:"expires"` // YYYY-MM-DD, last day the exception applies
+
+	expires time.Time
+}
+
+// LabelSelector is a Kubernetes label selector, written as a selector string
+// ("team in (payments,billing),!legacy") or as a map of labels that must all match
+type LabelSelector struct {
+	labels.Selector
+}
+
+// UnmarshalYAML parses and validates the selector
+func (s *LabelSelector) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		selector, err := labels.Parse(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid label selector %q: %w", node.Line, node.Value, err)
+		}
+		s.Selector = selector
+	case yaml.MappingNode:
+		var set map[string]string
+		if err := node.Decode(&set); err != nil {
+			return err
+		}
+		selector, err := labels.ValidatedSelectorFromSet(set)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid labels: %w", node.Line, err)
+		}
+		s.Selector = selector
+	default:
+		return fmt.Errorf("line %d: labels must be a selector string or a map of labels", node.Line)
+	}
+	return nil
+}
+
+// set reports whether the selector has any requirement
+func (s LabelSelector) set() bool {
+	return s.Selector != nil && !s.Selector.Empty()
+}
+
+// Suppressions holds the accepted-risk exceptions
+type Suppressions struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// LoadSuppressions reads and validates an exceptions file
+func LoadSuppressions(path string) (*Suppressions, error) {
+	path = ExpandHome(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading suppressions file (%s): %w", path, err)
+	}
+
+	suppressions := &Suppressions{}
+	if err := yaml.Unmarshal(data, suppressions); err != nil {
+		return nil, fmt.Errorf("error parsing suppressions file (%s): %w", path, err)
+	}
+
+	for i := range suppressions.Suppressions {
+		rule := &suppressions.Suppressions[i]
+		if rule.Reason == "" || rule.Owner == "" || rule.Expires == "" {
+			return nil, fmt.Errorf("suppression %d in %s: reason, owner and expires are required", i+1, path)
+		}
+		if len(rule.Types) == 0 && len(rule.Namespaces) == 0 && len(rule.Workloads) == 0 && !rule.Labels.set() {
+			return nil, fmt.Errorf("suppression %d in %s: set at least one of types, namespaces, workloads or labels", i+1, path)
+		}
+		expires, err := time.ParseInLocation("2006-01-02", rule.Expires, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("suppression %d in %s: expires must be YYYY-MM-DD: %w", i+1, path, err)
+		}
+		rule.expires = expires
+	}
+
+	return suppressions, nil
+}
+
+// Match returns the suppression covering a finding, or nil. An active suppression wins over
+// an expired one; expired suppressions are still returned so the finding can be flagged.
+func (s *Suppressions) Match(issueType, namespace string, workloads []string, podLabels map[string]string, now time.Time) *Suppression {
+	var expired *Suppression
+	for i := range s.Suppressions {
+		rule := &s.Suppressions[i]
+		if !rule.matches(issueType, namespace, workloads, podLabels) {
+			continue
+		}
+		if !rule.Expired(now) {
+			return rule
+		}
+		if expired == nil {
+			expired = rule
+		}
+	}
+	return expired
+}
+
+// Expired reports whether the exception has lapsed (it covers its whole expiry day)
+func (r Suppression) Expired(now time.Time) bool {
+	return !now.Before(r.expires.AddDate(0, 0, 1))
+}
+
+// matches requires every condition the suppression sets
+func (r Suppression) matches(issueType, namespace string, workloads []string, podLabels map[string]string) bool {
+	if len(r.Types) > 0 && !matchesAny(r.Types, issueType) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !matchesAny(r.Namespaces, namespace) {
+		return false
+	}
+	if len(r.Workloads) > 0 {
+		matched := false
+		for _, workload := range workloads {
+			if matchesAny(r.Workloads, workload) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.Labels.set() && !r.Labels.Matches(labels.Set(podLabels)) {
+		return false
+	}
+	return true
+}
+
+// matchesAny reports whether value matches one of the glob patterns
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
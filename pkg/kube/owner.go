@@ -0,0 +1,73 @@
+// Package kube holds Kubernetes object helpers shared by the scanner and analyzer
+package kube
+
+import (
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// cronJobNameLabel names the CronJob that created a Job
+const cronJobNameLabel = "batch.kubernetes.io/cronjob-name"
+
+// PodController resolves a pod's top-level controller, walking ReplicaSets up to their
+// Deployment. ok is false for pods without a controller.
+func PodController(pod corev1.Pod) (kind, name string, ok bool) {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				return "Deployment", strings.TrimSuffix(ref.Name, "-"+hash), true
+			}
+		}
+		return ref.Kind, ref.Name, true
+	}
+	return "", "", false
+}
+
+// PodWorkload resolves the workload a pod belongs to; a bare pod is its own workload
+func PodWorkload(pod corev1.Pod) (kind, name string) {
+	if kind, name, ok := PodController(pod); ok {
+		return kind, name
+	}
+	return "Pod", pod.Name
+}
+
+// JobCronJobs maps "namespace/job" to the CronJob that created each Job, from its
+// controller reference or the cronjob-name label
+func JobCronJobs(jobs []batchv1.Job) map[string]string {
+	cronJobs := make(map[string]string)
+	for _, job := range jobs {
+		name := job.Labels[cronJobNameLabel]
+		for _, ref := range job.OwnerReferences {
+			if ref.Kind == "CronJob" && (ref.Controller == nil || *ref.Controller) {
+				name = ref.Name
+				break
+			}
+		}
+		if name != "" {
+			cronJobs[job.Namespace+"/"+job.Name] = name
+		}
+	}
+	return cronJobs
+}
+
+// PodWorkloadWithCronJobs resolves like PodWorkload, but attributes pods of a CronJob's Jobs to
+// the CronJob so they keep one identity across runs. cronJobs comes from JobCronJobs (nil
+// falls back to the pod's cronjob-name label).
+func PodWorkloadWithCronJobs(pod corev1.Pod, cronJobs map[string]string) (kind, name string) {
+	kind, name = PodWorkload(pod)
+	if kind != "Job" {
+		return kind, name
+	}
+	if cronJob := cronJobs[pod.Namespace+"/"+name]; cronJob != "" {
+		return "CronJob", cronJob
+	}
+	if cronJob := pod.Labels[cronJobNameLabel]; cronJob != "" {
+		return "CronJob", cronJob
+	}
+	return kind, name
+}
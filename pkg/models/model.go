@@ -4,15 +4,59 @@ import "time"
 
 // EmergencyIssue represents a critical problem found in the cluster
 type EmergencyIssue struct {
-	Severity  string        `json:"severity"` // critical, high, medium, low
-	Resource  string        `json:"resource"` // pod, deployment, pvc, etc.
-	Namespace string        `json:"namespace"`
-	Name      string        `json:"name"`
-	Reason    string        `json:"reason"`
-	Message   string        `json:"message"`
-	Age       time.Duration `json:"age"`
-	Restarts  int           `json:"restarts,omitempty"`
-	LastEvent string        `json:"last_event,omitempty"`
+	Severity  string       `json:"severity"` // critical, high, medium, low
+	Resource  string       `json:"resource"` // pod, deployment, pvc, etc.
+	Namespace string       `json:"namespace"`
+	Name      string       `json:"name"`
+	Reason    string       `json:"reason"`
+	Message   string       `json:"message"`
+	Age       Duration     `json:"age_seconds"`
+	Restarts  int          `json:"restarts,omitempty"`
+	LastEvent string       `json:"last_event,omitempty"`
+	Events    []IssueEvent `json:"events,omitempty"` // Most relevant recent events for the involved object
+
+	// Container diagnostics
+	Container        string         `json:"container,omitempty"`         // Set for container-level issues
+	TerminationClass string         `json:"termination_class,omitempty"` // e.g. "Segfault (139)"
+	Hint             string         `json:"hint,omitempty"`              // What the failure usually means
+	Logs             *ContainerLogs `json:"logs,omitempty"`              // Log tail, only with --with-logs
+
+	// Correlation context
+	Node         string   `json:"node,omitempty"`
+	Images       []string `json:"images,omitempty"`
+	Owner        string   `json:"owner,omitempty"`    // Kind/name of the owning controller, e.g. Deployment/api
+	Revision     string   `json:"revision,omitempty"` // Rollout revision or pod-template-hash
+	Claims       []string `json:"claims,omitempty"`   // PVC names
+	StorageClass string   `json:"storage_class,omitempty"`
+	IncidentID   string   `json:"incident_id,omitempty"` // Set when grouped into an Incident
+}
+
+// ContainerLogs holds the redacted log tail of a crashing container
+type ContainerLogs struct {
+	Previous []string `json:"previous,omitempty"` // Last lines before the most recent restart
+	Current  []string `json:"current,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Incident groups emergency issues that share a probable root cause
+type Incident struct {
+	ID            string   `json:"id"`
+	Cause         string   `json:"cause"` // node, config, storage, image, owner
+	Key           string   `json:"key"`   // The shared node, image, object or controller
+	ProbableCause string   `json:"probable_cause"`
+	Severity      string   `json:"severity"`
+	IssueCount    int      `json:"issue_count"`
+	Affected      []string `json:"affected"` // resource namespace/name
+}
+
+// IssueEvent is a Kubernetes Event attached to an emergency issue
+type IssueEvent struct {
+	Type      string    `json:"type"` // Normal, Warning
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
 }
 
 // ClusterSnapshot represents the current state of a cluster
@@ -42,25 +86,25 @@ type NamespaceInfo struct {
 
 // DeploymentInfo represents a deployment's status
 type DeploymentInfo struct {
-	Name              string        `json:"name"`
-	Namespace         string        `json:"namespace"`
-	Replicas          int32         `json:"replicas"`
-	ReadyReplicas     int32         `json:"ready_replicas"`
-	AvailableReplicas int32         `json:"available_replicas"`
-	Healthy           bool          `json:"healthy"`
-	Age               time.Duration `json:"age"`
-	Image             string        `json:"image"`
+	Name              string   `json:"name"`
+	Namespace         string   `json:"namespace"`
+	Replicas          int32    `json:"replicas"`
+	ReadyReplicas     int32    `json:"ready_replicas"`
+	AvailableReplicas int32    `json:"available_replicas"`
+	Healthy           bool     `json:"healthy"`
+	Age               Duration `json:"age_seconds"`
+	Image             string   `json:"image"`
 }
 
 // StatefulSetInfo represents a statefulset's status
 type StatefulSetInfo struct {
-	Name                 string        `json:"name"`
-	Namespace            string        `json:"namespace"`
-	Replicas             int32         `json:"replicas"`
-	ReadyReplicas        int32         `json:"ready_replicas"`
-	Healthy              bool          `json:"healthy"`
-	Age                  time.Duration `json:"age"`
-	VolumeClaimTemplates []string      `json:"volume_claim_templates"`
+	Name                 string   `json:"name"`
+	Namespace            string   `json:"namespace"`
+	Replicas             int32    `json:"replicas"`
+	ReadyReplicas        int32    `json:"ready_replicas"`
+	Healthy              bool     `json:"healthy"`
+	Age                  Duration `json:"age_seconds"`
+	VolumeClaimTemplates []string `json:"volume_claim_templates"`
 }
 
 // ServiceInfo represents a service
@@ -94,17 +138,17 @@ type PVCInfo struct {
 
 // PodInfo represents detailed pod information
 type PodInfo struct {
-	Name            string        `json:"name"`
-	Namespace       string        `json:"namespace"`
-	Phase           string        `json:"phase"`
-	Ready           bool          `json:"ready"`
-	Restarts        int           `json:"restarts"`
-	Age             time.Duration `json:"age"`
-	Node            string        `json:"node"`
-	IP              string        `json:"ip"`
-	Containers      int           `json:"containers"`
-	ReadyContainers int           `json:"ready_containers"`
-	Reason          string        `json:"reason,omitempty"`
+	Name            string   `json:"name"`
+	Namespace       string   `json:"namespace"`
+	Phase           string   `json:"phase"`
+	Ready           bool     `json:"ready"`
+	Restarts        int      `json:"restarts"`
+	Age             Duration `json:"age_seconds"`
+	Node            string   `json:"node"`
+	IP              string   `json:"ip"`
+	Containers      int      `json:"containers"`
+	ReadyContainers int      `json:"ready_containers"`
+	Reason          string   `json:"reason,omitempty"`
 }
 
 // IdleResource represents a resource that appears idle
@@ -114,8 +158,10 @@ type IdleResource struct {
 	Namespace       string    `json:"namespace"`
 	IdleDays        int       `json:"idle_days"`
 	LastActivity    time.Time `json:"last_activity"`
+	Reason          string    `json:"reason"` // Why it is considered idle
 	EstCostPerMonth float64   `json:"est_cost_per_month"`
 	Recommendation  string    `json:"recommendation"`
+	CleanupCommand  string    `json:"cleanup_command,omitempty"`
 }
 
 // ResourceSearchResult represents a found resource
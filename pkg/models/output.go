@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion identifies the shape of the JSON output. Bump the major version when a
+// field is renamed, removed or changes type; adding fields bumps the minor version.
+const SchemaVersion = "1.2"
+
+// ToolVersion is the opscart-scan version reported in JSON output
+// (override with -ldflags "-X github.com/opscart/opscart-k8s-watcher/pkg/models.ToolVersion=...")
+var ToolVersion = "0.3.0"
+
+// Envelope wraps every JSON output with what produced it. Data holds the command's
+// result; `opscart-scan schema <command>` prints its JSON Schema.
+type Envelope struct {
+	SchemaVersion string      `json:"schema_version"`
+	ToolVersion   string      `json:"tool_version"`
+	Command       string      `json:"command"`
+	Cluster       string      `json:"cluster"`
+	Timestamp     time.Time   `json:"timestamp"`
+	Data          interface{} `json:"data"`
+}
+
+// NewEnvelope wraps a command's result for JSON output, stamped with the current time
+func NewEnvelope(command, cluster string, data interface{}) Envelope {
+	return Envelope{
+		SchemaVersion: SchemaVersion,
+		ToolVersion:   ToolVersion,
+		Command:       command,
+		Cluster:       cluster,
+		Timestamp:     time.Now().UTC(),
+		Data:          data,
+	}
+}
+
+// PrintEnvelope writes a command's result to stdout as an indented JSON envelope. The
+// envelope goes out in a single write, so clusters scanned in parallel never interleave.
+func PrintEnvelope(command, cluster string, data interface{}) {
+	out, err := json.MarshalIndent(NewEnvelope(command, cluster, data), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+		return
+	}
+	os.Stdout.Write(append(out, '\n'))
+}
+
+// Duration is a time span serialized as whole seconds, so JSON consumers get a plain number
+type Duration time.Duration
+
+// MarshalJSON writes the duration in whole seconds
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(time.Duration(d)/time.Second), 10)), nil
+}
+
+// UnmarshalJSON reads a duration written by MarshalJSON
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	seconds, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("duration must be whole seconds: %w", err)
+	}
+	*d = Duration(time.Duration(seconds) * time.Second)
+	return nil
+}
@@ -14,13 +14,58 @@ type ClusterResourceAnalysis struct {
 	CPUUtilization       float64 `json:"cpu_utilization"`    // Percentage
 	MemoryUtilization    float64 `json:"memory_utilization"` // Percentage
 
+	// Actual usage from metrics-server (zero when MetricsAvailable is false)
+	MetricsAvailable   bool    `json:"metrics_available"`
+	MetricsError       string  `json:"metrics_error,omitempty"`
+	TotalCPUUsed       float64 `json:"total_cpu_used"`
+	TotalMemoryUsed    float64 `json:"total_memory_used"`
+	CPUUsagePercent    float64 `json:"cpu_usage_percent"`    // Node usage vs capacity
+	MemoryUsagePercent float64 `json:"memory_usage_percent"` // Node usage vs capacity
+
+	// Node breakdown, used for node-priced cost allocation
+	Nodes []NodeResourceUsage `json:"nodes"`
+
 	// Namespace breakdown
 	Namespaces []NamespaceResourceUsage `json:"namespaces"`
 
+	// Showback groups (only with --group-by)
+	GroupBy   string               `json:"group_by,omitempty"` // e.g. "label:team"
+	Groups    []GroupResourceUsage `json:"groups,omitempty"`
+	PodGroups map[string]string    `json:"-"` // namespace/pod -> group, for attributing findings
+
+	// Billable storage and networking, priced by the cost analyzer
+	Volumes       []PVCDetail   `json:"volumes"` // PVCs, plus Released PVs with Status "Released"
+	LoadBalancers []ServiceInfo `json:"load_balancers"`
+	Ingresses     []IngressInfo `json:"ingresses"`
+
+	// Workload breakdown (Deployment, StatefulSet, DaemonSet, Job, standalone Pod)
+	Workloads []WorkloadResourceUsage `json:"workloads"`
+
+	// Per-pod spot verdicts with the reason for each
+	SpotEligibility []PodSpotEligibility `json:"spot_eligibility"`
+
+	// History-based right-sizing (only when Prometheus is configured)
+	HistorySource string                 `json:"history_source,omitempty"` // e.g. "prometheus"
+	HistoryWindow string                 `json:"history_window,omitempty"` // e.g. "7d"
+	HistoryError  string                 `json:"history_error,omitempty"`
+	Rightsizing   []ContainerRightsizing `json:"rightsizing,omitempty"`
+
 	// Optimization opportunities
 	Optimizations []Optimization `json:"optimizations"`
 }
 
+// PodSpotEligibility explains whether a pod can move to spot capacity
+type PodSpotEligibility struct {
+	Namespace   string `json:"namespace"`
+	Pod         string `json:"pod"`
+	Workload    string `json:"workload"` // Kind/name of the owning workload
+	Node        string `json:"node,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Eligible    bool   `json:"eligible"`
+	OnSpot      bool   `json:"on_spot"` // Already running on a spot node
+	Reason      string `json:"reason"`
+}
+
 // NamespaceResourceUsage represents resource usage for a single namespace
 type NamespaceResourceUsage struct {
 	Name string `json:"name"`
@@ -30,15 +75,29 @@ type NamespaceResourceUsage struct {
 	MemoryGBRequested float64 `json:"memory_gb_requested"`
 	PodCount          int     `json:"pod_count"`
 
+	// Limits and actual usage
+	CPUCoresLimit    float64 `json:"cpu_cores_limit"`
+	MemoryGBLimit    float64 `json:"memory_gb_limit"`
+	CPUCoresUsed     float64 `json:"cpu_cores_used"`
+	MemoryGBUsed     float64 `json:"memory_gb_used"`
+	CPUEfficiency    float64 `json:"cpu_efficiency"`    // Usage as % of requests
+	MemoryEfficiency float64 `json:"memory_efficiency"` // Usage as % of requests
+
 	// Cluster percentage
 	CPUPercent    float64 `json:"cpu_percent"`
 	MemoryPercent float64 `json:"memory_percent"`
 
 	// Waste indicators
 	IdlePods         int      `json:"idle_pods"`
+	FinishedPods     int      `json:"finished_pods"`          // Idle pods that have completed or failed; they hold no capacity
+	IdleCPUCores     float64  `json:"idle_cpu_cores"`         // Requests of idle running pods
+	IdleMemoryGB     float64  `json:"idle_memory_gb"`         // Requests of idle running pods
+	IdleDays         int      `json:"idle_days"`              // Shortest idle duration of the idle running pods, -1 if any was only sampled now
+	IdleReasons      []string `json:"idle_reasons,omitempty"` // "pod: why it is idle"
 	SpotEligiblePods int      `json:"spot_eligible_pods"`
+	OnSpotPods       int      `json:"on_spot_pods"`
 	WasteScore       float64  `json:"waste_score"` // 0-100
-	Flags            []string `json:"flags"`       // "IDLE-15d", "SPOT-OK", "OVER-PROV"
+	Flags            []string `json:"flags"`       // "IDLE-15d", "IDLE-NOW", "IDLE-PODS-2/5", "SPOT-OK", "OVER-PROV"
 }
 
 // WeightedShare returns the weighted average of CPU and Memory percentage as a fraction (0.0-1.0)
@@ -46,6 +105,98 @@ func (n NamespaceResourceUsage) WeightedShare() float64 {
 	return (n.CPUPercent + n.MemoryPercent) / 2.0 / 100.0
 }
 
+// NodeResourceUsage represents a node's allocatable capacity and the requests scheduled on it
+type NodeResourceUsage struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"-"` // Used to look up pricing
+	Spot   bool              `json:"spot"`
+
+	CPUCores          float64 `json:"cpu_cores"` // Allocatable
+	MemoryGB          float64 `json:"memory_gb"` // Allocatable
+	CPUCoresRequested float64 `json:"cpu_cores_requested"`
+	MemoryGBRequested float64 `json:"memory_gb_requested"`
+
+	// Requests per namespace (and per showback group) of the pods running on this node
+	NamespaceRequests map[string]ResourceCapacity `json:"namespace_requests"`
+	GroupRequests     map[string]ResourceCapacity `json:"-"`
+}
+
+// GroupResourceUsage aggregates the pods and billable objects sharing a label or annotation value
+type GroupResourceUsage struct {
+	Name       string   `json:"name"` // Label/annotation value, or "unallocated"
+	Namespaces []string `json:"namespaces"`
+	PodCount   int      `json:"pod_count"`
+
+	CPUCoresRequested float64 `json:"cpu_cores_requested"`
+	MemoryGBRequested float64 `json:"memory_gb_requested"`
+	CPUCoresUsed      float64 `json:"cpu_cores_used"`
+	MemoryGBUsed      float64 `json:"memory_gb_used"`
+
+	// Waste: requests of idle pods, and requests above actual usage (metrics-server only)
+	IdlePods         int     `json:"idle_pods"`
+	IdleCPUCores     float64 `json:"idle_cpu_cores"`
+	IdleMemoryGB     float64 `json:"idle_memory_gb"`
+	UnusedCPUCores   float64 `json:"unused_cpu_cores"`
+	UnusedMemoryGB   float64 `json:"unused_memory_gb"`
+	MetricsAvailable bool    `json:"metrics_available"`
+
+	// Billable storage and networking
+	StorageGB     map[string]float64 `json:"storage_gb"` // Bound/Released GB per StorageClass
+	LoadBalancers int                `json:"load_balancers"`
+	Ingresses     int                `json:"ingresses"`
+}
+
+// WorkloadResourceUsage represents requests, limits and actual usage for a single workload
+type WorkloadResourceUsage struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	PodCount  int    `json:"pod_count"`
+
+	CPUCoresRequested float64 `json:"cpu_cores_requested"`
+	CPUCoresLimit     float64 `json:"cpu_cores_limit"`
+	CPUCoresUsed      float64 `json:"cpu_cores_used"`
+	MemoryGBRequested float64 `json:"memory_gb_requested"`
+	MemoryGBLimit     float64 `json:"memory_gb_limit"`
+	MemoryGBUsed      float64 `json:"memory_gb_used"`
+	CPUEfficiency     float64 `json:"cpu_efficiency"`    // Usage as % of requests
+	MemoryEfficiency  float64 `json:"memory_efficiency"` // Usage as % of requests
+}
+
+// ContainerRightsizing is a history-based request/limit recommendation for one container of a workload
+type ContainerRightsizing struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Workload  string `json:"workload"`
+	Container string `json:"container"`
+	Replicas  int    `json:"replicas"`
+
+	// Observed usage over the history window (CPU in cores, memory in GB)
+	CPUP50    float64 `json:"cpu_p50"`
+	CPUP95    float64 `json:"cpu_p95"`
+	CPUMax    float64 `json:"cpu_max"`
+	MemoryP50 float64 `json:"memory_p50"`
+	MemoryP95 float64 `json:"memory_p95"`
+	MemoryMax float64 `json:"memory_max"`
+
+	Current     ContainerResources `json:"current"`
+	Recommended ContainerResources `json:"recommended"`
+
+	// Freed requests across all replicas (negative when the container needs more)
+	CPUCoresSaved float64 `json:"cpu_cores_saved"`
+	MemoryGBSaved float64 `json:"memory_gb_saved"`
+
+	Diff string `json:"diff"` // YAML diff of the container's resources block
+}
+
+// ContainerResources holds requests and limits for one container (CPU in cores, memory in GB; 0 = unset)
+type ContainerResources struct {
+	CPURequest    float64 `json:"cpu_request"`
+	CPULimit      float64 `json:"cpu_limit"`
+	MemoryRequest float64 `json:"memory_request"`
+	MemoryLimit   float64 `json:"memory_limit"`
+}
+
 // ResourceCapacity represents CPU and memory capacity
 type ResourceCapacity struct {
 	CPU    float64 `json:"cpu"`    // CPU cores
@@ -62,6 +213,24 @@ type Optimization struct {
 	Impact      string `json:"impact"`
 }
 
+// WorkloadPatch is a ready-to-apply change for one workload (optimize --emit-patches)
+type WorkloadPatch struct {
+	Type        string `json:"type"` // "spot", "rightsizing", "hpa"
+	Namespace   string `json:"namespace"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	File        string `json:"file"`    // Relative to the output directory
+	Command     string `json:"command"` // kubectl command applying the file
+
+	// Expected savings; MonthlySavings is 0 when no cost input was given
+	CPUCoresSaved  float64 `json:"cpu_cores_saved"`
+	MemoryGBSaved  float64 `json:"memory_gb_saved"`
+	MonthlySavings float64 `json:"monthly_savings"`
+
+	Content []byte `json:"-"` // YAML written to File
+}
+
 // CostEstimate represents estimated costs (Phase 2)
 type CostEstimate struct {
 	TotalClusterCost      float64                `json:"total_cluster_cost"`
@@ -72,6 +241,105 @@ type CostEstimate struct {
 	Confidence            string                 `json:"confidence"`
 	Assumptions           []string               `json:"assumptions"`
 	Disclaimers           []string               `json:"disclaimers"`
+
+	// Compute vs storage and networking (TotalClusterCost is their sum)
+	ComputeCost       float64           `json:"compute_cost"`
+	StorageCost       float64           `json:"storage_cost"`
+	UnusedStorageCost float64           `json:"unused_storage_cost"` // Released volumes, included in StorageCost
+	NetworkCost       float64           `json:"network_cost"`
+	StorageCosts      []StorageCostInfo `json:"storage_costs,omitempty"`
+	NetworkCosts      []NetworkCostInfo `json:"network_costs,omitempty"`
+
+	// Budget overruns and cost jumps vs the previous stored run
+	BudgetAlerts []BudgetAlert `json:"budget_alerts,omitempty"`
+
+	// Showback by label/annotation (only with --group-by)
+	GroupBy    string          `json:"group_by,omitempty"`
+	GroupCosts []GroupCostInfo `json:"group_costs,omitempty"`
+
+	// Node-priced allocation (Method "node_pricing" only)
+	NodeCosts        []NodeCostInfo `json:"node_costs,omitempty"`
+	AllocatedCost    float64        `json:"allocated_cost,omitempty"`
+	IdleCapacityCost float64        `json:"idle_capacity_cost,omitempty"`
+}
+
+// BudgetAlert flags a namespace or group over its budget or jumping in cost
+type BudgetAlert struct {
+	Type          string  `json:"type"`  // "over_budget", "cost_jump"
+	Scope         string  `json:"scope"` // "namespace" or the group-by key, e.g. "label:team"
+	Name          string  `json:"name"`
+	Severity      string  `json:"severity"` // over_budget: high, cost_jump: medium
+	Cost          float64 `json:"cost"`
+	Budget        float64 `json:"budget,omitempty"`
+	PreviousCost  float64 `json:"previous_cost,omitempty"`
+	PreviousRun   string  `json:"previous_run,omitempty"` // Date of the baseline run
+	Interval      string  `json:"interval,omitempty"`     // Time since the baseline run, e.g. "7 days"
+	ChangePercent float64 `json:"change_percent"`         // vs budget or previous cost
+	Message       string  `json:"message"`
+}
+
+// CostSnapshot is a stored costs run, the baseline for cost jump alerts
+type CostSnapshot struct {
+	Timestamp  time.Time          `json:"timestamp"`
+	Method     string             `json:"method"`
+	TotalCost  float64            `json:"total_cost"`
+	Namespaces map[string]float64 `json:"namespaces"`
+	GroupBy    string             `json:"group_by,omitempty"`
+	Groups     map[string]float64 `json:"groups,omitempty"`
+}
+
+// GroupCostInfo represents the showback cost of one label/annotation value
+type GroupCostInfo struct {
+	Name       string   `json:"name"`
+	Namespaces []string `json:"namespaces"`
+	PodCount   int      `json:"pod_count"`
+
+	CPUCoresRequested float64 `json:"cpu_cores_requested"`
+	MemoryGBRequested float64 `json:"memory_gb_requested"`
+
+	ComputeCost float64 `json:"compute_cost"`
+	StorageCost float64 `json:"storage_cost"`
+	NetworkCost float64 `json:"network_cost"`
+	TotalCost   float64 `json:"total_cost"`
+	Share       float64 `json:"share"` // Fraction of the cluster total (0.0-1.0)
+
+	IdlePods  int     `json:"idle_pods"`
+	WasteCost float64 `json:"waste_cost"` // Compute cost of idle or unused requests
+
+	SecurityFindings map[string]int `json:"security_findings"` // By severity
+}
+
+// StorageCostInfo represents the monthly cost of one volume
+type StorageCostInfo struct {
+	Namespace       string  `json:"namespace"`
+	Name            string  `json:"name"`   // PVC name (former claim for Released PVs)
+	Volume          string  `json:"volume"` // PV name
+	StorageClass    string  `json:"storage_class"`
+	Status          string  `json:"status"` // Bound, Pending, Lost, Released
+	SizeGB          float64 `json:"size_gb"`
+	PricePerGBMonth float64 `json:"price_per_gb_month"`
+	MonthlyCost     float64 `json:"monthly_cost"` // 0 for unbound claims (nothing provisioned)
+}
+
+// NetworkCostInfo represents the fixed monthly cost of a LoadBalancer Service or Ingress
+type NetworkCostInfo struct {
+	Namespace   string  `json:"namespace"`
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"` // "LoadBalancer" or "Ingress"
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// NodeCostInfo represents the priced cost of a node, split into allocated and idle capacity
+type NodeCostInfo struct {
+	Name         string  `json:"name"`
+	InstanceType string  `json:"instance_type"`
+	CapacityType string  `json:"capacity_type"` // "spot" or "on-demand"
+	Zone         string  `json:"zone"`
+	HourlyPrice  float64 `json:"hourly_price"`
+	MonthlyCost  float64 `json:"monthly_cost"`
+	Allocated    float64 `json:"allocated"` // Cost of requested CPU/memory
+	Idle         float64 `json:"idle"`      // Cost of unrequested capacity
+	Priced       bool    `json:"priced"`    // False when no pricing entry matched
 }
 
 // NamespaceCostInfo represents cost information for a namespace
@@ -81,6 +349,11 @@ type NamespaceCostInfo struct {
 	CPUShare      float64   `json:"cpu_share"`
 	MemoryShare   float64   `json:"memory_share"`
 	WeightedShare float64   `json:"weighted_share"`
+
+	// Breakdown of EstimatedCost.Best
+	ComputeCost float64 `json:"compute_cost"`
+	StorageCost float64 `json:"storage_cost"`
+	NetworkCost float64 `json:"network_cost"`
 }
 
 // CostRange represents a cost estimate range
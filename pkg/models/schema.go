@@ -0,0 +1,181 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(Duration(0))
+)
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of a command's JSON output: the
+// Envelope with data shaped like the given value. Property names and required fields
+// follow the encoding/json tags, so the schema cannot drift from what is printed.
+func JSONSchema(command string, data interface{}) map[string]interface{} {
+	b := &schemaBuilder{
+		defs:  make(map[string]interface{}),
+		names: make(map[reflect.Type]string),
+	}
+
+	envelope := b.structSchema(reflect.TypeOf(Envelope{}))
+	properties := envelope["properties"].(map[string]interface{})
+	major, _, _ := strings.Cut(SchemaVersion, ".")
+	properties["schema_version"] = map[string]interface{}{
+		"type":        "string",
+		"pattern":     "^" + major + `\.`,
+		"description": "Any " + major + ".x version has this shape, possibly with fewer fields",
+	}
+	properties["command"] = map[string]interface{}{"const": command}
+	properties["data"] = b.schemaFor(reflect.TypeOf(data))
+
+	envelope["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	envelope["title"] = fmt.Sprintf("opscart-scan %s output", command)
+	if len(b.defs) > 0 {
+		envelope["$defs"] = b.defs
+	}
+	return envelope
+}
+
+// schemaBuilder collects named structs under $defs so shared types are described once
+type schemaBuilder struct {
+	defs  map[string]interface{}
+	names map[reflect.Type]string
+}
+
+// schemaFor describes a Go type as encoding/json writes it
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "description": "Seconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return map[string]interface{}{"anyOf": []interface{}{b.schemaFor(t.Elem()), map[string]interface{}{"type": "null"}}}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		// nil slices are written as null
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + b.define(t)}
+	default:
+		// interface{} holds anything
+		return map[string]interface{}{}
+	}
+}
+
+// define adds a named struct to $defs once and returns its key
+func (b *schemaBuilder) define(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for _, taken := range b.names {
+		if taken == name {
+			// Same name in another package, e.g. report.SecurityFinding
+			name = t.String()
+			break
+		}
+	}
+	b.names[t] = name
+	b.defs[name] = nil // Reserve the key so recursive types terminate
+	b.defs[name] = b.structSchema(t)
+	return name
+}
+
+// schemaField is one JSON property of a struct, with the embedding depth it came from
+type schemaField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+	depth    int
+}
+
+// structSchema describes a struct's JSON properties; omitempty fields, and fields tagged
+// `schema:"optional"` that only some runs write, are optional
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	// Like encoding/json, a field at a shallower embedding depth hides deeper ones
+	fields := make(map[string]schemaField)
+	var order []string
+	collectFields(t, 0, func(f schemaField) {
+		existing, ok := fields[f.name]
+		if !ok {
+			order = append(order, f.name)
+		}
+		if !ok || f.depth < existing.depth {
+			fields[f.name] = f
+		}
+	})
+
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, name := range order {
+		f := fields[name]
+		properties[name] = b.schemaFor(f.typ)
+		if !f.optional {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// collectFields walks a struct's exported fields, flattening untagged embedded structs
+func collectFields(t reflect.Type, depth int, add func(schemaField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectFields(embedded, depth+1, add)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		add(schemaField{
+			name:     name,
+			typ:      f.Type,
+			optional: strings.Contains(","+options+",", ",omitempty,") || f.Tag.Get("schema") == "optional",
+			depth:    depth,
+		})
+	}
+}
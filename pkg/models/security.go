@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // SecurityAudit represents a complete security audit of the cluster
 type SecurityAudit struct {
 	TotalPodsAudited int             `json:"total_pods_audited"`
@@ -7,6 +9,14 @@ type SecurityAudit struct {
 	Risks            SecurityRisks   `json:"risks"`
 	Issues           []SecurityIssue `json:"issues"`
 	PriorityActions  []string        `json:"priority_actions"`
+
+	// Accepted-risk exceptions: suppressed findings are left out of Risks, Issues and the CIS score.
+	// Findings whose exception has expired are counted again and also listed here.
+	Suppressed          []SuppressedIssue `json:"suppressed,omitempty"`
+	ExpiredSuppressions []SuppressedIssue `json:"expired_suppressions,omitempty"`
+
+	// Set when compared against a saved baseline: Issues then holds only new findings
+	Baseline *BaselineComparison `json:"baseline,omitempty"`
 }
 
 // SecurityRisks contains counts of different security risks
@@ -23,8 +33,8 @@ type SecurityRisks struct {
 	AddedCapabilities      int `json:"added_capabilities"`
 	PrivilegeEscalation    int `json:"privilege_escalation"`
 	WritableFilesystem     int `json:"writable_filesystem"`
-	MissingNetworkPolicies int
-	MissingLimits          int
+	MissingNetworkPolicies int `json:"missing_network_policies"`
+	MissingLimits          int `json:"missing_limits"`
 }
 
 // SecurityIssue represents a single security issue
@@ -36,43 +46,66 @@ type SecurityIssue struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Remediation string `json:"remediation"`
+	Fingerprint string `json:"fingerprint"` // Stable across pod restarts and rollouts
+}
+
+// SuppressedIssue is a finding covered by an accepted-risk exception
+type SuppressedIssue struct {
+	SecurityIssue
+	Reason  string `json:"reason"`
+	Owner   string `json:"owner"`
+	Expires string `json:"expires"` // YYYY-MM-DD
+}
+
+// SecurityBaseline is a saved set of findings that later audits report changes against
+type SecurityBaseline struct {
+	Cluster   string          `json:"cluster"`
+	CreatedAt time.Time       `json:"created_at"`
+	Findings  []SecurityIssue `json:"findings"`
+}
+
+// BaselineComparison is how an audit differs from a saved baseline
+type BaselineComparison struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Existing  int             `json:"existing"` // Findings already in the baseline, left out of the audit
+	Fixed     []SecurityIssue `json:"fixed"`    // Baseline findings no longer present, one per fingerprint
 }
 
 // EnhancedClusterSnapshot represents detailed cluster state
 type EnhancedClusterSnapshot struct {
 	ClusterSnapshot // Embed the base snapshot
 
-	// Extended information
-	Services        []ServiceDetail     `json:"services"`
-	Ingresses       []IngressDetail     `json:"ingresses"`
-	PVCDetails      []PVCDetail         `json:"pvc_details"`
-	ConfigMaps      []ResourceCount     `json:"configmaps"`
-	Secrets         []ResourceCount     `json:"secrets"`
-	NetworkPolicies []NetworkPolicyInfo `json:"network_policies"`
+	// Extended information, only in snapshot --enhanced output
+	Services        []ServiceDetail     `json:"services" schema:"optional"`
+	Ingresses       []IngressDetail     `json:"ingresses" schema:"optional"`
+	PVCDetails      []PVCDetail         `json:"pvc_details" schema:"optional"`
+	ConfigMaps      []ResourceCount     `json:"configmaps" schema:"optional"`
+	Secrets         []ResourceCount     `json:"secrets" schema:"optional"`
+	NetworkPolicies []NetworkPolicyInfo `json:"network_policies" schema:"optional"`
 }
 
 // ServiceDetail represents detailed service information
 type ServiceDetail struct {
 	ServiceInfo                   // Embed base service info
 	Endpoints   int               `json:"endpoints"`
-	Age         string            `json:"age"`
+	Age         Duration          `json:"age_seconds"`
 	Selector    map[string]string `json:"selector,omitempty"`
 }
 
 // IngressDetail represents detailed ingress information
 type IngressDetail struct {
-	IngressInfo         // Embed base ingress info
-	IngressClass string `json:"ingress_class,omitempty"`
-	Age          string `json:"age"`
-	Rules        int    `json:"rules"`
+	IngressInfo           // Embed base ingress info
+	IngressClass string   `json:"ingress_class,omitempty"`
+	Age          Duration `json:"age_seconds"`
+	Rules        int      `json:"rules"`
 }
 
 // PVCDetail represents detailed PVC information
 type PVCDetail struct {
-	PVCInfo           // Embed base PVC info
-	Age        string `json:"age"`
-	AccessMode string `json:"access_mode"`
-	UsedBy     string `json:"used_by,omitempty"` // Pod using this PVC
+	PVCInfo             // Embed base PVC info
+	Age        Duration `json:"age_seconds"`
+	AccessMode string   `json:"access_mode"`
+	UsedBy     string   `json:"used_by,omitempty"` // Pod using this PVC
 }
 
 // ResourceCount represents count of resources in a namespace
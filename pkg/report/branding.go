@@ -0,0 +1,109 @@
+package report
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// hexColor accepts #rgb and #rrggbb, the only colors the branding styles interpolate
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Branding puts a company's name, logo and colors on HTML reports
+type Branding struct {
+	CompanyName    string
+	Logo           string // Image URL, or a local file embedded so the report stays self-contained
+	PrimaryColor   string // Header and score panel color, e.g. #0b5fff
+	SecondaryColor string // Gradient end color (default PrimaryColor)
+
+	logoSrc template.URL
+}
+
+// LogoSrc is the logo as an img src: the URL, or a data URI for a local file
+func (b Branding) LogoSrc() template.URL {
+	return b.logoSrc
+}
+
+// GradientEnd is the second gradient color, falling back to the primary color
+func (b Branding) GradientEnd() string {
+	if b.SecondaryColor != "" {
+		return b.SecondaryColor
+	}
+	return b.PrimaryColor
+}
+
+// SetBranding validates the branding and loads a local logo file
+func (g *Generator) SetBranding(b Branding) error {
+	for _, color := range []string{b.PrimaryColor, b.SecondaryColor} {
+		if color != "" && !hexColor.MatchString(color) {
+			return fmt.Errorf("invalid branding color %q: use #rgb or #rrggbb", color)
+		}
+	}
+
+	switch {
+	case b.Logo == "":
+	case strings.HasPrefix(b.Logo, "http://"), strings.HasPrefix(b.Logo, "https://"):
+		b.logoSrc = template.URL(b.Logo)
+	default:
+		content, err := os.ReadFile(b.Logo)
+		if err != nil {
+			return fmt.Errorf("reading logo: %w", err)
+		}
+		// SVG sniffs as text/xml, so trust the extension for it
+		mimeType := http.DetectContentType(content)
+		if strings.EqualFold(filepath.Ext(b.Logo), ".svg") {
+			mimeType = "image/svg+xml"
+		}
+		if !strings.HasPrefix(mimeType, "image/") {
+			return fmt.Errorf("logo %s is not an image (%s)", b.Logo, mimeType)
+		}
+		b.logoSrc = template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content))
+	}
+
+	g.branding = b
+	return nil
+}
+
+// LoadTemplate replaces the built-in HTML layout of cluster and security reports with a
+// user-supplied html/template file. It receives ReportData and the same helpers as the
+// built-in templates, and can reuse the "styles", "cluster-sections", "branding-styles"
+// and "branding-header" blocks.
+func (g *Generator) LoadTemplate(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(g.htmlFuncs()).Parse(htmlStyles + clusterSections + brandingTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse built-in blocks: %w", err)
+	}
+	if _, err := tmpl.Parse(string(content)); err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+
+	g.custom = tmpl
+	return nil
+}
+
+// brandingTemplate overrides the default purple theme and adds the logo and company name
+// to report headers. Both blocks render nothing without branding.
+const brandingTemplate = `{{define "branding-styles"}}{{with branding}}{{if .PrimaryColor}}
+    <style>
+        .header, .cost-box, .score-card { background: linear-gradient(135deg, {{.PrimaryColor}} 0%, {{.GradientEnd}} 100%); }
+        .button { background: {{.PrimaryColor}}; }
+        .metric-card { border-left-color: {{.PrimaryColor}}; }
+        .cluster-detail { border-top-color: {{.PrimaryColor}}; }
+        .back-link { color: {{.PrimaryColor}}; }
+    </style>{{end}}{{end}}{{end}}
+{{define "branding-header"}}{{with branding}}{{if or .LogoSrc .CompanyName}}
+            <div style="display: flex; align-items: center; gap: 12px; margin-bottom: 15px;">
+                {{if .LogoSrc}}<img src="{{.LogoSrc}}" alt="{{.CompanyName}}" style="max-height: 48px; max-width: 200px;">{{end}}
+                {{if .CompanyName}}<span style="font-size: 18px; font-weight: 600;">{{.CompanyName}}</span>{{end}}
+            </div>{{end}}{{end}}{{end}}
+`
@@ -1,223 +1,358 @@
 package report
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/analyzer"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 )
 
-// BuildOptions holds optional scan results for report building
+// maxIssueDetails caps the resources listed under one issue
+const maxIssueDetails = 5
+
+// BuildOptions holds the scan results a report is built from. Nil sections are left
+// out of the report and of the overall score, which re-weights the sections present.
 type BuildOptions struct {
-	// Add your actual types here as you integrate
-	// For now, using interface{} as placeholder
-	SecurityAudit    interface{}
-	ResourceAnalysis interface{}
-	EmergencyIssues  interface{}
-	MonthlyCost      float64
+	SecurityAudit    *models.SecurityAudit
+	CISResult        *analyzer.CISResult // Calculated from SecurityAudit when nil
+	ResourceAnalysis *models.ClusterResourceAnalysis
+	CostEstimate     *models.CostEstimate // Needs ResourceAnalysis
+	EmergencyIssues  []models.EmergencyIssue
 }
 
-// BuildFromScans creates ReportData from multiple scan results
-// This is a simplified version - you'll wire in your actual analyzer types
-func BuildFromScans(clusterName string, opts *BuildOptions) *ReportData {
+// securityCheck maps a SecurityRisks counter to a report issue
+type securityCheck struct {
+	issueType   string
+	severity    string
+	title       string // Format taking the count
+	description string
+	count       func(models.SecurityRisks) int
+}
+
+// securityChecks in report order, critical first
+var securityChecks = []securityCheck{
+	{"privileged_container", "critical", "🔴 %d privileged containers detected",
+		"Containers with elevated privileges can escape containment and compromise the host",
+		func(r models.SecurityRisks) int { return r.PrivilegedContainers }},
+	{"host_pid", "critical", "🔴 %d containers sharing host PID namespace",
+		"Host PID namespace sharing allows container processes to see all host processes",
+		func(r models.SecurityRisks) int { return r.HostPID }},
+	{"host_path_volume", "critical", "🔴 %d pods mounting host paths",
+		"Host path volumes provide direct access to host filesystem",
+		func(r models.SecurityRisks) int { return r.HostPathVolumes }},
+	{"host_ipc", "warning", "🟡 %d containers sharing host IPC namespace",
+		"Host IPC namespace sharing can leak sensitive information",
+		func(r models.SecurityRisks) int { return r.HostIPC }},
+	{"running_as_root", "warning", "🟡 %d containers running as root",
+		"Running as root increases attack surface",
+		func(r models.SecurityRisks) int { return r.RunningAsRoot }},
+	{"missing_resource_limits", "warning", "🟡 %d containers missing resource limits",
+		"Missing resource limits can lead to resource exhaustion",
+		func(r models.SecurityRisks) int { return r.MissingResourceLimits }},
+	{"host_network", "warning", "🟡 %d containers using host network",
+		"Host network access bypasses network policies",
+		func(r models.SecurityRisks) int { return r.HostNetwork }},
+	{"privilege_escalation", "warning", "🟡 %d containers allowing privilege escalation",
+		"Privilege escalation can lead to container breakout",
+		func(r models.SecurityRisks) int { return r.PrivilegeEscalation }},
+	{"added_capabilities", "warning", "🟡 %d containers with added capabilities",
+		"Unnecessary capabilities increase attack surface",
+		func(r models.SecurityRisks) int { return r.AddedCapabilities }},
+	{"default_service_account", "warning", "🟡 %d pods using default service account",
+		"Default service account may have excessive permissions",
+		func(r models.SecurityRisks) int { return r.DefaultServiceAccount }},
+}
+
+// Build maps scan results into ReportData and calculates the scores
+func Build(clusterName string, opts BuildOptions) *ReportData {
 	data := &ReportData{
 		ClusterName: clusterName,
 		GeneratedAt: time.Now(),
 	}
 
-	// Placeholder scores - replace with actual calculations
-	data.SecurityScore = 67
-	data.ResourceScore = 85
-	data.CostScore = 45
+	// Emergencies lead the critical issues - they are outages, not hardening
+	addEmergencyIssues(data, opts.EmergencyIssues)
+	if opts.SecurityAudit != nil {
+		cis := opts.CISResult
+		if cis == nil {
+			result := analyzer.CalculateCISScore(opts.SecurityAudit)
+			cis = &result
+		}
+		addSecurity(data, opts.SecurityAudit, cis)
+	}
+	if opts.ResourceAnalysis != nil {
+		addResources(data, opts.ResourceAnalysis, opts.CostEstimate)
+	}
 
-	// Add sample data (replace with actual scan results)
-	data.CISScore = 67
-	data.ControlsPassed = 4
-	data.ControlsFailed = 3
+	data.OverallScore = overallScore(data, opts.SecurityAudit != nil, opts.ResourceAnalysis != nil)
+	return data
+}
 
-	// Sample critical issues
-	data.CriticalIssues = []IssueItem{
-		{
-			Severity:    "critical",
-			Title:       "🔴 3 pods in CrashLoopBackOff",
-			Description: "Pods are restarting continuously",
-			Count:       3,
-			Details:     []string{"backend-api", "worker-queue", "metrics-exporter"},
-		},
+// overallScore is CalculateOverallScore over the scanned sections only, their weights
+// scaled to 100% (0 when neither security nor resources were scanned)
+func overallScore(data *ReportData, security, resources bool) int {
+	total, weight := 0, 0
+	if security {
+		total += data.SecurityScore * securityWeight
+		weight += securityWeight
+	}
+	// The cost score comes from the resource analysis, with or without a cost estimate
+	if resources {
+		total += data.ResourceScore*resourceWeight + data.CostScore*costWeight
+		weight += resourceWeight + costWeight
+	}
+	if weight == 0 {
+		return 0
 	}
+	return total / weight
+}
+
+// addSecurity fills the security score, issues and per-control findings
+func addSecurity(data *ReportData, audit *models.SecurityAudit, cis *analyzer.CISResult) {
+	data.CISScore = cis.Score
+	data.SecurityScore = cis.Score
+	data.ControlsPassed = cis.PassedChecks
+	data.ControlsFailed = cis.FailedChecks
+	data.PodCount = audit.TotalPodsAudited
+	data.IssueCount = len(audit.Issues)
+	data.SecurityIssues = audit.Issues
+	data.CISControls = cis.Controls
+	data.Suppressed = audit.Suppressed
+	data.ExpiredSuppressions = audit.ExpiredSuppressions
+	data.Baseline = audit.Baseline
 
-	data.WarningIssues = []IssueItem{
-		{
+	for _, check := range securityChecks {
+		count := check.count(audit.Risks)
+		if count == 0 {
+			continue
+		}
+		item := IssueItem{
+			Severity:    check.severity,
+			Title:       fmt.Sprintf(check.title, count),
+			Description: check.description,
+			Count:       count,
+			Details:     affectedResources(audit.Issues, check.issueType, maxIssueDetails),
+		}
+		if check.severity == "critical" {
+			data.CriticalIssues = append(data.CriticalIssues, item)
+		} else {
+			data.WarningIssues = append(data.WarningIssues, item)
+		}
+	}
+
+	if expired := len(audit.ExpiredSuppressions); expired > 0 {
+		item := IssueItem{
 			Severity:    "warning",
-			Title:       "🟡 31 containers running as root",
-			Description: "Running as root increases attack surface",
-			Count:       31,
-		},
-	}
-
-	// Sample resource data
-	data.TotalCPU = 24.0
-	data.TotalMemory = 29.1
-	data.UsedCPU = 10.2
-	data.UsedMemory = 13.0
-	data.PodCount = 89
-	data.NamespaceCount = 12
-
-	// Sample cost data
-	if opts.MonthlyCost > 0 {
-		data.MonthlyCost = opts.MonthlyCost
-		data.PotentialSavings = SavingsRange{
-			Min: opts.MonthlyCost * 0.24,
-			Max: opts.MonthlyCost * 0.36,
-		}
-
-		data.CostBreakdown = []CostItem{
-			{
-				Name:    "Delete idle namespaces",
-				Impact:  "High",
-				Savings: 850,
-				Action:  "5 namespaces identified",
-			},
-			{
-				Name:    "Move to spot instances",
-				Impact:  "Medium",
-				Savings: 350,
-				Action:  "38 pods eligible",
-			},
-			{
-				Name:    "Right-size pods",
-				Impact:  "Low",
-				Savings: 200,
-				Action:  "12 over-provisioned pods",
-			},
-		}
-	}
-
-	// Sample namespace data
-	data.Namespaces = []NamespaceItem{
-		{
-			Name:       "cost-test",
-			CPUPercent: 28.3,
-			MemPercent: 30.9,
-			PodCount:   18,
-			Cost:       1750,
-			Flags:      []string{"SPOT-OK"},
-		},
-		{
-			Name:       "prod-api",
-			CPUPercent: 5.0,
-			MemPercent: 4.3,
-			PodCount:   3,
-			Cost:       750,
-			Flags:      []string{"SPOT-OK"},
-		},
-		{
-			Name:       "staging",
-			CPUPercent: 1.3,
-			MemPercent: 1.3,
-			PodCount:   3,
-			Cost:       300,
-			Flags:      []string{"IDLE-21d"},
-		},
-	}
-
-	// Calculate overall score
-	data.OverallScore = CalculateOverallScore(data.SecurityScore, data.ResourceScore, data.CostScore)
+			Title:       fmt.Sprintf("⏰ %d findings past their exception expiry", expired),
+			Description: "Accepted-risk exceptions have expired and these findings count again - fix them or renew the exceptions",
+			Count:       expired,
+		}
+		for i, issue := range audit.ExpiredSuppressions {
+			if i == maxIssueDetails {
+				item.Details = append(item.Details, fmt.Sprintf("... and %d more", expired-maxIssueDetails))
+				break
+			}
+			item.Details = append(item.Details, fmt.Sprintf("%s: %s in namespace %s (%s, expired %s)",
+				issue.Type, issue.Name, issue.Namespace, issue.Owner, issue.Expires))
+		}
+		data.WarningIssues = append(data.WarningIssues, item)
+	}
 
-	return data
+	for _, control := range cis.Controls {
+		finding := SecurityFinding{
+			Control: control.ID + " " + control.Description,
+			Status:  "passed",
+		}
+		if !control.Passed {
+			finding.Status = "failed"
+			finding.Severity = "warning"
+			for _, issue := range audit.Issues {
+				if control.IssueType == "" || issue.Type != control.IssueType {
+					continue
+				}
+				finding.Count++
+				if issue.Severity == "critical" {
+					finding.Severity = "critical"
+				}
+				if finding.Remediation == "" {
+					finding.Remediation = issue.Remediation
+				}
+			}
+			finding.Resources = affectedResources(audit.Issues, control.IssueType, maxIssueDetails)
+		}
+		data.SecurityFindings = append(data.SecurityFindings, finding)
+	}
 }
 
-// BuildFromRealScans - TEMPLATE for when you wire in actual types
-// Uncomment and modify this once you integrate with your analyzer package
-/*
-func BuildFromRealScans(clusterName string, opts *BuildOptions) *ReportData {
-	data := &ReportData{
-		ClusterName: clusterName,
-		GeneratedAt: time.Now(),
+// addResources fills the resource and cost sections and their scores (costs may be nil)
+func addResources(data *ReportData, resources *models.ClusterResourceAnalysis, costs *models.CostEstimate) {
+	data.TotalCPU = resources.TotalCPUCores
+	data.TotalMemory = resources.TotalMemoryGB
+	data.NamespaceCount = len(resources.Namespaces)
+
+	// Actual usage when metrics-server is available, requests otherwise
+	usedPercent := (resources.CPUUtilization + resources.MemoryUtilization) / 2
+	data.UsedCPU = resources.TotalCPURequested
+	data.UsedMemory = resources.TotalMemoryRequested
+	if resources.MetricsAvailable {
+		usedPercent = (resources.CPUUsagePercent + resources.MemoryUsagePercent) / 2
+		data.UsedCPU = resources.TotalCPUUsed
+		data.UsedMemory = resources.TotalMemoryUsed
 	}
+	data.ResourceScore = CalculateResourceScore(usedPercent)
 
-	// Security data
-	if secAudit, ok := opts.SecurityAudit.(*analyzer.SecurityAudit); ok {
-		data.CISScore = secAudit.CISScore
-		data.SecurityScore = secAudit.CISScore
-		data.ControlsPassed = secAudit.ControlsPassed
-		data.ControlsFailed = secAudit.ControlsFailed
-
-		if secAudit.PrivilegedContainers > 0 {
-			data.CriticalIssues = append(data.CriticalIssues, IssueItem{
-				Severity:    "critical",
-				Title:       fmt.Sprintf("🔴 %d privileged containers", secAudit.PrivilegedContainers),
-				Description: "Containers with elevated privileges detected",
-				Count:       secAudit.PrivilegedContainers,
-			})
-		}
-
-		if secAudit.RootContainers > 0 {
-			data.WarningIssues = append(data.WarningIssues, IssueItem{
-				Severity:    "warning",
-				Title:       fmt.Sprintf("🟡 %d containers running as root", secAudit.RootContainers),
-				Description: "Running as root increases attack surface",
-				Count:       secAudit.RootContainers,
-			})
-		}
-	}
-
-	// Resource data
-	if resAnalysis, ok := opts.ResourceAnalysis.(*analyzer.ResourceAnalysis); ok {
-		data.TotalCPU = resAnalysis.TotalCPU
-		data.TotalMemory = resAnalysis.TotalMemory
-		data.UsedCPU = resAnalysis.UsedCPU
-		data.UsedMemory = resAnalysis.UsedMemory
-		data.PodCount = resAnalysis.TotalPods
-		data.NamespaceCount = len(resAnalysis.Namespaces)
-
-		usedPercent := (data.UsedCPU / data.TotalCPU) * 100
-		data.ResourceScore = CalculateResourceScore(usedPercent)
-
-		// Namespace breakdown
-		for _, ns := range resAnalysis.Namespaces {
-			item := NamespaceItem{
-				Name:       ns.Name,
-				CPUPercent: (ns.CPURequested / data.TotalCPU) * 100,
-				MemPercent: (ns.MemoryRequested / data.TotalMemory) * 100,
-				PodCount:   ns.PodCount,
-				Flags:      []string{},
-			}
+	namespaceCosts := make(map[string]float64)
+	if costs != nil {
+		for _, ns := range costs.NamespaceCosts {
+			namespaceCosts[ns.Name] = ns.EstimatedCost.Best
+		}
+	}
 
-			if ns.IdleDays > 21 {
-				item.Flags = append(item.Flags, fmt.Sprintf("IDLE-%dd", ns.IdleDays))
-			}
-			if ns.SpotEligible {
-				item.Flags = append(item.Flags, "SPOT-OK")
-			}
+	podCount, idlePods, spotPods := 0, 0, 0
+	for _, ns := range resources.Namespaces {
+		podCount += ns.PodCount
+		idlePods += ns.IdlePods
+		spotPods += ns.SpotEligiblePods
+		data.Namespaces = append(data.Namespaces, NamespaceItem{
+			Name:       ns.Name,
+			CPUPercent: ns.CPUPercent,
+			MemPercent: ns.MemoryPercent,
+			PodCount:   ns.PodCount,
+			Cost:       namespaceCosts[ns.Name],
+			Flags:      ns.Flags,
+		})
+	}
+	data.PodCount = podCount
 
-			if opts.MonthlyCost > 0 {
-				cpuCost := opts.MonthlyCost / data.TotalCPU
-				item.Cost = ns.CPURequested * cpuCost
-			}
+	// Idle pods and pods eligible for spot but not on it both count against cost efficiency
+	data.CostScore = 100
+	if podCount > 0 {
+		data.CostScore = CalculateCostScore(idlePods, spotPods, podCount)
+	}
 
-			data.Namespaces = append(data.Namespaces, item)
+	if costs == nil {
+		return
+	}
+	data.MonthlyCost = costs.TotalClusterCost
+	data.NamespaceCosts = costs.NamespaceCosts
+	data.OptimizationScenarios = costs.OptimizationScenarios
+	data.PotentialSavings = SavingsRange{
+		Min: costs.TotalSavingsPotential.Low,
+		Max: costs.TotalSavingsPotential.High,
+	}
+	for _, scenario := range costs.OptimizationScenarios {
+		data.CostBreakdown = append(data.CostBreakdown, CostItem{
+			Name:    scenario.Name,
+			Impact:  savingsImpact(scenario.Savings.Best, costs.TotalClusterCost),
+			Savings: scenario.Savings.Best,
+			Action:  scenario.Impact,
+		})
+	}
+}
+
+// savingsImpact rates savings by their share of the monthly cost
+func savingsImpact(savings, total float64) string {
+	switch {
+	case total > 0 && savings >= total*0.10:
+		return "High"
+	case total > 0 && savings >= total*0.03:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
+
+// addEmergencyIssues groups emergency issues by reason; critical and high go to critical issues
+func addEmergencyIssues(data *ReportData, issues []models.EmergencyIssue) {
+	type group struct {
+		severity string
+		resource string
+		reason   string
+		first    models.EmergencyIssue
+		details  []string
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, issue := range issues {
+		key := issue.Severity + "/" + issue.Resource + "/" + issue.Reason
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{severity: issue.Severity, resource: issue.Resource, reason: issue.Reason, first: issue}
+			byKey[key] = g
+			groups = append(groups, g)
 		}
+		g.details = append(g.details, fmt.Sprintf("%s/%s", issue.Namespace, issue.Name))
 	}
 
-	// Emergency issues
-	if emergency, ok := opts.EmergencyIssues.(*scanner.EmergencyIssues); ok {
-		if emergency.CriticalCount > 0 {
-			details := []string{}
-			for _, pod := range emergency.CriticalPods {
-				details = append(details, pod.Name)
-			}
+	for _, g := range groups {
+		noun := g.resource
+		if len(g.details) > 1 {
+			noun += "s"
+		}
+		description := g.first.Message
+		if g.first.Hint != "" {
+			description = g.first.Hint
+		}
+		item := IssueItem{
+			Description: description,
+			Count:       len(g.details),
+			Details:     g.details,
+		}
+		if g.severity == "critical" || g.severity == "high" {
+			item.Severity = "critical"
+			item.Title = fmt.Sprintf("🔴 %s: %d %s", g.reason, len(g.details), noun)
+			data.CriticalIssues = append(data.CriticalIssues, item)
+		} else {
+			item.Severity = "warning"
+			item.Title = fmt.Sprintf("🟡 %s: %d %s", g.reason, len(g.details), noun)
+			data.WarningIssues = append(data.WarningIssues, item)
+		}
+	}
+}
 
-			data.CriticalIssues = append(data.CriticalIssues, IssueItem{
-				Severity:    "critical",
-				Title:       fmt.Sprintf("🔴 %d pods in critical state", emergency.CriticalCount),
-				Description: "CrashLoopBackOff, ImagePullBackOff, or pending",
-				Count:       emergency.CriticalCount,
-				Details:     details,
-			})
+// affectedResources lists the top N pods with an issue type (deduplicated with counts)
+func affectedResources(issues []models.SecurityIssue, issueType string, limit int) []string {
+	if issueType == "" {
+		return nil
+	}
+	podCounts := make(map[string]int)
+	for _, issue := range issues {
+		if issue.Type == issueType {
+			podCounts[issue.Namespace+"/"+issue.Name]++
 		}
 	}
 
-	// Calculate overall score
-	data.OverallScore = CalculateOverallScore(data.SecurityScore, data.ResourceScore, data.CostScore)
+	type podInfo struct {
+		key   string
+		count int
+	}
+	var pods []podInfo
+	for key, count := range podCounts {
+		pods = append(pods, podInfo{key, count})
+	}
+	// Most issues first, then by name so reports are stable
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].count != pods[j].count {
+			return pods[i].count > pods[j].count
+		}
+		return pods[i].key < pods[j].key
+	})
 
-	return data
+	var resources []string
+	for i := 0; i < len(pods) && i < limit; i++ {
+		namespace, podName, _ := strings.Cut(pods[i].key, "/")
+		if pods[i].count > 1 {
+			resources = append(resources, fmt.Sprintf("%s in namespace %s (%d issues)", podName, namespace, pods[i].count))
+		} else {
+			resources = append(resources, fmt.Sprintf("%s in namespace %s", podName, namespace))
+		}
+	}
+
+	if remaining := len(pods) - limit; remaining > 0 {
+		resources = append(resources, fmt.Sprintf("... and %d more pods", remaining))
+	}
+	return resources
 }
-*/
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxFleetFindings caps the cross-cluster findings ranking
+const maxFleetFindings = 10
+
+// FleetData holds the per-cluster reports combined into one fleet report
+type FleetData struct {
+	Name        string // Cluster group, or "all-clusters"
+	GeneratedAt time.Time
+	Clusters    []*ReportData // Worst overall score first
+	Failed      []FleetFailure
+	TopFindings []FleetFinding
+}
+
+// FleetFailure records a cluster that could not be scanned
+type FleetFailure struct {
+	ClusterName string
+	Error       string
+}
+
+// FleetFinding is one cluster's issue in the cross-cluster ranking
+type FleetFinding struct {
+	ClusterName string
+	Severity    string // "critical", "warning"
+	Title       string
+	Count       int
+}
+
+// NewFleetData orders clusters worst first and ranks their findings across the fleet
+func NewFleetData(name string, clusters []*ReportData, failed []FleetFailure) *FleetData {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].OverallScore < clusters[j].OverallScore
+	})
+
+	var findings []FleetFinding
+	for _, cluster := range clusters {
+		for _, issue := range cluster.CriticalIssues {
+			findings = append(findings, FleetFinding{cluster.ClusterName, "critical", issue.Title, issue.Count})
+		}
+		for _, issue := range cluster.WarningIssues {
+			findings = append(findings, FleetFinding{cluster.ClusterName, "warning", issue.Title, issue.Count})
+		}
+	}
+	// Critical before warning, then by how many resources are affected
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity == "critical"
+		}
+		return findings[i].Count > findings[j].Count
+	})
+	if len(findings) > maxFleetFindings {
+		findings = findings[:maxFleetFindings]
+	}
+
+	return &FleetData{
+		Name:        name,
+		GeneratedAt: time.Now(),
+		Clusters:    clusters,
+		Failed:      failed,
+		TopFindings: findings,
+	}
+}
+
+// GenerateFleet writes a single self-contained HTML report covering every cluster
+func (g *Generator) GenerateFleet(fleet *FleetData) (string, error) {
+	if g.format != FormatHTML {
+		return "", fmt.Errorf("fleet reports support html only, not %s", g.format)
+	}
+	if g.custom != nil {
+		return "", fmt.Errorf("custom templates render single-cluster reports, not fleet reports")
+	}
+
+	tmpl, err := template.New("fleet").Funcs(g.htmlFuncs()).Parse(htmlStyles + clusterSections + brandingTemplate + fleetTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse fleet template: %w", err)
+	}
+
+	return g.write(fleet.Name, "fleet", "html", func(w io.Writer) error {
+		if err := tmpl.Execute(w, fleet); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return nil
+	})
+}
+
+// clusterAnchor turns a cluster name into an HTML id for drill-down links
+func clusterAnchor(name string) string {
+	var b strings.Builder
+	b.WriteString("cluster-")
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package report
+
+// fleetTemplate combines every cluster into one report: overview matrix, top findings, then per-cluster detail
+const fleetTemplate = `{{define "score"}}<span class="badge {{if ge . 80}}badge-success{{else if ge . 60}}badge-warning{{else}}badge-critical{{end}}">{{.}}</span>{{end}}<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>OpsCart Fleet Health Report - {{.Name}}</title>
+    {{template "styles"}}
+    <style>
+        .cluster-detail { border-top: 3px solid #667eea; padding-top: 30px; }
+        .cluster-title { font-size: 24px; font-weight: 600; color: #2d3748; margin-bottom: 20px; }
+        .back-link { font-size: 14px; color: #667eea; }
+        @media print {
+            .cluster-detail { page-break-before: always; }
+        }
+    </style>
+    {{template "branding-styles"}}
+</head>
+<body>
+    <div class="container">
+        <div class="header" id="overview">
+            {{template "branding-header"}}
+            <h1>🛡️ Kubernetes Fleet Health Report</h1>
+            <div class="header-meta">
+                <strong>Fleet:</strong> {{.Name}} &nbsp;|&nbsp;
+                <strong>Clusters:</strong> {{len .Clusters}} &nbsp;|&nbsp;
+                <strong>Generated:</strong> {{.GeneratedAt.Format "January 2, 2006 3:04 PM"}}
+            </div>
+        </div>
+
+        <div class="content">
+            <!-- Overview Matrix -->
+            <div class="section">
+                <div class="section-title">📊 Cluster Overview</div>
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>Cluster</th>
+                            <th>Overall</th>
+                            <th>Security</th>
+                            <th>Resources</th>
+                            <th>Cost</th>
+                            <th>Critical Issues</th>
+                            <th>Warnings</th>
+                            <th>Cost/Month</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .Clusters}}
+                        <tr>
+                            <td><a href="#{{anchor .ClusterName}}"><strong>{{.ClusterName}}</strong></a></td>
+                            <td>{{template "score" .OverallScore}}</td>
+                            <td>{{template "score" .SecurityScore}}</td>
+                            <td>{{template "score" .ResourceScore}}</td>
+                            <td>{{template "score" .CostScore}}</td>
+                            <td>{{len .CriticalIssues}}</td>
+                            <td>{{len .WarningIssues}}</td>
+                            <td>{{if gt .MonthlyCost 0.0}}{{formatMoney .MonthlyCost}}{{else}}-{{end}}</td>
+                        </tr>
+                        {{end}}
+                        {{range .Failed}}
+                        <tr>
+                            <td><strong>{{.ClusterName}}</strong></td>
+                            <td colspan="7"><span class="badge badge-critical">scan failed</span> {{.Error}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+
+            <!-- Top Findings -->
+            {{if .TopFindings}}
+            <div class="section">
+                <div class="section-title">🚨 Top {{len .TopFindings}} Findings Across the Fleet</div>
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>#</th>
+                            <th>Cluster</th>
+                            <th>Severity</th>
+                            <th>Finding</th>
+                            <th>Affected</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range $i, $f := .TopFindings}}
+                        <tr>
+                            <td>{{add $i 1}}</td>
+                            <td><a href="#{{anchor $f.ClusterName}}">{{$f.ClusterName}}</a></td>
+                            <td><span class="badge {{if eq $f.Severity "critical"}}badge-critical{{else}}badge-warning{{end}}">{{$f.Severity}}</span></td>
+                            <td>{{$f.Title}}</td>
+                            <td>{{$f.Count}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{end}}
+
+            <!-- Cluster Detail -->
+            {{range .Clusters}}
+            <div class="section cluster-detail" id="{{anchor .ClusterName}}">
+                <div class="cluster-title">☸️ {{.ClusterName}}</div>
+                {{template "cluster-sections" .}}
+                <a href="#overview" class="back-link">↑ Back to overview</a>
+            </div>
+            {{end}}
+
+            <!-- Actions -->
+            <div class="actions">
+                <button class="button" onclick="window.print()">📥 Download PDF</button>
+                <a href="https://opscart.com" target="_blank" class="button button-secondary">🌐 Visit OpsCart.com</a>
+            </div>
+        </div>
+    </div>
+
+    <div style="text-align: center; margin-top: 30px; padding: 20px; color: #718096; font-size: 14px;">
+        Generated by <strong>OpsCart Kubernetes Watcher v0.3</strong><br>
+        <a href="https://opscart.com" style="color: #667eea;">opscart.com</a>
+    </div>
+</body>
+</html>`
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+)
+
+// manifestFile lists what the tool wrote under a reports directory; retention only
+// deletes the reports and directories recorded there
+const manifestFile = ".opscart-reports.json"
+
+// reportManifest holds report files and the directories created for them, relative to
+// the reports directory
+type reportManifest struct {
+	Reports []string `json:"reports"`
+	Dirs    []string `json:"dirs,omitempty"`
+}
+
+// loadManifest reads dir's manifest (empty when none exists yet)
+func loadManifest(dir string) (*reportManifest, error) {
+	manifest := &reportManifest{}
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading report manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parsing report manifest (%s): %w", filepath.Join(dir, manifestFile), err)
+	}
+	return manifest, nil
+}
+
+// save writes the manifest into dir atomically
+func (m *reportManifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding report manifest: %w", err)
+	}
+	if err := config.WriteFileAtomic(filepath.Join(dir, manifestFile), data); err != nil {
+		return fmt.Errorf("writing report manifest: %w", err)
+	}
+	return nil
+}
+
+// recordReport adds a written report and the directories created for it to dir's manifest.
+// Files outside dir are not recorded, so retention never reaches them.
+func recordReport(dir, filename string, createdDirs []string) error {
+	rel, ok := relativeTo(dir, filename)
+	if !ok {
+		return nil
+	}
+
+	manifest, err := loadManifest(dir)
+	if err != nil {
+		return err
+	}
+	if !contains(manifest.Reports, rel) {
+		manifest.Reports = append(manifest.Reports, rel)
+	}
+	for _, created := range createdDirs {
+		if relDir, ok := relativeTo(dir, created); ok && !contains(manifest.Dirs, relDir) {
+			manifest.Dirs = append(manifest.Dirs, relDir)
+		}
+	}
+	return manifest.save(dir)
+}
+
+// missingDirs lists dir and its parents below root that do not exist yet
+func missingDirs(root, dir string) []string {
+	root = filepath.Clean(root)
+	var missing []string
+	for d := filepath.Clean(dir); d != root && d != "." && d != filepath.Dir(d); d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+	}
+	return missing
+}
+
+// relativeTo returns path relative to dir, and false when it is dir itself or outside it
+func relativeTo(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
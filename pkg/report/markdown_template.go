@@ -0,0 +1,57 @@
+package report
+
+// markdownTemplate renders the report as GitHub-flavored Markdown for tickets and wiki pages
+const markdownTemplate = `# Kubernetes Cluster Health Report: {{.ClusterName}}
+
+**Generated:** {{.GeneratedAt.Format "January 2, 2006 3:04 PM"}}
+
+## Scores
+
+| Overall | Security | Resources | Cost Efficiency |
+|---:|---:|---:|---:|
+| {{.OverallScore}}/100 | {{.SecurityScore}}/100 | {{.ResourceScore}}/100 | {{.CostScore}}/100 |
+
+| Pods | Namespaces | CPU (cores) | Memory (GB) |
+|---:|---:|---:|---:|
+| {{.PodCount}} | {{.NamespaceCount}} | {{formatFloat .UsedCPU}} of {{formatFloat .TotalCPU}} | {{formatFloat .UsedMemory}} of {{formatFloat .TotalMemory}} |
+{{if or .CriticalIssues .WarningIssues}}
+## Findings
+
+| Severity | Finding | Count | Details |
+|---|---|---:|---|
+{{range .CriticalIssues}}| critical | {{cell .Title}} | {{.Count}} | {{cell .Description}}{{if .Details}}<br>{{cell (join .Details "<br>")}}{{end}} |
+{{end}}{{range .WarningIssues}}| warning | {{cell .Title}} | {{.Count}} | {{cell .Description}}{{if .Details}}<br>{{cell (join .Details "<br>")}}{{end}} |
+{{end}}{{end}}{{if gt .MonthlyCost 0.0}}
+## Costs
+
+- **Monthly cluster cost:** {{formatMoney .MonthlyCost}}
+- **Potential savings:** {{formatMoney .PotentialSavings.Min}} - {{formatMoney .PotentialSavings.Max}}/month
+{{if .CostBreakdown}}
+| Opportunity | Impact | Savings/Month | Action |
+|---|---|---:|---|
+{{range .CostBreakdown}}| {{cell .Name}} | {{.Impact}} | {{formatMoney .Savings}} | {{cell .Action}} |
+{{end}}{{end}}{{end}}{{if .Namespaces}}
+## Namespaces
+
+| Namespace | CPU % | Memory % | Pods | Cost/Month | Flags |
+|---|---:|---:|---:|---:|---|
+{{range .Namespaces}}| {{cell .Name}} | {{formatPercent .CPUPercent}} | {{formatPercent .MemPercent}} | {{.PodCount}} | {{if gt $.MonthlyCost 0.0}}{{formatMoney .Cost}}{{else}}-{{end}} | {{cell (join .Flags ", ")}} |
+{{end}}{{end}}{{if .SecurityFindings}}
+## Security Controls
+
+CIS score **{{.CISScore}}/100** - {{.ControlsPassed}} of {{add .ControlsPassed .ControlsFailed}} controls passed
+
+| Control | Status | Found | Remediation |
+|---|---|---:|---|
+{{range .SecurityFindings}}| {{cell .Control}} | {{if eq .Status "passed"}}✅ passed{{else}}❌ failed{{end}} | {{.Count}} | {{cell .Remediation}} |
+{{end}}{{end}}{{if or .Suppressed .ExpiredSuppressions}}
+## Accepted Risks
+
+| Finding | Resource | Reason | Owner | Expires |
+|---|---|---|---|---|
+{{range .ExpiredSuppressions}}| {{cell .Type}} | {{cell .Namespace}}/{{cell .Name}} | {{cell .Reason}} | {{cell .Owner}} | ⏰ expired {{.Expires}} |
+{{end}}{{range .Suppressed}}| {{cell .Type}} | {{cell .Namespace}}/{{cell .Name}} | {{cell .Reason}} | {{cell .Owner}} | {{.Expires}} |
+{{end}}{{end}}
+---
+Generated by OpsCart Kubernetes Watcher v0.3
+`
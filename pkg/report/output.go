@@ -0,0 +1,209 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
+)
+
+const (
+	// DefaultReportsDir is where reports go unless --output-dir or the config says otherwise
+	DefaultReportsDir = "reports"
+
+	// DefaultFilenameTemplate keeps the historical reports/YYYY-MM-DD/<cluster>-<kind>-<time> layout,
+	// with seconds so back-to-back runs do not collide
+	DefaultFilenameTemplate = "{date}/{cluster}-{kind}-{timestamp}.{ext}"
+)
+
+// reportExtensions are the files LatestReport considers reports
+var reportExtensions = map[string]bool{".html": true, ".json": true, ".csv": true, ".md": true}
+
+// OutputOptions controls where reports are written
+type OutputOptions struct {
+	Path     string // Exact file (placeholders allowed); overrides Dir and Template, overwrites an existing file
+	Dir      string // Root directory for Template (default DefaultReportsDir)
+	Template string // Filename template relative to Dir (default DefaultFilenameTemplate)
+	Group    string // Cluster group, for {group}
+}
+
+// filename expands the output template. Placeholders: {cluster}, {group}, {kind}
+// (report, security, fleet...), {format}, {ext}, {date} (YYYY-MM-DD) and {timestamp} (HHMMSS).
+func (o OutputOptions) filename(cluster, kind string, format ReportFormat, ext string, now time.Time) string {
+	group := o.Group
+	if group == "" {
+		group = "default"
+	}
+	replacer := strings.NewReplacer(
+		"{cluster}", safeFilename(cluster),
+		"{group}", safeFilename(group),
+		"{kind}", kind,
+		"{format}", string(format),
+		"{ext}", ext,
+		"{date}", now.Format("2006-01-02"),
+		"{timestamp}", now.Format("150405"),
+	)
+
+	if o.Path != "" {
+		return replacer.Replace(o.Path)
+	}
+	tmpl := o.Template
+	if tmpl == "" {
+		tmpl = DefaultFilenameTemplate
+	}
+	return filepath.Join(o.reportsDir(), replacer.Replace(tmpl))
+}
+
+// reportsDir returns the root directory for generated report names
+func (o OutputOptions) reportsDir() string {
+	if o.Dir == "" {
+		return DefaultReportsDir
+	}
+	return o.Dir
+}
+
+// write renders a report into its output file atomically and returns the absolute path.
+// Generated names get a -2, -3... suffix instead of overwriting an earlier report.
+func (g *Generator) write(cluster, kind, ext string, render func(io.Writer) error) (string, error) {
+	filename := g.output.filename(cluster, kind, g.format, ext, time.Now())
+	if g.output.Path == "" {
+		filename = uniqueFilename(filename)
+	}
+
+	dir := filepath.Dir(filename)
+	root := g.output.reportsDir()
+	createdDirs := missingDirs(root, dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	// Render fully before writing so a failed run never leaves a partial report
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return "", err
+	}
+	if err := config.WriteFileAtomic(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	// Only generated names are recorded for retention; an explicit --output file is the user's
+	if g.output.Path == "" {
+		if err := recordReport(root, filename, createdDirs); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Abs(filename)
+}
+
+// uniqueFilename appends -2, -3... before the extension until the name is free
+func uniqueFilename(filename string) string {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return filename
+	}
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// safeFilename keeps cluster and group names from escaping the reports directory
+func safeFilename(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "_").Replace(name)
+}
+
+// PruneReports deletes reports under dir older than retention, then removes directories
+// left empty. Only files and directories recorded in dir's manifest as written by the
+// tool are touched. Returns how many reports were deleted.
+func PruneReports(dir string, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-retention)
+
+	manifest, err := loadManifest(dir)
+	if err != nil {
+		return 0, fmt.Errorf("pruning reports: %w", err)
+	}
+	if len(manifest.Reports) == 0 && len(manifest.Dirs) == 0 {
+		return 0, nil
+	}
+
+	deleted := 0
+	kept := []string{}
+	for _, rel := range manifest.Reports {
+		info, err := os.Stat(filepath.Join(dir, rel))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("pruning reports: %w", err)
+		}
+		if !info.ModTime().Before(cutoff) {
+			kept = append(kept, rel)
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, rel)); err != nil {
+			return deleted, fmt.Errorf("pruning reports: %w", err)
+		}
+		deleted++
+	}
+	manifest.Reports = kept
+
+	// Deepest first, so parents empty out after their children; non-empty directories stay
+	dirs := manifest.Dirs
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	manifest.Dirs = nil
+	for _, rel := range dirs {
+		if err := os.Remove(filepath.Join(dir, rel)); err != nil && !os.IsNotExist(err) {
+			manifest.Dirs = append(manifest.Dirs, rel)
+		}
+	}
+
+	if err := manifest.save(dir); err != nil {
+		return deleted, fmt.Errorf("pruning reports: %w", err)
+	}
+	return deleted, nil
+}
+
+// LatestReport returns the most recently written report under dir with the given
+// extension (any report when ext is empty)
+func LatestReport(dir, ext string) (string, error) {
+	var latest string
+	var latestTime time.Time
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !reportExtensions[filepath.Ext(path)] || strings.HasPrefix(entry.Name(), ".") {
+			return nil
+		}
+		if ext != "" && filepath.Ext(path) != ext {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latestTime) {
+			latest, latestTime = path, info.ModTime()
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("searching reports: %w", err)
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no reports found in %s", dir)
+	}
+	return filepath.Abs(latest)
+}
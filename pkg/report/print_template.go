@@ -0,0 +1,76 @@
+package report
+
+// printTemplate is the cluster report laid out for paper/PDF: no buttons or hover effects,
+// page breaks between sections, and the full control list as audit evidence
+const printTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>OpsCart Cluster Health Report - {{.ClusterName}}</title>
+    {{template "styles"}}
+    <style>
+        @page { size: A4; margin: 15mm; }
+        body { background: white; padding: 0; }
+        .container { max-width: none; box-shadow: none; border-radius: 0; }
+        .header, .health-score, .cost-box, .badge {
+            -webkit-print-color-adjust: exact;
+            print-color-adjust: exact;
+        }
+        .data-table tr:hover { background: none; }
+        .data-table thead { display: table-header-group; }
+        .data-table tr, .alert-box, .metric-card { page-break-inside: avoid; break-inside: avoid; }
+        .section-title { page-break-after: avoid; break-after: avoid; }
+        .page-break { page-break-before: always; break-before: page; }
+    </style>
+    {{template "branding-styles"}}
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            {{template "branding-header"}}
+            <h1>🛡️ Kubernetes Cluster Health Report</h1>
+            <div class="header-meta">
+                <strong>Cluster:</strong> {{.ClusterName}} &nbsp;|&nbsp;
+                <strong>Generated:</strong> {{.GeneratedAt.Format "January 2, 2006 3:04 PM MST"}}
+            </div>
+        </div>
+
+        <div class="content">
+            {{template "cluster-sections" .}}
+
+            <!-- Security Controls (audit evidence) -->
+            {{if .SecurityFindings}}
+            <div class="section page-break">
+                <div class="section-title">📋 Security Controls</div>
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>Control</th>
+                            <th>Status</th>
+                            <th>Found</th>
+                            <th>Remediation</th>
+                            <th>Affected Resources</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .SecurityFindings}}
+                        <tr>
+                            <td>{{.Control}}</td>
+                            <td><span class="badge {{if eq .Status "passed"}}badge-success{{else if eq .Severity "critical"}}badge-critical{{else}}badge-warning{{end}}">{{.Status}}</span></td>
+                            <td>{{.Count}}</td>
+                            <td>{{.Remediation}}</td>
+                            <td>{{range .Resources}}{{.}}<br>{{end}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{end}}
+        </div>
+    </div>
+
+    <div style="text-align: center; margin-top: 30px; padding: 20px; color: #718096; font-size: 14px;">
+        Generated by <strong>OpsCart Kubernetes Watcher v0.3</strong>
+    </div>
+</body>
+</html>`
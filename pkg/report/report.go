@@ -5,119 +5,146 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"os"
-	"path/filepath"
+	"io"
 	"strings"
+	texttemplate "text/template"
 	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/analyzer"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 )
 
 // ReportFormat defines the output format
 type ReportFormat string
 
 const (
-	FormatHTML ReportFormat = "html"
-	FormatJSON ReportFormat = "json"
-	FormatCSV  ReportFormat = "csv"
+	FormatHTML     ReportFormat = "html"
+	FormatJSON     ReportFormat = "json"
+	FormatCSV      ReportFormat = "csv"
+	FormatMarkdown ReportFormat = "markdown"
+	FormatPrint    ReportFormat = "print" // Print-optimized HTML for PDF conversion
 )
 
 // ReportData holds all data for report generation
 type ReportData struct {
-	ClusterName   string
-	GeneratedAt   time.Time
-	OverallScore  int
-	SecurityScore int
-	ResourceScore int
-	CostScore     int
+	ClusterName   string    `json:"cluster_name"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	OverallScore  int       `json:"overall_score"`
+	SecurityScore int       `json:"security_score"`
+	ResourceScore int       `json:"resource_score"`
+	CostScore     int       `json:"cost_score"`
 
 	// Critical issues
-	CriticalIssues []IssueItem
-	WarningIssues  []IssueItem
+	CriticalIssues []IssueItem `json:"critical_issues"`
+	WarningIssues  []IssueItem `json:"warning_issues"`
 
 	// Cost data
-	MonthlyCost      float64
-	PotentialSavings SavingsRange
-	CostBreakdown    []CostItem
+	MonthlyCost      float64      `json:"monthly_cost"`
+	PotentialSavings SavingsRange `json:"potential_savings"`
+	CostBreakdown    []CostItem   `json:"cost_breakdown"`
 
 	// Resource data
-	TotalCPU       float64
-	TotalMemory    float64
-	UsedCPU        float64
-	UsedMemory     float64
-	PodCount       int
-	NamespaceCount int
+	TotalCPU       float64 `json:"total_cpu_cores"`
+	TotalMemory    float64 `json:"total_memory_gb"`
+	UsedCPU        float64 `json:"used_cpu_cores"`
+	UsedMemory     float64 `json:"used_memory_gb"`
+	PodCount       int     `json:"pod_count"`
+	NamespaceCount int     `json:"namespace_count"`
 
 	// Security data
-	CISScore         int
-	ControlsPassed   int
-	ControlsFailed   int
-	SecurityFindings []SecurityFinding
+	CISScore         int               `json:"cis_score"`
+	ControlsPassed   int               `json:"controls_passed"`
+	ControlsFailed   int               `json:"controls_failed"`
+	IssueCount       int               `json:"issue_count"`
+	SecurityFindings []SecurityFinding `json:"security_findings"`
+
+	// Accepted-risk exceptions (.opscart-ignore.yaml); expired ones are also counted above
+	Suppressed          []models.SuppressedIssue `json:"suppressed"`
+	ExpiredSuppressions []models.SuppressedIssue `json:"expired_suppressions"`
+
+	// Set by security --baseline: the issues above are then only the new ones
+	Baseline *models.BaselineComparison `json:"baseline,omitempty"`
 
 	// Namespace breakdown
-	Namespaces []NamespaceItem
+	Namespaces []NamespaceItem `json:"namespaces"`
+
+	// Scan details behind the summaries, for the CSV and JSON exports
+	SecurityIssues        []models.SecurityIssue        `json:"security_issues"`
+	CISControls           []analyzer.CISControl         `json:"cis_controls"`
+	NamespaceCosts        []models.NamespaceCostInfo    `json:"namespace_costs"`
+	OptimizationScenarios []models.OptimizationScenario `json:"optimization_scenarios"`
 
 	// Trends (optional - for future)
-	TrendData *TrendData
+	TrendData *TrendData `json:"trends,omitempty"`
 }
 
 type IssueItem struct {
-	Severity    string // "critical", "warning", "info"
-	Title       string
-	Description string
-	Count       int
-	Details     []string
+	Severity    string   `json:"severity"` // "critical", "warning", "info"
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Count       int      `json:"count"`
+	Details     []string `json:"details"`
 }
 
 type SavingsRange struct {
-	Min float64
-	Max float64
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
 }
 
 type CostItem struct {
-	Name    string
-	Impact  string // "High", "Medium", "Low"
-	Savings float64
-	Action  string
+	Name    string  `json:"name"`
+	Impact  string  `json:"impact"` // "High", "Medium", "Low"
+	Savings float64 `json:"savings"`
+	Action  string  `json:"action"`
 }
 
 type SecurityFinding struct {
-	Control     string
-	Status      string // "passed", "failed"
-	Severity    string
-	Count       int
-	Resources   []string
-	Remediation string
+	Control     string   `json:"control"`
+	Status      string   `json:"status"` // "passed", "failed"
+	Severity    string   `json:"severity"`
+	Count       int      `json:"count"`
+	Resources   []string `json:"resources"`
+	Remediation string   `json:"remediation"`
 }
 
 type NamespaceItem struct {
-	Name       string
-	CPUPercent float64
-	MemPercent float64
-	PodCount   int
-	Cost       float64
-	Flags      []string
+	Name       string   `json:"name"`
+	CPUPercent float64  `json:"cpu_percent"`
+	MemPercent float64  `json:"memory_percent"`
+	PodCount   int      `json:"pod_count"`
+	Cost       float64  `json:"monthly_cost"`
+	Flags      []string `json:"flags"`
 }
 
 type TrendData struct {
-	HealthScoreTrend    []int
-	CriticalIssueTrend  []int
-	CostEfficiencyTrend []int
-	Labels              []string
+	HealthScoreTrend    []int    `json:"health_score_trend"`
+	CriticalIssueTrend  []int    `json:"critical_issue_trend"`
+	CostEfficiencyTrend []int    `json:"cost_efficiency_trend"`
+	Labels              []string `json:"labels"`
 }
 
 // Generator handles report generation
 type Generator struct {
-	format     ReportFormat
-	outputPath string
+	format   ReportFormat
+	output   OutputOptions
+	branding Branding
+	custom   *template.Template // Replaces the built-in HTML layout (see LoadTemplate)
 }
 
-// NewGenerator creates a new report generator
+// NewGenerator creates a new report generator. An empty outputPath writes to the
+// default reports directory and filename template.
 func NewGenerator(format ReportFormat, outputPath string) *Generator {
 	return &Generator{
-		format:     format,
-		outputPath: outputPath,
+		format: format,
+		output: OutputOptions{Path: outputPath},
 	}
 }
 
+// SetOutput replaces where the generator writes reports
+func (g *Generator) SetOutput(output OutputOptions) {
+	g.output = output
+}
+
 // Generate creates the report based on format
 func (g *Generator) Generate(data *ReportData) (string, error) {
 	switch g.format {
@@ -127,14 +154,18 @@ func (g *Generator) Generate(data *ReportData) (string, error) {
 		return g.generateJSON(data)
 	case FormatCSV:
 		return g.generateCSV(data)
+	case FormatMarkdown:
+		return g.generateMarkdown(data)
+	case FormatPrint:
+		return g.generatePrintHTML(data)
 	default:
 		return "", fmt.Errorf("unsupported format: %s", g.format)
 	}
 }
 
-// generateHTML creates an HTML report
-func (g *Generator) generateHTML(data *ReportData) (string, error) {
-	tmpl, err := template.New("report").Funcs(template.FuncMap{
+// htmlFuncs are the helpers available to the cluster, fleet and custom HTML templates
+func (g *Generator) htmlFuncs() template.FuncMap {
+	return template.FuncMap{
 		"formatFloat": func(f float64) string {
 			return fmt.Sprintf("%.1f", f)
 		},
@@ -159,186 +190,286 @@ func (g *Generator) generateHTML(data *ReportData) (string, error) {
 		"contains": func(s, substr string) bool {
 			return strings.Contains(s, substr)
 		},
-	}).Parse(htmlTemplate)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		"anchor": clusterAnchor,
+		"branding": func() Branding {
+			return g.branding
+		},
 	}
+}
 
-	// Generate filename if not specified
-	filename := g.outputPath
-	if filename == "" {
-		// Create reports/YYYY-MM-DD directory structure
-		today := time.Now().Format("2006-01-02")
-		reportsDir := filepath.Join("reports", today)
+// generateHTML creates an HTML report
+func (g *Generator) generateHTML(data *ReportData) (string, error) {
+	tmpl := g.custom
+	if tmpl == nil {
+		var err error
+		tmpl, err = template.New("report").Funcs(g.htmlFuncs()).Parse(htmlStyles + clusterSections + brandingTemplate + htmlTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template: %w", err)
+		}
+	}
 
-		if err := os.MkdirAll(reportsDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create reports directory: %w", err)
+	return g.write(data.ClusterName, "report", "html", func(w io.Writer) error {
+		// Execute template
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
 		}
+		return nil
+	})
+}
 
-		timestamp := time.Now().Format("1504")
-		filename = filepath.Join(reportsDir, fmt.Sprintf("%s-report-%s.html", data.ClusterName, timestamp))
+// generatePrintHTML creates a print-optimized HTML report
+func (g *Generator) generatePrintHTML(data *ReportData) (string, error) {
+	tmpl := g.custom
+	if tmpl == nil {
+		var err error
+		tmpl, err = template.New("print").Funcs(g.htmlFuncs()).Parse(htmlStyles + clusterSections + brandingTemplate + printTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse print template: %w", err)
+		}
 	}
 
-	// Create file
-	file, err := os.Create(filename)
+	return g.write(data.ClusterName, "print", "html", func(w io.Writer) error {
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return nil
+	})
+}
+
+// generateMarkdown creates a GitHub-flavored Markdown report
+func (g *Generator) generateMarkdown(data *ReportData) (string, error) {
+	tmpl, err := texttemplate.New("markdown").Funcs(texttemplate.FuncMap{
+		"formatFloat": func(f float64) string {
+			return fmt.Sprintf("%.1f", f)
+		},
+		"formatMoney": func(f float64) string {
+			return fmt.Sprintf("$%.0f", f)
+		},
+		"formatPercent": func(f float64) string {
+			return fmt.Sprintf("%.1f%%", f)
+		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"join": strings.Join,
+		"cell": markdownCell,
+	}).Parse(markdownTemplate)
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return "", fmt.Errorf("failed to parse markdown template: %w", err)
 	}
-	defer file.Close()
 
-	// Execute template
-	if err := tmpl.Execute(file, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
-	}
+	return g.write(data.ClusterName, "report", "md", func(w io.Writer) error {
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return nil
+	})
+}
 
-	return filepath.Abs(filename)
+// markdownCell keeps a value inside one table cell: pipes escaped, line breaks as <br>
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
 }
 
 // generateJSON creates a JSON report
 func (g *Generator) generateJSON(data *ReportData) (string, error) {
-	// Generate filename if not specified
-	filename := g.outputPath
-	if filename == "" {
-		// Create reports/YYYY-MM-DD directory structure
-		today := time.Now().Format("2006-01-02")
-		reportsDir := filepath.Join("reports", today)
-
-		if err := os.MkdirAll(reportsDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create reports directory: %w", err)
+	return g.write(data.ClusterName, "report", "json", func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		envelope := models.NewEnvelope("report", data.ClusterName, data)
+		envelope.Timestamp = data.GeneratedAt.UTC()
+		if err := encoder.Encode(envelope); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
 		}
+		return nil
+	})
+}
 
-		timestamp := time.Now().Format("1504")
-		filename = filepath.Join(reportsDir, fmt.Sprintf("%s-report-%s.json", data.ClusterName, timestamp))
-	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+// generateCSV creates a sectioned CSV report: summary, findings, security issues, CIS controls,
+// suppressed findings, namespaces, namespace costs and optimization scenarios. Each section starts with a
+// "# <name>" row and its own header row, and sections are separated by a blank row.
+func (g *Generator) generateCSV(data *ReportData) (string, error) {
+	return g.write(data.ClusterName, "report", "csv", func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+
+		writeCSVSection(writer, "Summary", []string{"Metric", "Value"}, [][]string{
+			{"Cluster", data.ClusterName},
+			{"Generated", data.GeneratedAt.Format("2006-01-02 15:04:05")},
+			{"Overall Score", fmt.Sprintf("%d", data.OverallScore)},
+			{"Security Score", fmt.Sprintf("%d", data.SecurityScore)},
+			{"Resource Score", fmt.Sprintf("%d", data.ResourceScore)},
+			{"Cost Score", fmt.Sprintf("%d", data.CostScore)},
+			{"CIS Score", fmt.Sprintf("%d", data.CISScore)},
+			{"Controls Passed", fmt.Sprintf("%d", data.ControlsPassed)},
+			{"Controls Failed", fmt.Sprintf("%d", data.ControlsFailed)},
+			{"Security Issues", fmt.Sprintf("%d", data.IssueCount)},
+			{"Pods", fmt.Sprintf("%d", data.PodCount)},
+			{"Namespaces", fmt.Sprintf("%d", data.NamespaceCount)},
+			{"CPU Cores Total", fmt.Sprintf("%.2f", data.TotalCPU)},
+			{"CPU Cores Used", fmt.Sprintf("%.2f", data.UsedCPU)},
+			{"Memory GB Total", fmt.Sprintf("%.2f", data.TotalMemory)},
+			{"Memory GB Used", fmt.Sprintf("%.2f", data.UsedMemory)},
+			{"Monthly Cost", fmt.Sprintf("%.2f", data.MonthlyCost)},
+			{"Potential Savings Min", fmt.Sprintf("%.2f", data.PotentialSavings.Min)},
+			{"Potential Savings Max", fmt.Sprintf("%.2f", data.PotentialSavings.Max)},
+		})
 
-	if err := encoder.Encode(data); err != nil {
-		return "", fmt.Errorf("failed to encode JSON: %w", err)
-	}
+		var findings [][]string
+		for _, issue := range append(append([]IssueItem{}, data.CriticalIssues...), data.WarningIssues...) {
+			findings = append(findings, []string{
+				issue.Severity, issue.Title, fmt.Sprintf("%d", issue.Count), issue.Description, strings.Join(issue.Details, "; "),
+			})
+		}
+		writeCSVSection(writer, "Findings", []string{"Severity", "Finding", "Count", "Description", "Affected"}, findings)
 
-	return filepath.Abs(filename)
-}
+		var issues [][]string
+		for _, issue := range data.SecurityIssues {
+			issues = append(issues, []string{
+				issue.Type, issue.Severity, issue.Namespace, issue.Resource, issue.Name, issue.Description, issue.Remediation,
+			})
+		}
+		writeCSVSection(writer, "Security Issues", []string{"Type", "Severity", "Namespace", "Resource", "Name", "Description", "Remediation"}, issues)
 
-// generateCSV creates a CSV report (summary only)
-func (g *Generator) generateCSV(data *ReportData) (string, error) {
-	// Generate filename if not specified
-	filename := g.outputPath
-	if filename == "" {
-		// Create reports/YYYY-MM-DD directory structure
-		today := time.Now().Format("2006-01-02")
-		reportsDir := filepath.Join("reports", today)
-
-		if err := os.MkdirAll(reportsDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create reports directory: %w", err)
+		var controls [][]string
+		for _, control := range data.CISControls {
+			status := "failed"
+			if control.Passed {
+				status = "passed"
+			}
+			controls = append(controls, []string{
+				control.ID, control.Description, status, fmt.Sprintf("%.0f", control.Weight), control.Finding,
+			})
+		}
+		writeCSVSection(writer, "CIS Controls", []string{"Control", "Description", "Status", "Weight", "Finding"}, controls)
+
+		var suppressed [][]string
+		for _, group := range []struct {
+			status string
+			issues []models.SuppressedIssue
+		}{{"expired", data.ExpiredSuppressions}, {"suppressed", data.Suppressed}} {
+			for _, issue := range group.issues {
+				suppressed = append(suppressed, []string{
+					group.status, issue.Type, issue.Severity, issue.Namespace, issue.Name, issue.Reason, issue.Owner, issue.Expires,
+				})
+			}
+		}
+		writeCSVSection(writer, "Suppressed Findings", []string{"Status", "Type", "Severity", "Namespace", "Name", "Reason", "Owner", "Expires"}, suppressed)
+
+		var namespaces [][]string
+		for _, ns := range data.Namespaces {
+			namespaces = append(namespaces, []string{
+				ns.Name,
+				fmt.Sprintf("%.1f", ns.CPUPercent),
+				fmt.Sprintf("%.1f", ns.MemPercent),
+				fmt.Sprintf("%d", ns.PodCount),
+				fmt.Sprintf("%.2f", ns.Cost),
+				strings.Join(ns.Flags, "; "),
+			})
+		}
+		writeCSVSection(writer, "Namespaces", []string{"Namespace", "CPU %", "Memory %", "Pods", "Cost/Month", "Flags"}, namespaces)
+
+		var costs [][]string
+		for _, ns := range data.NamespaceCosts {
+			costs = append(costs, []string{
+				ns.Name,
+				fmt.Sprintf("%.2f", ns.EstimatedCost.Low),
+				fmt.Sprintf("%.2f", ns.EstimatedCost.Best),
+				fmt.Sprintf("%.2f", ns.EstimatedCost.High),
+				fmt.Sprintf("%.4f", ns.CPUShare),
+				fmt.Sprintf("%.4f", ns.MemoryShare),
+				fmt.Sprintf("%.2f", ns.ComputeCost),
+				fmt.Sprintf("%.2f", ns.StorageCost),
+				fmt.Sprintf("%.2f", ns.NetworkCost),
+			})
+		}
+		writeCSVSection(writer, "Namespace Costs", []string{"Namespace", "Cost Low", "Cost Best", "Cost High", "CPU Share", "Memory Share", "Compute", "Storage", "Network"}, costs)
+
+		var scenarios [][]string
+		for _, scenario := range data.OptimizationScenarios {
+			scenarios = append(scenarios, []string{
+				scenario.Name,
+				scenario.Description,
+				fmt.Sprintf("%.2f", scenario.CurrentCost.Best),
+				fmt.Sprintf("%.2f", scenario.AfterCost.Best),
+				fmt.Sprintf("%.2f", scenario.Savings.Low),
+				fmt.Sprintf("%.2f", scenario.Savings.Best),
+				fmt.Sprintf("%.2f", scenario.Savings.High),
+				scenario.Effort,
+				scenario.Risk,
+				scenario.Timeline,
+				scenario.Impact,
+				strings.Join(scenario.Actions, "; "),
+			})
 		}
+		writeCSVSection(writer, "Optimization Scenarios", []string{"Scenario", "Description", "Current Cost", "After Cost", "Savings Low", "Savings Best", "Savings High", "Effort", "Risk", "Timeline", "Impact", "Actions"}, scenarios)
 
-		timestamp := time.Now().Format("1504")
-		filename = filepath.Join(reportsDir, fmt.Sprintf("%s-report-%s.csv", data.ClusterName, timestamp))
-	}
+		writer.Flush()
+		return writer.Error()
+	})
+}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+// writeCSVSection writes a "# <title>" row, the header and rows, then a blank separator row.
+// Empty sections keep their header so every export has the same layout.
+func writeCSVSection(writer *csv.Writer, title string, header []string, rows [][]string) {
+	writer.Write([]string{"# " + title})
+	writer.Write(header)
+	for _, row := range rows {
+		writer.Write(row)
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write summary
-	writer.Write([]string{"Metric", "Value"})
-	writer.Write([]string{"Cluster", data.ClusterName})
-	writer.Write([]string{"Generated", data.GeneratedAt.Format("2006-01-02 15:04:05")})
-	writer.Write([]string{"Overall Score", fmt.Sprintf("%d", data.OverallScore)})
-	writer.Write([]string{"Security Score", fmt.Sprintf("%d", data.SecurityScore)})
-	writer.Write([]string{"Resource Score", fmt.Sprintf("%d", data.ResourceScore)})
-	writer.Write([]string{"Cost Score", fmt.Sprintf("%d", data.CostScore)})
 	writer.Write([]string{})
-
-	// Write namespace breakdown
-	writer.Write([]string{"Namespace", "CPU %", "Memory %", "Pods", "Cost/Month", "Flags"})
-	for _, ns := range data.Namespaces {
-		flags := ""
-		if len(ns.Flags) > 0 {
-			flags = ns.Flags[0]
-		}
-		writer.Write([]string{
-			ns.Name,
-			fmt.Sprintf("%.1f", ns.CPUPercent),
-			fmt.Sprintf("%.1f", ns.MemPercent),
-			fmt.Sprintf("%d", ns.PodCount),
-			fmt.Sprintf("%.0f", ns.Cost),
-			flags,
-		})
-	}
-
-	return filepath.Abs(filename)
 }
 
 // GenerateSecurityHTML creates a security-focused HTML report (public method)
 func (g *Generator) GenerateSecurityHTML(data *ReportData) (string, error) {
-	tmpl, err := template.New("security").Funcs(template.FuncMap{
-		"formatFloat": func(f float64) string {
-			return fmt.Sprintf("%.1f", f)
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"ge": func(a, b int) bool {
-			return a >= b
-		},
-		"eq": func(a, b string) bool {
-			return a == b
-		},
-		"ne": func(a, b string) bool {
-			return a != b
-		},
-	}).Parse(securityHTMLTemplate)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to parse security template: %w", err)
-	}
-
-	// Determine output path
-	filename := g.outputPath
-	if filename == "" {
-		today := time.Now().Format("2006-01-02")
-		timestamp := time.Now().Format("1504")
-		reportsDir := filepath.Join("reports", today)
-
-		if err := os.MkdirAll(reportsDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create reports directory: %w", err)
+	tmpl := g.custom
+	if tmpl == nil {
+		var err error
+		tmpl, err = template.New("security").Funcs(template.FuncMap{
+			"formatFloat": func(f float64) string {
+				return fmt.Sprintf("%.1f", f)
+			},
+			"add": func(a, b int) int {
+				return a + b
+			},
+			"ge": func(a, b int) bool {
+				return a >= b
+			},
+			"eq": func(a, b string) bool {
+				return a == b
+			},
+			"ne": func(a, b string) bool {
+				return a != b
+			},
+			"branding": func() Branding {
+				return g.branding
+			},
+		}).Parse(brandingTemplate + securityHTMLTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse security template: %w", err)
 		}
-
-		filename = filepath.Join(reportsDir, fmt.Sprintf("%s-security-%s.html", data.ClusterName, timestamp))
-	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return filepath.Abs(filename)
+	return g.write(data.ClusterName, "security", "html", func(w io.Writer) error {
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return nil
+	})
 }
 
+// Overall score weights of the security, resource and cost scores
+const (
+	securityWeight = 40
+	resourceWeight = 30
+	costWeight     = 30
+)
+
 // CalculateOverallScore computes overall health score
 func CalculateOverallScore(security, resource, cost int) int {
 	// Weighted average: Security 40%, Resource 30%, Cost 30%
-	return (security*40 + resource*30 + cost*30) / 100
+	return (security*securityWeight + resource*resourceWeight + cost*costWeight) / 100
 }
 
 // CalculateResourceScore computes resource utilization score
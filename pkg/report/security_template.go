@@ -152,10 +152,12 @@ const securityHTMLTemplate = `<!DOCTYPE html>
             .button { display: none; }
         }
     </style>
+    {{template "branding-styles"}}
 </head>
 <body>
     <div class="container">
         <div class="header">
+            {{template "branding-header"}}
             <h1>🛡️ Security Audit Report</h1>
             <div class="header-meta">
                 <strong>Cluster:</strong> {{.ClusterName}} &nbsp;|&nbsp;
@@ -190,10 +192,10 @@ const securityHTMLTemplate = `<!DOCTYPE html>
                         <div class="metric-value">{{.PodCount}}</div>
                     </div>
                     {{end}}
-                    {{if .NamespaceCount}}
+                    {{if .IssueCount}}
                     <div class="metric-card">
                         <div class="metric-label">Issues Found</div>
-                        <div class="metric-value" style="color: #fc8181;">{{.NamespaceCount}}</div>
+                        <div class="metric-value" style="color: #fc8181;">{{.IssueCount}}</div>
                     </div>
                     {{end}}
                     <div class="metric-card">
@@ -207,6 +209,25 @@ const securityHTMLTemplate = `<!DOCTYPE html>
                 </div>
             </div>
             
+            <!-- Baseline Comparison -->
+            {{with .Baseline}}
+            <div class="section">
+                <div class="section-title">📌 Baseline Comparison</div>
+                <div class="finding-box finding-pass">
+                    <div class="finding-body">
+                        Compared with the baseline saved {{.CreatedAt.Format "January 2, 2006 3:04 PM MST"}}: only new findings are listed and scored.
+                        {{.Existing}} findings already in the baseline are not shown; {{len .Fixed}} have been fixed since.
+                    </div>
+                    {{if .Fixed}}
+                    <div class="resource-list">
+                        <strong>Fixed since baseline:</strong><br>
+                        {{range .Fixed}}✅ {{.Type}}: {{.Name}} in namespace {{.Namespace}}<br>{{end}}
+                    </div>
+                    {{end}}
+                </div>
+            </div>
+            {{end}}
+            
             <!-- Critical Findings -->
             {{if .CriticalIssues}}
             <div class="section">
@@ -274,6 +295,44 @@ const securityHTMLTemplate = `<!DOCTYPE html>
             </div>
             {{end}}
             
+            <!-- Accepted Risks -->
+            {{if or .Suppressed .ExpiredSuppressions}}
+            <div class="section">
+                <div class="section-title">🔕 Accepted Risks</div>
+                <table style="width: 100%; border-collapse: collapse;">
+                    <thead>
+                        <tr style="background: #f7fafc; border-bottom: 2px solid #e2e8f0;">
+                            <th style="padding: 12px; text-align: left; font-weight: 600;">Finding</th>
+                            <th style="padding: 12px; text-align: left; font-weight: 600;">Resource</th>
+                            <th style="padding: 12px; text-align: left; font-weight: 600;">Reason</th>
+                            <th style="padding: 12px; text-align: left; font-weight: 600;">Owner</th>
+                            <th style="padding: 12px; text-align: left; font-weight: 600;">Expires</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .ExpiredSuppressions}}
+                        <tr style="border-bottom: 1px solid #e2e8f0;">
+                            <td style="padding: 12px;">{{.Type}}</td>
+                            <td style="padding: 12px;">{{.Namespace}}/{{.Name}}</td>
+                            <td style="padding: 12px;">{{.Reason}}</td>
+                            <td style="padding: 12px;">{{.Owner}}</td>
+                            <td style="padding: 12px;"><span class="badge badge-critical">expired {{.Expires}}</span></td>
+                        </tr>
+                        {{end}}
+                        {{range .Suppressed}}
+                        <tr style="border-bottom: 1px solid #e2e8f0;">
+                            <td style="padding: 12px;">{{.Type}}</td>
+                            <td style="padding: 12px;">{{.Namespace}}/{{.Name}}</td>
+                            <td style="padding: 12px;">{{.Reason}}</td>
+                            <td style="padding: 12px;">{{.Owner}}</td>
+                            <td style="padding: 12px;">{{.Expires}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{end}}
+            
             <!-- Recommended Actions -->
             <div class="section">
                 <div class="section-title">📋 Recommended Actions (Priority Order)</div>
@@ -304,7 +363,7 @@ const securityHTMLTemplate = `<!DOCTYPE html>
             </div>
             
             <!-- Issue Count Breakdown -->
-            {{if .NamespaceCount}}
+            {{if .IssueCount}}
             <div class="section">
                 <div class="section-title">📊 Issue Count Breakdown</div>
                 <table style="width: 100%; border-collapse: collapse;">
@@ -329,7 +388,7 @@ const securityHTMLTemplate = `<!DOCTYPE html>
                         {{end}}
                         <tr style="background: #f7fafc; font-weight: 700; border-top: 2px solid #2d3748;">
                             <td style="padding: 12px;">TOTAL ISSUES</td>
-                            <td style="padding: 12px; text-align: right;">{{.NamespaceCount}}</td>
+                            <td style="padding: 12px; text-align: right;">{{.IssueCount}}</td>
                         </tr>
                     </tbody>
                 </table>
@@ -1,12 +1,7 @@
 package report
 
-// htmlTemplate is the embedded HTML template for reports
-const htmlTemplate = `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>OpsCart Cluster Health Report - {{.ClusterName}}</title>
+// htmlStyles is the stylesheet shared by the cluster and fleet reports
+const htmlStyles = `{{define "styles"}}
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body {
@@ -184,19 +179,10 @@ const htmlTemplate = `<!DOCTYPE html>
             .button { display: none; }
         }
     </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>🛡️ Kubernetes Cluster Health Report</h1>
-            <div class="header-meta">
-                <strong>Cluster:</strong> {{.ClusterName}} &nbsp;|&nbsp;
-                <strong>Generated:</strong> {{.GeneratedAt.Format "January 2, 2006 3:04 PM"}} &nbsp;|&nbsp;
-                <strong>Period:</strong> Last 24 hours
-            </div>
-        </div>
-        
-        <div class="content">
+{{end}}`
+
+// clusterSections renders one cluster's scores, issues, costs, namespaces and security summary
+const clusterSections = `{{define "cluster-sections"}}
             <!-- Overall Health Score -->
             <div class="section">
                 <div class="health-score">
@@ -373,6 +359,70 @@ const htmlTemplate = `<!DOCTYPE html>
             </div>
             {{end}}
             
+            <!-- Accepted Risks -->
+            {{if or .Suppressed .ExpiredSuppressions}}
+            <div class="section">
+                <div class="section-title">🔕 Accepted Risks</div>
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>Finding</th>
+                            <th>Resource</th>
+                            <th>Reason</th>
+                            <th>Owner</th>
+                            <th>Expires</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .ExpiredSuppressions}}
+                        <tr>
+                            <td>{{.Type}}</td>
+                            <td>{{.Namespace}}/{{.Name}}</td>
+                            <td>{{.Reason}}</td>
+                            <td>{{.Owner}}</td>
+                            <td><span class="badge badge-critical">expired {{.Expires}}</span></td>
+                        </tr>
+                        {{end}}
+                        {{range .Suppressed}}
+                        <tr>
+                            <td>{{.Type}}</td>
+                            <td>{{.Namespace}}/{{.Name}}</td>
+                            <td>{{.Reason}}</td>
+                            <td>{{.Owner}}</td>
+                            <td>{{.Expires}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{end}}
+{{end}}`
+
+// htmlTemplate is the embedded HTML template for reports
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>OpsCart Cluster Health Report - {{.ClusterName}}</title>
+    {{template "styles"}}
+    {{template "branding-styles"}}
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            {{template "branding-header"}}
+            <h1>🛡️ Kubernetes Cluster Health Report</h1>
+            <div class="header-meta">
+                <strong>Cluster:</strong> {{.ClusterName}} &nbsp;|&nbsp;
+                <strong>Generated:</strong> {{.GeneratedAt.Format "January 2, 2006 3:04 PM"}} &nbsp;|&nbsp;
+                <strong>Period:</strong> Last 24 hours
+            </div>
+        </div>
+        
+        <div class="content">
+            {{template "cluster-sections" .}}
+            
             <!-- Actions -->
             <div class="actions">
                 <button class="button" onclick="window.print()">📥 Download PDF</button>
@@ -3,8 +3,10 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/opscart/opscart-k8s-watcher/pkg/config"
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,6 +19,7 @@ type Scanner struct {
 	clientset   *kubernetes.Clientset
 	clusterName string
 	ctx         context.Context
+	pricing     *config.Pricing // Prices orphaned storage and LoadBalancers (see SetPricing)
 }
 
 // NewScanner creates a new scanner for the given cluster context
@@ -46,6 +49,11 @@ func NewScanner(clusterContext string) (*Scanner, error) {
 	}, nil
 }
 
+// SetPricing prices orphaned volumes and LoadBalancers from a pricing file instead of list prices
+func (s *Scanner) SetPricing(pricing *config.Pricing) {
+	s.pricing = pricing
+}
+
 // FindEmergencyIssues scans for critical problems that need immediate attention
 func (s *Scanner) FindEmergencyIssues(namespace string) ([]models.EmergencyIssue, error) {
 	var issues []models.EmergencyIssue
@@ -68,6 +76,14 @@ func (s *Scanner) FindEmergencyIssues(namespace string) ([]models.EmergencyIssue
 		issues = append(issues, pvcIssues...)
 	}
 
+	// Check controllers (deployments, statefulsets, daemonsets, jobs, cronjobs)
+	issues = append(issues, s.findWorkloadIssues(namespace)...)
+
+	// Attach recent events so responders don't need kubectl describe
+	if err := s.attachEvents(namespace, issues); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not fetch events: %v\n", err)
+	}
+
 	return issues, nil
 }
 
@@ -93,8 +109,9 @@ func (s *Scanner) analyzePodForIssues(pod corev1.Pod) []models.EmergencyIssue {
 			Name:      pod.Name,
 			Reason:    "PodFailed",
 			Message:   fmt.Sprintf("Pod in Failed state: %s", pod.Status.Reason),
-			Age:       age,
+			Age:       models.Duration(age),
 			Restarts:  totalRestarts,
+			Hint:      podReasonHints[pod.Status.Reason],
 		})
 
 	case corev1.PodPending:
@@ -118,24 +135,63 @@ func (s *Scanner) analyzePodForIssues(pod corev1.Pod) []models.EmergencyIssue {
 				Name:      pod.Name,
 				Reason:    reason,
 				Message:   message,
-				Age:       age,
+				Age:       models.Duration(age),
 			})
 		}
 	}
 
 	// Check container statuses
 	for _, cs := range pod.Status.ContainerStatuses {
-		// CrashLoopBackOff
+		start := len(issues)
+		// CrashLoopBackOff - explain the crash from the last termination
 		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			class, hint := classifyTermination(cs.LastTerminationState.Terminated)
+			message := fmt.Sprintf("Container %s is crash looping: %s", cs.Name, cs.State.Waiting.Message)
+			if class != "" {
+				message += fmt.Sprintf(" (last exit: %s)", class)
+			}
 			issues = append(issues, models.EmergencyIssue{
-				Severity:  "critical",
+				Severity:         "critical",
+				Resource:         "pod",
+				Namespace:        pod.Namespace,
+				Name:             pod.Name,
+				Reason:           "CrashLoopBackOff",
+				Message:          message,
+				Age:              models.Duration(age),
+				Restarts:         int(cs.RestartCount),
+				TerminationClass: class,
+				Hint:             hint,
+			})
+		}
+
+		// Waiting reasons that prevent the container from ever starting
+		if cs.State.Waiting != nil && waitingReasonHints[cs.State.Waiting.Reason] != "" {
+			issues = append(issues, models.EmergencyIssue{
+				Severity:  "high",
 				Resource:  "pod",
 				Namespace: pod.Namespace,
 				Name:      pod.Name,
-				Reason:    "CrashLoopBackOff",
-				Message:   fmt.Sprintf("Container %s is crash looping: %s", cs.Name, cs.State.Waiting.Message),
-				Age:       age,
-				Restarts:  int(cs.RestartCount),
+				Reason:    cs.State.Waiting.Reason,
+				Message:   fmt.Sprintf("Container %s cannot start: %s", cs.Name, cs.State.Waiting.Message),
+				Age:       models.Duration(age),
+				Hint:      waitingReasonHints[cs.State.Waiting.Reason],
+			})
+		}
+
+		// Container stopped with a failure and is not being restarted (restartPolicy Never/OnFailure, Jobs)
+		if term := cs.State.Terminated; term != nil && (term.ExitCode != 0 || term.Reason == "ContainerCannotRun" || term.Reason == "DeadlineExceeded") {
+			class, hint := classifyTermination(term)
+			issues = append(issues, models.EmergencyIssue{
+				Severity:         "high",
+				Resource:         "pod",
+				Namespace:        pod.Namespace,
+				Name:             pod.Name,
+				Reason:           term.Reason,
+				Message:          fmt.Sprintf("Container %s terminated: %s", cs.Name, class),
+				Age:              models.Duration(age),
+				Restarts:         int(cs.RestartCount),
+				TerminationClass: class,
+				Hint:             hint,
 			})
 		}
 
@@ -148,7 +204,7 @@ func (s *Scanner) analyzePodForIssues(pod corev1.Pod) []models.EmergencyIssue {
 				Name:      pod.Name,
 				Reason:    cs.State.Waiting.Reason,
 				Message:   fmt.Sprintf("Cannot pull image for container %s: %s", cs.Name, cs.State.Waiting.Message),
-				Age:       age,
+				Age:       models.Duration(age),
 			})
 		}
 
@@ -161,8 +217,9 @@ func (s *Scanner) analyzePodForIssues(pod corev1.Pod) []models.EmergencyIssue {
 				Name:      pod.Name,
 				Reason:    "OOMKilled",
 				Message:   fmt.Sprintf("Container %s killed due to out of memory", cs.Name),
-				Age:       age,
+				Age:       models.Duration(age),
 				Restarts:  int(cs.RestartCount),
+				Hint:      oomKilledHint,
 			})
 		}
 
@@ -175,12 +232,19 @@ func (s *Scanner) analyzePodForIssues(pod corev1.Pod) []models.EmergencyIssue {
 				Name:      pod.Name,
 				Reason:    "HighRestartCount",
 				Message:   fmt.Sprintf("Container %s has restarted %d times", cs.Name, cs.RestartCount),
-				Age:       age,
+				Age:       models.Duration(age),
 				Restarts:  int(cs.RestartCount),
 			})
 		}
+
+		// Container-level issues point at that container and its image (not sidecars)
+		for i := start; i < len(issues); i++ {
+			issues[i].Images = []string{containerImage(pod, cs.Name)}
+			issues[i].Container = cs.Name
+		}
 	}
 
+	applyPodContext(pod, issues)
 	return issues
 }
 
@@ -198,26 +262,30 @@ func (s *Scanner) findPVCIssues(namespace string) ([]models.EmergencyIssue, erro
 			age := time.Since(pvc.CreationTimestamp.Time)
 			if age > 2*time.Minute {
 				issues = append(issues, models.EmergencyIssue{
-					Severity:  "high",
-					Resource:  "pvc",
-					Namespace: pvc.Namespace,
-					Name:      pvc.Name,
-					Reason:    "PVCPending",
-					Message:   "PersistentVolumeClaim stuck in Pending state",
-					Age:       age,
+					Severity:     "high",
+					Resource:     "pvc",
+					Namespace:    pvc.Namespace,
+					Name:         pvc.Name,
+					Reason:       "PVCPending",
+					Message:      "PersistentVolumeClaim stuck in Pending state",
+					Age:          models.Duration(age),
+					Claims:       []string{pvc.Name},
+					StorageClass: storageClassName(pvc),
 				})
 			}
 		}
 
 		if pvc.Status.Phase == corev1.ClaimLost {
 			issues = append(issues, models.EmergencyIssue{
-				Severity:  "critical",
-				Resource:  "pvc",
-				Namespace: pvc.Namespace,
-				Name:      pvc.Name,
-				Reason:    "PVCLost",
-				Message:   "PersistentVolumeClaim in Lost state - data may be unavailable",
-				Age:       time.Since(pvc.CreationTimestamp.Time),
+				Severity:     "critical",
+				Resource:     "pvc",
+				Namespace:    pvc.Namespace,
+				Name:         pvc.Name,
+				Reason:       "PVCLost",
+				Message:      "PersistentVolumeClaim in Lost state - data may be unavailable",
+				Age:          models.Duration(time.Since(pvc.CreationTimestamp.Time)),
+				Claims:       []string{pvc.Name},
+				StorageClass: storageClassName(pvc),
 			})
 		}
 	}
@@ -258,7 +326,7 @@ func (s *Scanner) TakeSnapshot(namespace string) (*models.ClusterSnapshot, error
 				ReadyReplicas:     deploy.Status.ReadyReplicas,
 				AvailableReplicas: deploy.Status.AvailableReplicas,
 				Healthy:           deploy.Status.ReadyReplicas == *deploy.Spec.Replicas,
-				Age:               time.Since(deploy.CreationTimestamp.Time),
+				Age:               models.Duration(time.Since(deploy.CreationTimestamp.Time)),
 			})
 		}
 	}
@@ -266,34 +334,6 @@ func (s *Scanner) TakeSnapshot(namespace string) (*models.ClusterSnapshot, error
 	return snapshot, nil
 }
 
-// FindIdleResources identifies resources with zero activity
-func (s *Scanner) FindIdleResources(namespace string) ([]models.IdleResource, error) {
-	var idle []models.IdleResource
-
-	// This is a placeholder - real implementation would check metrics
-	// For now, we'll identify deployments with 0 replicas or pods that haven't restarted in 30 days
-
-	deployList, err := s.clientset.AppsV1().Deployments(namespace).List(s.ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	for _, deploy := range deployList.Items {
-		if *deploy.Spec.Replicas == 0 {
-			idle = append(idle, models.IdleResource{
-				Type:           "deployment",
-				Name:           deploy.Name,
-				Namespace:      deploy.Namespace,
-				IdleDays:       int(time.Since(deploy.CreationTimestamp.Time).Hours() / 24),
-				LastActivity:   deploy.CreationTimestamp.Time,
-				Recommendation: "Consider deleting if no longer needed",
-			})
-		}
-	}
-
-	return idle, nil
-}
-
 // isPodReady checks if all containers in a pod are ready
 func isPodReady(pod corev1.Pod) bool {
 	for _, condition := range pod.Status.Conditions {
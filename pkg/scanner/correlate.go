@@ -0,0 +1,401 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/kube"
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// minIncidentSize is the number of distinct resources needed before issues are grouped
+const minIncidentSize = 2
+
+// missingConfigPattern matches kubelet messages such as: configmap "app-config" not found
+var missingConfigPattern = regexp.MustCompile(`(?i)(configmap|secret)s? "([^"]+)" not found`)
+
+// imagePullReasons are waiting reasons that point at the image itself
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// severityRank orders severities for picking an incident's overall severity
+var severityRank = map[string]int{
+	"critical": 4,
+	"high":     3,
+	"medium":   2,
+	"low":      1,
+}
+
+// CorrelateIssues groups issues that share a probable root cause into incidents.
+// Grouped issues get their IncidentID set so printers can show them under the incident.
+func (s *Scanner) CorrelateIssues(issues []models.EmergencyIssue) []models.Incident {
+	nodeProblems, nodeCount := s.getNodeProblems()
+	return correlateIssues(issues, nodeProblems, nodeCount)
+}
+
+// getNodeProblems returns unhealthy node conditions keyed by node name, plus the node count
+func (s *Scanner) getNodeProblems() (map[string]string, int) {
+	problems := make(map[string]string)
+
+	nodeList, err := s.clientset.CoreV1().Nodes().List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return problems, 0
+	}
+
+	for _, node := range nodeList.Items {
+		var conditions []string
+		for _, condition := range node.Status.Conditions {
+			switch condition.Type {
+			case corev1.NodeReady:
+				if condition.Status != corev1.ConditionTrue {
+					conditions = append(conditions, "NotReady")
+				}
+			case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure, corev1.NodeNetworkUnavailable:
+				if condition.Status == corev1.ConditionTrue {
+					conditions = append(conditions, string(condition.Type))
+				}
+			}
+		}
+		if node.Spec.Unschedulable {
+			conditions = append(conditions, "cordoned")
+		}
+		if len(conditions) > 0 {
+			problems[node.Name] = strings.Join(conditions, ", ")
+		}
+	}
+
+	return problems, len(nodeList.Items)
+}
+
+// correlationPass groups issues along one dimension of shared cause
+type correlationPass struct {
+	cause string
+	keys  func(issue models.EmergencyIssue) []string
+}
+
+// correlateIssues runs correlation passes in priority order; each issue joins at most one incident.
+// Unhealthy nodes come first; failures merely sharing a healthy node are grouped only after the
+// config, storage and image passes have had a chance to find a more specific cause.
+func correlateIssues(issues []models.EmergencyIssue, nodeProblems map[string]string, nodeCount int) []models.Incident {
+	var incidents []models.Incident
+
+	nodeKeys := func(unhealthy bool) func(issue models.EmergencyIssue) []string {
+		return func(issue models.EmergencyIssue) []string {
+			if issue.Node == "" || (nodeProblems[issue.Node] != "") != unhealthy {
+				return nil
+			}
+			return []string{issue.Node}
+		}
+	}
+
+	passes := []correlationPass{
+		{cause: "node", keys: nodeKeys(true)},
+		{cause: "config", keys: missingConfigKeys},
+		{cause: "storage", keys: func(issue models.EmergencyIssue) []string {
+			var keys []string
+			for _, claim := range issue.Claims {
+				keys = append(keys, "pvc "+issue.Namespace+"/"+claim)
+			}
+			if issue.StorageClass != "" {
+				keys = append(keys, "storageclass "+issue.StorageClass)
+			}
+			return keys
+		}},
+		{cause: "image", keys: func(issue models.EmergencyIssue) []string {
+			return issue.Images
+		}},
+		{cause: "node", keys: nodeKeys(false)},
+		{cause: "owner", keys: func(issue models.EmergencyIssue) []string {
+			if issue.Owner == "" {
+				return nil
+			}
+			return []string{issue.Namespace + "/" + issue.Owner}
+		}},
+	}
+
+	for _, pass := range passes {
+		// Collect unassigned members per key
+		groups := make(map[string][]int)
+		for i, issue := range issues {
+			if issue.IncidentID != "" {
+				continue
+			}
+			for _, key := range pass.keys(issue) {
+				groups[key] = append(groups[key], i)
+			}
+		}
+
+		// Largest groups first so an issue lands in its most significant group
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if len(groups[keys[i]]) != len(groups[keys[j]]) {
+				return len(groups[keys[i]]) > len(groups[keys[j]])
+			}
+			return keys[i] < keys[j]
+		})
+
+		for _, key := range keys {
+			var members []int
+			for _, idx := range groups[key] {
+				if issues[idx].IncidentID == "" {
+					members = append(members, idx)
+				}
+			}
+
+			if !qualifiesAsIncident(pass.cause, key, issues, members, nodeProblems, nodeCount) {
+				continue
+			}
+
+			incident := buildIncident(len(incidents)+1, pass.cause, key, issues, members, nodeProblems)
+			for _, idx := range members {
+				issues[idx].IncidentID = incident.ID
+			}
+			incidents = append(incidents, incident)
+		}
+	}
+
+	return incidents
+}
+
+// qualifiesAsIncident decides whether a group is a real shared cause rather than coincidence
+func qualifiesAsIncident(cause, key string, issues []models.EmergencyIssue, members []int, nodeProblems map[string]string, nodeCount int) bool {
+	if len(affectedResources(issues, members)) < minIncidentSize {
+		return false
+	}
+
+	switch cause {
+	case "node":
+		// Everything shares the node on single-node clusters; require an unhealthy node or several workloads
+		if nodeProblems[key] != "" {
+			return true
+		}
+		return nodeCount > 1 && len(distinctOwners(issues, members)) >= 2
+	case "image":
+		// Sidecar or base images are shared widely; require pull failures or several workloads
+		return hasImagePullFailure(issues, members) || len(distinctOwners(issues, members)) >= 2
+	}
+
+	return true
+}
+
+// buildIncident creates the incident record for a group of issues
+func buildIncident(seq int, cause, key string, issues []models.EmergencyIssue, members []int, nodeProblems map[string]string) models.Incident {
+	affected := affectedResources(issues, members)
+
+	severity := ""
+	for _, idx := range members {
+		if severityRank[issues[idx].Severity] > severityRank[severity] {
+			severity = issues[idx].Severity
+		}
+	}
+
+	var probableCause string
+	switch cause {
+	case "node":
+		if problem := nodeProblems[key]; problem != "" {
+			probableCause = fmt.Sprintf("Node %s is unhealthy (%s) - %d resources on it are failing",
+				key, problem, len(affected))
+		} else {
+			probableCause = fmt.Sprintf("%d resources from %d workloads failing on node %s - check kubelet, disk and network on the node",
+				len(affected), len(distinctOwners(issues, members)), key)
+		}
+
+	case "config":
+		parts := strings.SplitN(key, " ", 2)
+		probableCause = fmt.Sprintf("Missing %s %s - referenced by %d resources; create it or fix the reference",
+			parts[0], parts[1], len(affected))
+
+	case "storage":
+		if strings.HasPrefix(key, "storageclass ") {
+			probableCause = fmt.Sprintf("StorageClass %s is failing to provide volumes for %d resources - check the provisioner",
+				strings.TrimPrefix(key, "storageclass "), len(affected))
+		} else {
+			probableCause = fmt.Sprintf("PVC %s is not bound or unavailable - %d resources depend on it",
+				strings.TrimPrefix(key, "pvc "), len(affected))
+		}
+
+	case "image":
+		if hasImagePullFailure(issues, members) {
+			probableCause = fmt.Sprintf("Image %s cannot be pulled (wrong tag, missing image or registry credentials)", key)
+		} else {
+			probableCause = fmt.Sprintf("Image %s is failing across %d workloads - likely a bad build or release",
+				key, len(distinctOwners(issues, members)))
+		}
+
+	case "owner":
+		revisions := distinctRevisions(issues, members)
+		probableCause = fmt.Sprintf("%s is failing across %d resources", key, len(affected))
+		if len(revisions) > 0 {
+			probableCause += fmt.Sprintf(" (revision %s)", strings.Join(revisions, ", "))
+		}
+		if ns, owner, ok := strings.Cut(key, "/"); ok && strings.HasPrefix(owner, "Deployment/") {
+			probableCause += fmt.Sprintf(" - likely a bad rollout; consider: kubectl rollout undo %s -n %s",
+				strings.ToLower(owner), ns)
+		}
+	}
+
+	return models.Incident{
+		ID:            fmt.Sprintf("INC-%d", seq),
+		Cause:         cause,
+		Key:           key,
+		ProbableCause: probableCause,
+		Severity:      severity,
+		IssueCount:    len(members),
+		Affected:      affected,
+	}
+}
+
+// missingConfigKeys extracts missing ConfigMap/Secret references from the issue and its events
+func missingConfigKeys(issue models.EmergencyIssue) []string {
+	texts := []string{issue.Message, issue.LastEvent}
+	for _, event := range issue.Events {
+		texts = append(texts, event.Message)
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, text := range texts {
+		for _, match := range missingConfigPattern.FindAllStringSubmatch(text, -1) {
+			kind := "ConfigMap"
+			if strings.EqualFold(match[1], "secret") {
+				kind = "Secret"
+			}
+			key := fmt.Sprintf("%s %s/%s", kind, issue.Namespace, match[2])
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// affectedResources returns the unique resources in a group, in first-seen order
+func affectedResources(issues []models.EmergencyIssue, members []int) []string {
+	seen := make(map[string]bool)
+	var resources []string
+	for _, idx := range members {
+		issue := issues[idx]
+		resource := fmt.Sprintf("%s %s/%s", issue.Resource, issue.Namespace, issue.Name)
+		if !seen[resource] {
+			seen[resource] = true
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// distinctOwners returns the unique owning controllers in a group (standalone resources count as their own owner)
+func distinctOwners(issues []models.EmergencyIssue, members []int) []string {
+	seen := make(map[string]bool)
+	var owners []string
+	for _, idx := range members {
+		issue := issues[idx]
+		owner := issue.Namespace + "/" + issue.Owner
+		if issue.Owner == "" {
+			owner = issue.Namespace + "/" + issue.Resource + "/" + issue.Name
+		}
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// distinctRevisions returns the unique rollout revisions in a group
+func distinctRevisions(issues []models.EmergencyIssue, members []int) []string {
+	seen := make(map[string]bool)
+	var revisions []string
+	for _, idx := range members {
+		revision := issues[idx].Revision
+		if revision != "" && !seen[revision] {
+			seen[revision] = true
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+// hasImagePullFailure reports whether any issue in the group is an image pull failure
+func hasImagePullFailure(issues []models.EmergencyIssue, members []int) bool {
+	for _, idx := range members {
+		if imagePullReasons[issues[idx].Reason] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPodContext records node, owner and volume context used for correlation
+func applyPodContext(pod corev1.Pod, issues []models.EmergencyIssue) {
+	owner, revision := podOwner(pod)
+
+	var claims []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+
+	for i := range issues {
+		issues[i].Node = pod.Spec.NodeName
+		issues[i].Owner = owner
+		issues[i].Revision = revision
+		issues[i].Claims = claims
+	}
+}
+
+// applyOwnerContext records the controller and revision for controller-level issues
+func applyOwnerContext(issues []models.EmergencyIssue, owner, revision string) {
+	for i := range issues {
+		issues[i].Owner = owner
+		issues[i].Revision = revision
+	}
+}
+
+// podOwner returns a pod's controller as Kind/name with the pod-template revision it runs
+func podOwner(pod corev1.Pod) (string, string) {
+	kind, name, ok := kube.PodController(pod)
+	if !ok {
+		return "", ""
+	}
+
+	switch kind {
+	case "Deployment", "ReplicaSet":
+		return kind + "/" + name, pod.Labels["pod-template-hash"]
+	case "StatefulSet", "DaemonSet":
+		return kind + "/" + name, pod.Labels["controller-revision-hash"]
+	default:
+		return kind + "/" + name, ""
+	}
+}
+
+// containerImage returns the image configured for a container
+func containerImage(pod corev1.Pod, containerName string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == containerName {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// storageClassName returns the PVC's storage class, or empty when unset
+func storageClassName(pvc corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return ""
+}
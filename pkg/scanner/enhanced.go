@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
@@ -19,75 +20,75 @@ func (s *Scanner) TakeEnhancedSnapshot(namespace string) (*models.EnhancedCluste
 	}
 
 	// Get base snapshot data (pods, deployments, etc)
-	fmt.Println("📦 Getting pods and deployments...")
+	fmt.Fprintln(os.Stderr, "📦 Getting pods and deployments...")
 	baseSnapshot, err := s.TakeSnapshot(namespace)
 	if err != nil {
 		return nil, err
 	}
 	snapshot.ClusterSnapshot = *baseSnapshot
-	fmt.Printf("   ✅ Found %d deployments\n", len(baseSnapshot.Deployments))
+	fmt.Fprintf(os.Stderr, "   ✅ Found %d deployments\n", len(baseSnapshot.Deployments))
 
 	// Get services
-	fmt.Println("🌐 Getting services...")
+	fmt.Fprintln(os.Stderr, "🌐 Getting services...")
 	services, err := s.getServiceDetails(namespace)
 	if err == nil {
 		snapshot.Services = services
-		fmt.Printf("   ✅ Found %d services\n", len(services))
+		fmt.Fprintf(os.Stderr, "   ✅ Found %d services\n", len(services))
 	} else {
-		fmt.Printf("   ⚠️  Failed to get services: %v\n", err)
+		fmt.Fprintf(os.Stderr, "   ⚠️  Failed to get services: %v\n", err)
 	}
 
 	// Get ingresses
-	fmt.Println("🔗 Getting ingresses...")
+	fmt.Fprintln(os.Stderr, "🔗 Getting ingresses...")
 	ingresses, err := s.getIngressDetails(namespace)
 	if err == nil {
 		snapshot.Ingresses = ingresses
-		fmt.Printf("   ✅ Found %d ingresses\n", len(ingresses))
+		fmt.Fprintf(os.Stderr, "   ✅ Found %d ingresses\n", len(ingresses))
 	} else {
-		fmt.Printf("   ⚠️  Failed to get ingresses: %v\n", err)
+		fmt.Fprintf(os.Stderr, "   ⚠️  Failed to get ingresses: %v\n", err)
 	}
 
 	// Get PVC details
-	fmt.Println("💾 Getting PVCs...")
+	fmt.Fprintln(os.Stderr, "💾 Getting PVCs...")
 	pvcDetails, err := s.getPVCDetails(namespace)
 	if err == nil {
 		snapshot.PVCDetails = pvcDetails
-		fmt.Printf("   ✅ Found %d PVCs\n", len(pvcDetails))
+		fmt.Fprintf(os.Stderr, "   ✅ Found %d PVCs\n", len(pvcDetails))
 	} else {
-		fmt.Printf("   ⚠️  Failed to get PVCs: %v\n", err)
+		fmt.Fprintf(os.Stderr, "   ⚠️  Failed to get PVCs: %v\n", err)
 	}
 
 	// Get ConfigMaps count
-	fmt.Println("📄 Getting ConfigMaps...")
+	fmt.Fprintln(os.Stderr, "📄 Getting ConfigMaps...")
 	configMaps, err := s.getResourceCounts(namespace, "configmaps")
 	if err == nil {
 		snapshot.ConfigMaps = configMaps
-		fmt.Printf("   ✅ Found ConfigMaps in %d namespaces\n", len(configMaps))
+		fmt.Fprintf(os.Stderr, "   ✅ Found ConfigMaps in %d namespaces\n", len(configMaps))
 	} else {
-		fmt.Printf("   ⚠️  Failed to get ConfigMaps: %v\n", err)
+		fmt.Fprintf(os.Stderr, "   ⚠️  Failed to get ConfigMaps: %v\n", err)
 	}
 
 	// Get Secrets count
-	fmt.Println("🔐 Getting Secrets...")
+	fmt.Fprintln(os.Stderr, "🔐 Getting Secrets...")
 	secrets, err := s.getResourceCounts(namespace, "secrets")
 	if err == nil {
 		snapshot.Secrets = secrets
-		fmt.Printf("   ✅ Found Secrets in %d namespaces\n", len(secrets))
+		fmt.Fprintf(os.Stderr, "   ✅ Found Secrets in %d namespaces\n", len(secrets))
 	} else {
-		fmt.Printf("   ⚠️  Failed to get Secrets: %v\n", err)
+		fmt.Fprintf(os.Stderr, "   ⚠️  Failed to get Secrets: %v\n", err)
 	}
 
 	// Get Network Policies
-	fmt.Println("🔒 Getting Network Policies...")
+	fmt.Fprintln(os.Stderr, "🔒 Getting Network Policies...")
 	networkPolicies, err := s.getNetworkPolicies(namespace)
 	if err == nil {
 		snapshot.NetworkPolicies = networkPolicies
-		fmt.Printf("   ✅ Found %d network policies\n", len(networkPolicies))
+		fmt.Fprintf(os.Stderr, "   ✅ Found %d network policies\n", len(networkPolicies))
 	} else {
-		fmt.Printf("   ⚠️  Failed to get Network Policies: %v\n", err)
+		fmt.Fprintf(os.Stderr, "   ⚠️  Failed to get Network Policies: %v\n", err)
 	}
 
-	fmt.Println("✅ Snapshot complete!")
+	fmt.Fprintln(os.Stderr, "✅ Snapshot complete!")
 	return snapshot, nil
 }
 
@@ -101,12 +102,12 @@ func (s *Scanner) getServiceDetails(namespace string) ([]models.ServiceDetail, e
 	}
 
 	totalServices := len(svcList.Items)
-	fmt.Printf("   Processing %d services...\n", totalServices)
+	fmt.Fprintf(os.Stderr, "   Processing %d services...\n", totalServices)
 
 	for i, svc := range svcList.Items {
 		// Show progress every 10 services
 		if i > 0 && i%10 == 0 {
-			fmt.Printf("   ... processed %d/%d services\n", i, totalServices)
+			fmt.Fprintf(os.Stderr, "   ... processed %d/%d services\n", i, totalServices)
 		}
 
 		// Get endpoints to see if service has backends
@@ -146,7 +147,7 @@ func (s *Scanner) getServiceDetails(namespace string) ([]models.ServiceDetail, e
 				Ports:      ports,
 			},
 			Endpoints: endpointCount,
-			Age:       formatDuration(time.Since(svc.CreationTimestamp.Time)),
+			Age:       models.Duration(time.Since(svc.CreationTimestamp.Time)),
 			Selector:  svc.Spec.Selector,
 		})
 	}
@@ -204,7 +205,7 @@ func (s *Scanner) getIngressDetails(namespace string) ([]models.IngressDetail, e
 				Backend:    backend,
 			},
 			IngressClass: ingressClass,
-			Age:          formatDuration(time.Since(ing.CreationTimestamp.Time)),
+			Age:          models.Duration(time.Since(ing.CreationTimestamp.Time)),
 			Rules:        len(ing.Spec.Rules),
 		})
 	}
@@ -221,10 +222,10 @@ func (s *Scanner) getPVCDetails(namespace string) ([]models.PVCDetail, error) {
 		return nil, err
 	}
 
-	fmt.Printf("   Processing %d PVCs...\n", len(pvcList.Items))
+	fmt.Fprintf(os.Stderr, "   Processing %d PVCs...\n", len(pvcList.Items))
 
 	// Get all pods to find which ones use PVCs
-	fmt.Println("   Looking up pod usage for PVCs...")
+	fmt.Fprintln(os.Stderr, "   Looking up pod usage for PVCs...")
 	podList, _ := s.clientset.CoreV1().Pods(namespace).List(s.ctx, metav1.ListOptions{})
 	pvcUsage := make(map[string]string)
 
@@ -267,7 +268,7 @@ func (s *Scanner) getPVCDetails(namespace string) ([]models.PVCDetail, error) {
 				Size:         size,
 				VolumeName:   pvc.Spec.VolumeName,
 			},
-			Age:        formatDuration(time.Since(pvc.CreationTimestamp.Time)),
+			Age:        models.Duration(time.Since(pvc.CreationTimestamp.Time)),
 			AccessMode: accessMode,
 			UsedBy:     usedBy,
 		})
@@ -1,11 +1,11 @@
 package scanner
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/opscart/opscart-k8s-watcher/pkg/models"
 )
@@ -53,7 +53,7 @@ func printEnhancedSnapshotTable(snapshot *models.EnhancedClusterSnapshot) {
 				deploy.Replicas,
 				deploy.ReadyReplicas,
 				status,
-				formatDuration(deploy.Age))
+				formatDuration(time.Duration(deploy.Age)))
 		}
 		w.Flush()
 		fmt.Println()
@@ -111,7 +111,7 @@ func printEnhancedSnapshotTable(snapshot *models.EnhancedClusterSnapshot) {
 				hosts,
 				tls,
 				ing.Backend,
-				ing.Age)
+				formatDuration(time.Duration(ing.Age)))
 		}
 		w.Flush()
 		fmt.Println()
@@ -144,7 +144,7 @@ func printEnhancedSnapshotTable(snapshot *models.EnhancedClusterSnapshot) {
 				pvc.Size,
 				pvc.StorageClass,
 				usedBy,
-				pvc.Age)
+				formatDuration(time.Duration(pvc.Age)))
 		}
 		w.Flush()
 		fmt.Println()
@@ -212,12 +212,7 @@ func printEnhancedSnapshotTable(snapshot *models.EnhancedClusterSnapshot) {
 
 // printEnhancedSnapshotJSON outputs enhanced snapshot as JSON
 func printEnhancedSnapshotJSON(snapshot *models.EnhancedClusterSnapshot) {
-	data, err := json.MarshalIndent(snapshot, "", "  ")
-	if err != nil {
-		fmt.Printf("Error formatting JSON: %v\n", err)
-		return
-	}
-	fmt.Println(string(data))
+	models.PrintEnvelope("snapshot", snapshot.ClusterName, snapshot)
 }
 
 // Helper functions
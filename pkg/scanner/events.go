@@ -0,0 +1,186 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxEventsPerIssue limits how many events are attached to a single issue
+const maxEventsPerIssue = 3
+
+// relevantEventReasons are event reasons that usually explain an emergency issue
+var relevantEventReasons = map[string]bool{
+	"FailedScheduling":       true,
+	"FailedMount":            true,
+	"FailedAttachVolume":     true,
+	"BackOff":                true,
+	"Unhealthy":              true,
+	"FailedCreatePodSandBox": true,
+	"Failed":                 true,
+	"FailedCreate":           true,
+	"ProvisioningFailed":     true,
+	"Evicted":                true,
+	"OOMKilling":             true,
+	"BackoffLimitExceeded":   true,
+	"DeadlineExceeded":       true,
+	"FailedNeedsStart":       true,
+}
+
+// resourceKinds maps EmergencyIssue.Resource values to Event involvedObject kinds
+var resourceKinds = map[string]string{
+	"pod":         "Pod",
+	"pvc":         "PersistentVolumeClaim",
+	"deployment":  "Deployment",
+	"statefulset": "StatefulSet",
+	"daemonset":   "DaemonSet",
+	"job":         "Job",
+	"cronjob":     "CronJob",
+}
+
+// attachEvents fetches Events once and attaches the most relevant ones to each issue
+func (s *Scanner) attachEvents(namespace string, issues []models.EmergencyIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	eventList, err := s.clientset.CoreV1().Events(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list events: %w", err)
+	}
+
+	// Index events by involved object
+	byObject := make(map[string][]corev1.Event)
+	for _, event := range eventList.Items {
+		obj := event.InvolvedObject
+		key := eventKey(obj.Kind, obj.Namespace, obj.Name)
+		byObject[key] = append(byObject[key], event)
+	}
+
+	for i := range issues {
+		kind, ok := resourceKinds[issues[i].Resource]
+		if !ok {
+			continue
+		}
+
+		events := byObject[eventKey(kind, issues[i].Namespace, issues[i].Name)]
+
+		// Rollout failures are usually reported on the Deployment's ReplicaSets (e.g. FailedCreate)
+		if kind == "Deployment" {
+			prefix := eventKey("ReplicaSet", issues[i].Namespace, issues[i].Name+"-")
+			for key, rsEvents := range byObject {
+				// ReplicaSet names are <deployment>-<pod-template-hash>
+				if strings.HasPrefix(key, prefix) && !strings.Contains(strings.TrimPrefix(key, prefix), "-") {
+					events = append(events, rsEvents...)
+				}
+			}
+		}
+
+		issues[i].Events = selectRelevantEvents(events, maxEventsPerIssue)
+		if len(issues[i].Events) > 0 {
+			top := issues[i].Events[0]
+			issues[i].LastEvent = fmt.Sprintf("%s: %s", top.Reason, top.Message)
+		}
+	}
+
+	return nil
+}
+
+// selectRelevantEvents ranks events (relevant warnings first, then most recent) and returns the top N
+func selectRelevantEvents(events []corev1.Event, limit int) []models.IssueEvent {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ranked := make([]models.IssueEvent, 0, len(events))
+	for _, event := range events {
+		ranked = append(ranked, toIssueEvent(event))
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ri, rj := eventRank(ranked[i]), eventRank(ranked[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return ranked[i].LastSeen.After(ranked[j].LastSeen)
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// eventRank scores an event by how likely it is to explain the issue
+func eventRank(event models.IssueEvent) int {
+	rank := 0
+	if event.Type == corev1.EventTypeWarning {
+		rank += 2
+	}
+	if relevantEventReasons[event.Reason] {
+		rank++
+	}
+	return rank
+}
+
+// toIssueEvent normalizes core/v1 and events.k8s.io style timestamps and counts
+func toIssueEvent(event corev1.Event) models.IssueEvent {
+	count := event.Count
+	lastSeen := event.LastTimestamp.Time
+	firstSeen := event.FirstTimestamp.Time
+
+	if event.Series != nil {
+		count = event.Series.Count
+		lastSeen = event.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.EventTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.CreationTimestamp.Time
+	}
+	if firstSeen.IsZero() {
+		firstSeen = event.EventTime.Time
+	}
+	if firstSeen.IsZero() {
+		firstSeen = lastSeen
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	return models.IssueEvent{
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   strings.TrimSpace(event.Message),
+		Count:     count,
+		FirstSeen: firstSeen,
+		LastSeen:  lastSeen,
+	}
+}
+
+// eventKey builds the lookup key for an involved object
+func eventKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// formatEvent renders an event for inline display
+func formatEvent(event models.IssueEvent) string {
+	icon := "ℹ️ "
+	if event.Type == corev1.EventTypeWarning {
+		icon = "⚠️ "
+	}
+
+	seen := "unknown"
+	if !event.LastSeen.IsZero() {
+		seen = formatDuration(time.Since(event.LastSeen)) + " ago"
+	}
+
+	message := strings.ReplaceAll(event.Message, "\n", " ")
+	return fmt.Sprintf("%s %s (x%d, last %s): %s", icon, event.Reason, event.Count, seen, message)
+}
@@ -0,0 +1,249 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FindIdleResources identifies resources doing no work, with how long they have been idle
+func (s *Scanner) FindIdleResources(namespace string) ([]models.IdleResource, error) {
+	var idle []models.IdleResource
+
+	// Deployments are the baseline check; the rest are best-effort
+	deployIdle, err := s.findIdleDeployments(namespace)
+	if err != nil {
+		return nil, err
+	}
+	idle = append(idle, deployIdle...)
+
+	if stsIdle, err := s.findIdleStatefulSets(namespace); err == nil {
+		idle = append(idle, stsIdle...)
+	}
+	if cronIdle, err := s.findIdleCronJobs(namespace); err == nil {
+		idle = append(idle, cronIdle...)
+	}
+	if jobIdle, err := s.findIdleJobs(namespace); err == nil {
+		idle = append(idle, jobIdle...)
+	}
+
+	idle = append(idle, s.findOrphans(namespace)...)
+
+	return idle, nil
+}
+
+// findOrphans finds objects nothing uses: storage, networking, config and old ReplicaSets
+func (s *Scanner) findOrphans(namespace string) []models.IdleResource {
+	var orphans []models.IdleResource
+
+	podList, err := s.clientset.CoreV1().Pods(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	deployList, err := s.clientset.AppsV1().Deployments(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	refs := s.collectReferences(namespace, podList.Items, deployList.Items)
+	if storage, err := s.findOrphanedStorage(namespace, refs); err == nil {
+		orphans = append(orphans, storage...)
+	}
+	if networking, err := s.findOrphanedNetworking(namespace); err == nil {
+		orphans = append(orphans, networking...)
+	}
+	if config, err := s.findUnreferencedConfig(namespace, refs); err == nil {
+		orphans = append(orphans, config...)
+	}
+	if replicaSets, err := s.findStaleReplicaSets(namespace, deployList.Items); err == nil {
+		orphans = append(orphans, replicaSets...)
+	}
+
+	return orphans
+}
+
+// findIdleDeployments finds Deployments scaled to zero
+func (s *Scanner) findIdleDeployments(namespace string) ([]models.IdleResource, error) {
+	var idle []models.IdleResource
+
+	deployList, err := s.clientset.AppsV1().Deployments(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	for _, deploy := range deployList.Items {
+		if replicasOrDefault(deploy.Spec.Replicas) != 0 || deploy.Status.Replicas != 0 {
+			continue
+		}
+
+		// Conditions are refreshed when the rollout settles at 0 replicas
+		since := lastReplicasChange(deploy.ObjectMeta)
+		for _, condition := range deploy.Status.Conditions {
+			if condition.LastUpdateTime.After(since) {
+				since = condition.LastUpdateTime.Time
+			}
+		}
+
+		idle = append(idle, newIdleResource("deployment", deploy.ObjectMeta, since,
+			"scaled to 0 replicas",
+			"Consider deleting if no longer needed"))
+	}
+
+	return idle, nil
+}
+
+// findIdleStatefulSets finds StatefulSets scaled to zero
+func (s *Scanner) findIdleStatefulSets(namespace string) ([]models.IdleResource, error) {
+	var idle []models.IdleResource
+
+	stsList, err := s.clientset.AppsV1().StatefulSets(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	for _, sts := range stsList.Items {
+		if replicasOrDefault(sts.Spec.Replicas) != 0 || sts.Status.Replicas != 0 {
+			continue
+		}
+
+		idle = append(idle, newIdleResource("statefulset", sts.ObjectMeta, lastReplicasChange(sts.ObjectMeta),
+			"scaled to 0 replicas (volumes are kept)",
+			"Delete it and its PVCs if the data is no longer needed"))
+	}
+
+	return idle, nil
+}
+
+// findIdleCronJobs finds suspended CronJobs
+func (s *Scanner) findIdleCronJobs(namespace string) ([]models.IdleResource, error) {
+	var idle []models.IdleResource
+
+	cronList, err := s.clientset.BatchV1().CronJobs(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+
+	for _, cj := range cronList.Items {
+		if cj.Spec.Suspend == nil || !*cj.Spec.Suspend {
+			continue
+		}
+
+		// Idle since the last run, or since creation if it never ran
+		since := cj.CreationTimestamp.Time
+		reason := "suspended, never ran"
+		if cj.Status.LastScheduleTime != nil {
+			since = cj.Status.LastScheduleTime.Time
+			reason = "suspended, last run " + since.Format("2006-01-02")
+		}
+
+		idle = append(idle, newIdleResource("cronjob", cj.ObjectMeta, since, reason,
+			"Delete if the schedule is no longer needed"))
+	}
+
+	return idle, nil
+}
+
+// findIdleJobs finds finished Jobs that are not managed by a CronJob and have no TTL
+func (s *Scanner) findIdleJobs(namespace string) ([]models.IdleResource, error) {
+	var idle []models.IdleResource
+
+	jobList, err := s.clientset.BatchV1().Jobs(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	for _, job := range jobList.Items {
+		// CronJob history limits clean these up already
+		// The TTL controller removes Jobs with ttlSecondsAfterFinished
+		if isOwnedByKind(job.OwnerReferences, "CronJob") || job.Spec.TTLSecondsAfterFinished != nil {
+			continue
+		}
+
+		finished, outcome := jobFinished(job)
+		if finished.IsZero() {
+			continue
+		}
+
+		idle = append(idle, newIdleResource("job", job.ObjectMeta, finished,
+			fmt.Sprintf("%s %s, no ttlSecondsAfterFinished", outcome, finished.Format("2006-01-02")),
+			"Delete the finished Job and set ttlSecondsAfterFinished"))
+	}
+
+	return idle, nil
+}
+
+// newIdleResource builds an IdleResource idle since the given time, with its delete command
+func newIdleResource(kind string, meta metav1.ObjectMeta, since time.Time, reason, recommendation string) models.IdleResource {
+	if since.IsZero() {
+		since = meta.CreationTimestamp.Time
+	}
+
+	cleanup := fmt.Sprintf("kubectl delete %s %s", kind, meta.Name)
+	if meta.Namespace != "" {
+		cleanup += " -n " + meta.Namespace
+	}
+
+	return models.IdleResource{
+		Type:           kind,
+		Name:           meta.Name,
+		Namespace:      meta.Namespace,
+		IdleDays:       int(time.Since(since).Hours() / 24),
+		LastActivity:   since,
+		Reason:         reason,
+		Recommendation: recommendation,
+		CleanupCommand: cleanup,
+	}
+}
+
+// lastReplicasChange returns when spec.replicas was last written, from managed fields
+// (covers kubectl scale, apply and autoscalers); falls back to creation time
+func lastReplicasChange(meta metav1.ObjectMeta) time.Time {
+	latest := meta.CreationTimestamp.Time
+
+	for _, entry := range meta.ManagedFields {
+		if entry.Time == nil || entry.FieldsV1 == nil {
+			continue
+		}
+		if bytes.Contains(entry.FieldsV1.Raw, []byte(`"f:replicas"`)) && entry.Time.After(latest) {
+			latest = entry.Time.Time
+		}
+	}
+
+	return latest
+}
+
+// jobFinished returns when a Job completed or failed, and which
+func jobFinished(job batchv1.Job) (time.Time, string) {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time, "completed"
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return condition.LastTransitionTime.Time, "completed"
+		case batchv1.JobFailed:
+			return condition.LastTransitionTime.Time, "failed"
+		}
+	}
+
+	return time.Time{}, ""
+}
+
+// isOwnedByKind reports whether any owner reference has the given kind
+func isOwnedByKind(refs []metav1.OwnerReference, kind string) bool {
+	for _, ref := range refs {
+		if ref.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
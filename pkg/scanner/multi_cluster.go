@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"sync"
 	"time"
 
@@ -28,6 +30,7 @@ type MultiClusterRunner struct {
 	clusters []config.ClusterConfig
 	scanFunc func(clusterContext string) (*ClusterResult, error) // injected scan function
 	parallel bool
+	out      io.Writer // Progress lines; stderr when stdout carries JSON or CSV
 }
 
 // NewMultiClusterRunner creates a runner for the given clusters
@@ -36,9 +39,15 @@ func NewMultiClusterRunner(clusters []config.ClusterConfig, scanFunc func(string
 		clusters: clusters,
 		scanFunc: scanFunc,
 		parallel: false,
+		out:      os.Stdout,
 	}
 }
 
+// SetOutput sends the progress lines to w
+func (r *MultiClusterRunner) SetOutput(w io.Writer) {
+	r.out = w
+}
+
 // RunAll executes scans across all clusters
 func (r *MultiClusterRunner) RunAll() []ClusterResult {
 	results := make([]ClusterResult, len(r.clusters))
@@ -62,7 +71,7 @@ func (r *MultiClusterRunner) runParallel(results []ClusterResult) {
 		go func(idx int, c config.ClusterConfig) {
 			defer wg.Done()
 
-			fmt.Printf("🔄 Scanning %s...\n", c.Name)
+			fmt.Fprintf(r.out, "🔄 Scanning %s...\n", c.Name)
 			start := time.Now()
 
 			result, err := r.scanFunc(c.Context)
@@ -77,14 +86,14 @@ func (r *MultiClusterRunner) runParallel(results []ClusterResult) {
 					Duration:    duration,
 					Error:       err,
 				}
-				fmt.Printf("❌ %s failed: %v\n", c.Name, err)
+				fmt.Fprintf(r.out, "❌ %s failed: %v\n", c.Name, err)
 			} else {
 				result.ClusterName = c.Name
 				result.Context = c.Context
 				result.Group = c.Group
 				result.Duration = duration
 				results[idx] = *result
-				fmt.Printf("✅ %s done (%v)\n", c.Name, duration.Round(time.Millisecond))
+				fmt.Fprintf(r.out, "✅ %s done (%v)\n", c.Name, duration.Round(time.Millisecond))
 			}
 			mu.Unlock()
 		}(i, cluster)
@@ -96,7 +105,7 @@ func (r *MultiClusterRunner) runParallel(results []ClusterResult) {
 // runSequential scans clusters one at a time
 func (r *MultiClusterRunner) runSequential(results []ClusterResult) {
 	for i, cluster := range r.clusters {
-		fmt.Printf("🔄 Scanning %s (%d/%d)...\n", cluster.Name, i+1, len(r.clusters))
+		fmt.Fprintf(r.out, "🔄 Scanning %s (%d/%d)...\n", cluster.Name, i+1, len(r.clusters))
 		start := time.Now()
 
 		result, err := r.scanFunc(cluster.Context)
@@ -110,56 +119,56 @@ func (r *MultiClusterRunner) runSequential(results []ClusterResult) {
 				Duration:    duration,
 				Error:       err,
 			}
-			fmt.Printf("❌ %s failed: %v\n", cluster.Name, err)
+			fmt.Fprintf(r.out, "❌ %s failed: %v\n", cluster.Name, err)
 		} else {
 			result.ClusterName = cluster.Name
 			result.Context = cluster.Context
 			result.Group = cluster.Group
 			result.Duration = duration
 			results[i] = *result
-			fmt.Printf("✅ %s done (%v)\n", cluster.Name, duration.Round(time.Millisecond))
+			fmt.Fprintf(r.out, "✅ %s done (%v)\n", cluster.Name, duration.Round(time.Millisecond))
 		}
 	}
 }
 
-// PrintMultiClusterHeader prints the header for multi-cluster output
-func PrintMultiClusterHeader(clusters []config.ClusterConfig) {
-	fmt.Println()
-	fmt.Println("╔═══════════════════════════════════════════════════════════╗")
-	fmt.Println("║           MULTI-CLUSTER SCAN                              ║")
-	fmt.Println("╚═══════════════════════════════════════════════════════════╝")
-	fmt.Printf("  📦 Scanning %d clusters...\n", len(clusters))
-	fmt.Println("  ─────────────────────────────────────────────")
+// PrintMultiClusterHeader prints the header for multi-cluster output to w
+func PrintMultiClusterHeader(w io.Writer, clusters []config.ClusterConfig) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "╔═══════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(w, "║           MULTI-CLUSTER SCAN                              ║")
+	fmt.Fprintln(w, "╚═══════════════════════════════════════════════════════════╝")
+	fmt.Fprintf(w, "  📦 Scanning %d clusters...\n", len(clusters))
+	fmt.Fprintln(w, "  ─────────────────────────────────────────────")
 	for _, c := range clusters {
-		fmt.Printf("  • %-20s [%s]\n", c.Name, c.Group)
+		fmt.Fprintf(w, "  • %-20s [%s]\n", c.Name, c.Group)
 	}
-	fmt.Println("  ─────────────────────────────────────────────")
-	fmt.Println()
+	fmt.Fprintln(w, "  ─────────────────────────────────────────────")
+	fmt.Fprintln(w)
 }
 
-// PrintMultiClusterSummary prints a summary across all results
-func PrintMultiClusterSummary(results []ClusterResult) {
-	fmt.Println()
-	fmt.Println("╔═══════════════════════════════════════════════════════════╗")
-	fmt.Println("║           MULTI-CLUSTER SUMMARY                           ║")
-	fmt.Println("╚═══════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("  %-20s %-12s %-10s\n", "CLUSTER", "GROUP", "STATUS")
-	fmt.Println("  ─────────────────────────────────────────────")
+// PrintMultiClusterSummary prints a summary across all results to w
+func PrintMultiClusterSummary(w io.Writer, results []ClusterResult) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "╔═══════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(w, "║           MULTI-CLUSTER SUMMARY                           ║")
+	fmt.Fprintln(w, "╚═══════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  %-20s %-12s %-10s\n", "CLUSTER", "GROUP", "STATUS")
+	fmt.Fprintln(w, "  ─────────────────────────────────────────────")
 
 	success := 0
 	failed := 0
 	for _, r := range results {
 		if r.Error != nil {
-			fmt.Printf("  %-20s %-12s ❌ %v\n", r.ClusterName, r.Group, r.Error)
+			fmt.Fprintf(w, "  %-20s %-12s ❌ %v\n", r.ClusterName, r.Group, r.Error)
 			failed++
 		} else {
-			fmt.Printf("  %-20s %-12s ✅ (%v)\n", r.ClusterName, r.Group, r.Duration.Round(time.Millisecond))
+			fmt.Fprintf(w, "  %-20s %-12s ✅ (%v)\n", r.ClusterName, r.Group, r.Duration.Round(time.Millisecond))
 			success++
 		}
 	}
 
-	fmt.Println("  ─────────────────────────────────────────────")
-	fmt.Printf("  ✅ Success: %d  |  ❌ Failed: %d  |  📦 Total: %d\n", success, failed, len(results))
-	fmt.Println()
+	fmt.Fprintln(w, "  ─────────────────────────────────────────────")
+	fmt.Fprintf(w, "  ✅ Success: %d  |  ❌ Failed: %d  |  📦 Total: %d\n", success, failed, len(results))
+	fmt.Fprintln(w)
 }
@@ -0,0 +1,451 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultRevisionHistoryLimit is the Deployment default for spec.revisionHistoryLimit
+	defaultRevisionHistoryLimit = 10
+
+	// endpointsChangeAnnotation records when an Endpoints object last changed
+	endpointsChangeAnnotation = "endpoints.kubernetes.io/last-change-trigger-time"
+)
+
+// systemNamespaces hold ConfigMaps/Secrets consumed by control plane components rather than pods
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// ignoredSecretTypes are managed by Kubernetes or tooling and never referenced by pods directly
+var ignoredSecretTypes = map[corev1.SecretType]bool{
+	corev1.SecretTypeServiceAccountToken: true,
+	corev1.SecretTypeBootstrapToken:      true,
+	"helm.sh/release.v1":                 true,
+}
+
+// findOrphanedStorage finds unbound or unmounted PVCs and Released PVs. Claims used by a
+// workload template (scaled to zero, or a CronJob between runs) are not orphaned.
+func (s *Scanner) findOrphanedStorage(namespace string, refs *workloadReferences) ([]models.IdleResource, error) {
+	var orphans []models.IdleResource
+
+	pvcList, err := s.clientset.CoreV1().PersistentVolumeClaims(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pvcs: %w", err)
+	}
+
+	for _, pvc := range pvcList.Items {
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			size = capacity
+		}
+		sizeGB := float64(size.Value()) / (1024 * 1024 * 1024)
+
+		if refs.usesClaim(pvc.Namespace, pvc.Name) {
+			continue
+		}
+
+		reason := fmt.Sprintf("bound but not used by any pod or workload, %.0f GB", sizeGB)
+		if pvc.Status.Phase != corev1.ClaimBound {
+			reason = fmt.Sprintf("%s, %.0f GB requested", pvc.Status.Phase, sizeGB)
+		}
+
+		storageClass := ""
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		orphan := newIdleResource("pvc", pvc.ObjectMeta, pvc.CreationTimestamp.Time, reason,
+			"Delete if the data is no longer needed (back it up first)")
+		orphan.EstCostPerMonth = sizeGB * s.pricing.StoragePrice(storageClass)
+		orphans = append(orphans, orphan)
+	}
+
+	pvList, err := s.clientset.CoreV1().PersistentVolumes().List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return orphans, nil
+	}
+
+	for _, pv := range pvList.Items {
+		if pv.Status.Phase != corev1.VolumeReleased {
+			continue
+		}
+
+		claim := "unknown claim"
+		if pv.Spec.ClaimRef != nil {
+			// PVs are cluster-scoped; attribute them to the namespace of their old claim
+			if namespace != "" && pv.Spec.ClaimRef.Namespace != namespace {
+				continue
+			}
+			claim = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+		}
+
+		capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+		sizeGB := float64(capacity.Value()) / (1024 * 1024 * 1024)
+
+		orphan := newIdleResource("pv", pv.ObjectMeta, pv.CreationTimestamp.Time,
+			fmt.Sprintf("Released (claim %s deleted, reclaim policy %s), %.0f GB", claim, pv.Spec.PersistentVolumeReclaimPolicy, sizeGB),
+			"Delete the volume (and the backing disk) if the data is no longer needed")
+		orphan.EstCostPerMonth = sizeGB * s.pricing.StoragePrice(pv.Spec.StorageClassName)
+		orphans = append(orphans, orphan)
+	}
+
+	return orphans, nil
+}
+
+// findOrphanedNetworking finds Services without endpoints and Ingresses pointing at missing Services
+func (s *Scanner) findOrphanedNetworking(namespace string) ([]models.IdleResource, error) {
+	var orphans []models.IdleResource
+
+	svcList, err := s.clientset.CoreV1().Services(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	endpoints := make(map[string]corev1.Endpoints)
+	if epList, err := s.clientset.CoreV1().Endpoints(namespace).List(s.ctx, metav1.ListOptions{}); err == nil {
+		for _, ep := range epList.Items {
+			endpoints[ep.Namespace+"/"+ep.Name] = ep
+		}
+	}
+
+	services := make(map[string]bool)
+	for _, svc := range svcList.Items {
+		services[svc.Namespace+"/"+svc.Name] = true
+
+		// Selector-less and ExternalName Services manage endpoints themselves
+		if len(svc.Spec.Selector) == 0 || svc.Spec.Type == corev1.ServiceTypeExternalName {
+			continue
+		}
+
+		ep := endpoints[svc.Namespace+"/"+svc.Name]
+		if hasReadyAddresses(ep) {
+			continue
+		}
+
+		since := svc.CreationTimestamp.Time
+		if changed, err := time.Parse(time.RFC3339, ep.Annotations[endpointsChangeAnnotation]); err == nil {
+			since = changed
+		}
+
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			orphan := newIdleResource("service", svc.ObjectMeta, since,
+				"LoadBalancer with no backends - still billed by the cloud provider",
+				"Delete the Service or fix its selector")
+			orphan.EstCostPerMonth = s.pricing.LoadBalancerPrice()
+			orphans = append(orphans, orphan)
+			continue
+		}
+
+		orphans = append(orphans, newIdleResource("service", svc.ObjectMeta, since,
+			fmt.Sprintf("%s Service with no endpoints (selector matches no ready pods)", svc.Spec.Type),
+			"Delete the Service or fix its selector"))
+	}
+
+	ingList, err := s.clientset.NetworkingV1().Ingresses(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return orphans, nil
+	}
+
+	for _, ing := range ingList.Items {
+		var missing []string
+		seen := make(map[string]bool)
+		for _, name := range ingressBackendServices(ing.Spec) {
+			if !services[ing.Namespace+"/"+name] && !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		orphans = append(orphans, newIdleResource("ingress", ing.ObjectMeta, ing.CreationTimestamp.Time,
+			fmt.Sprintf("routes to missing Service %s", strings.Join(missing, ", ")),
+			"Delete the Ingress or restore the Services"))
+	}
+
+	return orphans, nil
+}
+
+// findUnreferencedConfig finds ConfigMaps and Secrets no pod or pod template references
+func (s *Scanner) findUnreferencedConfig(namespace string, refs *workloadReferences) ([]models.IdleResource, error) {
+	var orphans []models.IdleResource
+
+	cmList, err := s.clientset.CoreV1().ConfigMaps(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %w", err)
+	}
+
+	for _, cm := range cmList.Items {
+		// kube-root-ca.crt is injected into every namespace
+		if systemNamespaces[cm.Namespace] || cm.Name == "kube-root-ca.crt" || refs.configMaps[cm.Namespace+"/"+cm.Name] {
+			continue
+		}
+		orphans = append(orphans, newIdleResource("configmap", cm.ObjectMeta, cm.CreationTimestamp.Time,
+			"not referenced by any pod or workload template",
+			"Delete if not read by an operator or external tool"))
+	}
+
+	secretList, err := s.clientset.CoreV1().Secrets(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return orphans, nil
+	}
+
+	for _, secret := range secretList.Items {
+		if systemNamespaces[secret.Namespace] || ignoredSecretTypes[secret.Type] || refs.secrets[secret.Namespace+"/"+secret.Name] {
+			continue
+		}
+		orphans = append(orphans, newIdleResource("secret", secret.ObjectMeta, secret.CreationTimestamp.Time,
+			"not referenced by any pod, workload template, ServiceAccount or Ingress TLS",
+			"Delete if not read by an operator or external tool"))
+	}
+
+	return orphans, nil
+}
+
+// findStaleReplicaSets finds empty ReplicaSets beyond their Deployment's revision history, and ownerless ones
+func (s *Scanner) findStaleReplicaSets(namespace string, deployments []appsv1.Deployment) ([]models.IdleResource, error) {
+	var orphans []models.IdleResource
+
+	rsList, err := s.clientset.AppsV1().ReplicaSets(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	historyLimit := make(map[string]int)
+	for _, deploy := range deployments {
+		limit := defaultRevisionHistoryLimit
+		if deploy.Spec.RevisionHistoryLimit != nil {
+			limit = int(*deploy.Spec.RevisionHistoryLimit)
+		}
+		historyLimit[deploy.Namespace+"/"+deploy.Name] = limit
+	}
+
+	// Group empty ReplicaSets by owning Deployment
+	byDeployment := make(map[string][]appsv1.ReplicaSet)
+	for _, rs := range rsList.Items {
+		if replicasOrDefault(rs.Spec.Replicas) != 0 || rs.Status.Replicas != 0 {
+			continue
+		}
+
+		owner := ""
+		for _, ref := range rs.OwnerReferences {
+			if ref.Kind == "Deployment" {
+				owner = rs.Namespace + "/" + ref.Name
+			}
+		}
+		if owner == "" && len(rs.OwnerReferences) == 0 {
+			orphans = append(orphans, newIdleResource("replicaset", rs.ObjectMeta, rs.CreationTimestamp.Time,
+				"empty ReplicaSet with no owner", "Delete the leftover ReplicaSet"))
+			continue
+		}
+		if owner != "" {
+			byDeployment[owner] = append(byDeployment[owner], rs)
+		}
+	}
+
+	for owner, replicaSets := range byDeployment {
+		limit, ok := historyLimit[owner]
+		if !ok || len(replicaSets) <= limit {
+			continue
+		}
+
+		// Newest revisions are kept for rollback; the rest are beyond history
+		sort.Slice(replicaSets, func(i, j int) bool {
+			return rsRevision(replicaSets[i]) > rsRevision(replicaSets[j])
+		})
+		for _, rs := range replicaSets[limit:] {
+			orphans = append(orphans, newIdleResource("replicaset", rs.ObjectMeta, rs.CreationTimestamp.Time,
+				fmt.Sprintf("revision %d beyond revisionHistoryLimit %d of %s", rsRevision(rs), limit, owner),
+				"Delete the old ReplicaSet (rollback to it will no longer be possible)"))
+		}
+	}
+
+	return orphans, nil
+}
+
+// workloadReferences records ConfigMaps, Secrets and PVCs referenced by pods, templates,
+// ServiceAccounts and Ingresses
+type workloadReferences struct {
+	configMaps map[string]bool
+	secrets    map[string]bool
+	claims     map[string]bool
+
+	// claimPrefixes are "<namespace>/<template>-<statefulset>-" for StatefulSet volumeClaimTemplates,
+	// whose claims are named with an ordinal suffix
+	claimPrefixes []string
+}
+
+// usesClaim reports whether a PVC is mounted by a pod or claimed by a workload template
+func (r *workloadReferences) usesClaim(namespace, name string) bool {
+	key := namespace + "/" + name
+	if r.claims[key] {
+		return true
+	}
+	for _, prefix := range r.claimPrefixes {
+		if ordinal, ok := strings.CutPrefix(key, prefix); ok {
+			if _, err := strconv.Atoi(ordinal); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addPodSpec records every ConfigMap, Secret and PVC a pod spec can use
+func (r *workloadReferences) addPodSpec(namespace string, spec corev1.PodSpec) {
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			r.claims[namespace+"/"+volume.PersistentVolumeClaim.ClaimName] = true
+		}
+		if volume.ConfigMap != nil {
+			r.configMaps[namespace+"/"+volume.ConfigMap.Name] = true
+		}
+		if volume.Secret != nil {
+			r.secrets[namespace+"/"+volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					r.configMaps[namespace+"/"+source.ConfigMap.Name] = true
+				}
+				if source.Secret != nil {
+					r.secrets[namespace+"/"+source.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, pullSecret := range spec.ImagePullSecrets {
+		r.secrets[namespace+"/"+pullSecret.Name] = true
+	}
+
+	containers := append([]corev1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				r.configMaps[namespace+"/"+envFrom.ConfigMapRef.Name] = true
+			}
+			if envFrom.SecretRef != nil {
+				r.secrets[namespace+"/"+envFrom.SecretRef.Name] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				r.configMaps[namespace+"/"+env.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				r.secrets[namespace+"/"+env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+}
+
+// collectReferences gathers references from pods, workload templates, ServiceAccounts and Ingress TLS
+func (s *Scanner) collectReferences(namespace string, pods []corev1.Pod, deployments []appsv1.Deployment) *workloadReferences {
+	refs := &workloadReferences{
+		configMaps: make(map[string]bool),
+		secrets:    make(map[string]bool),
+		claims:     make(map[string]bool),
+	}
+
+	for _, pod := range pods {
+		refs.addPodSpec(pod.Namespace, pod.Spec)
+	}
+
+	// Templates cover workloads scaled to zero or between runs
+	for _, deploy := range deployments {
+		refs.addPodSpec(deploy.Namespace, deploy.Spec.Template.Spec)
+	}
+	if stsList, err := s.clientset.AppsV1().StatefulSets(namespace).List(s.ctx, metav1.ListOptions{}); err == nil {
+		for _, sts := range stsList.Items {
+			refs.addPodSpec(sts.Namespace, sts.Spec.Template.Spec)
+			for _, template := range sts.Spec.VolumeClaimTemplates {
+				refs.claimPrefixes = append(refs.claimPrefixes, sts.Namespace+"/"+template.Name+"-"+sts.Name+"-")
+			}
+		}
+	}
+	if dsList, err := s.clientset.AppsV1().DaemonSets(namespace).List(s.ctx, metav1.ListOptions{}); err == nil {
+		for _, ds := range dsList.Items {
+			refs.addPodSpec(ds.Namespace, ds.Spec.Template.Spec)
+		}
+	}
+	if cronList, err := s.clientset.BatchV1().CronJobs(namespace).List(s.ctx, metav1.ListOptions{}); err == nil {
+		for _, cj := range cronList.Items {
+			refs.addPodSpec(cj.Namespace, cj.Spec.JobTemplate.Spec.Template.Spec)
+		}
+	}
+	if saList, err := s.clientset.CoreV1().ServiceAccounts(namespace).List(s.ctx, metav1.ListOptions{}); err == nil {
+		for _, sa := range saList.Items {
+			for _, secret := range sa.Secrets {
+				refs.secrets[sa.Namespace+"/"+secret.Name] = true
+			}
+			for _, pullSecret := range sa.ImagePullSecrets {
+				refs.secrets[sa.Namespace+"/"+pullSecret.Name] = true
+			}
+		}
+	}
+	if ingList, err := s.clientset.NetworkingV1().Ingresses(namespace).List(s.ctx, metav1.ListOptions{}); err == nil {
+		for _, ing := range ingList.Items {
+			for _, tls := range ing.Spec.TLS {
+				refs.secrets[ing.Namespace+"/"+tls.SecretName] = true
+			}
+		}
+	}
+
+	return refs
+}
+
+// hasReadyAddresses reports whether an Endpoints object has at least one ready address
+func hasReadyAddresses(ep corev1.Endpoints) bool {
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressBackendServices lists the Service names an Ingress routes to
+func ingressBackendServices(spec networkingv1.IngressSpec) []string {
+	var names []string
+	if spec.DefaultBackend != nil && spec.DefaultBackend.Service != nil {
+		names = append(names, spec.DefaultBackend.Service.Name)
+	}
+	for _, rule := range spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				names = append(names, path.Backend.Service.Name)
+			}
+		}
+	}
+	return names
+}
+
+// rsRevision returns the Deployment revision recorded on a ReplicaSet
+func rsRevision(rs appsv1.ReplicaSet) int {
+	revision, err := strconv.Atoi(rs.Annotations[revisionAnnotation])
+	if err != nil {
+		return 0
+	}
+	return revision
+}
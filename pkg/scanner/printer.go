@@ -1,7 +1,6 @@
 package scanner
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -13,8 +12,12 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-// PrintEmergencyIssues displays critical issues in war room format
-func PrintEmergencyIssues(issues []models.EmergencyIssue) {
+// maxAffectedShown limits how many affected resources are listed per incident
+const maxAffectedShown = 10
+
+// PrintEmergencyIssues displays critical issues in war room format.
+// Issues that belong to a correlated incident are shown once under that incident.
+func PrintEmergencyIssues(issues []models.EmergencyIssue, incidents []models.Incident) {
 	if len(issues) == 0 {
 		fmt.Println("✅ No critical issues found!")
 		return
@@ -24,8 +27,12 @@ func PrintEmergencyIssues(issues []models.EmergencyIssue) {
 	critical := []models.EmergencyIssue{}
 	high := []models.EmergencyIssue{}
 	medium := []models.EmergencyIssue{}
+	correlated := 0
 
 	for _, issue := range issues {
+		if issue.IncidentID != "" {
+			correlated++
+		}
 		switch issue.Severity {
 		case "critical":
 			critical = append(critical, issue)
@@ -40,56 +47,160 @@ func PrintEmergencyIssues(issues []models.EmergencyIssue) {
 	fmt.Println("╔════════════════════════════════════════════════════════════╗")
 	fmt.Println("║             WAR ROOM - EMERGENCY ISSUES                    ║")
 	fmt.Println("╚════════════════════════════════════════════════════════════╝")
-	fmt.Printf("\n🔴 CRITICAL: %d    🟡 HIGH: %d    🟠 MEDIUM: %d\n\n", len(critical), len(high), len(medium))
+	fmt.Printf("\n🔴 CRITICAL: %d    🟡 HIGH: %d    🟠 MEDIUM: %d\n", len(critical), len(high), len(medium))
+	if len(incidents) > 0 {
+		fmt.Printf("🧩 INCIDENTS: %d (covering %d issues)\n", len(incidents), correlated)
+	}
+	fmt.Println()
+
+	// Print correlated incidents first - one root cause usually explains many issues
+	if len(incidents) > 0 {
+		fmt.Println("🧩 CORRELATED INCIDENTS:")
+		fmt.Println(strings.Repeat("═", 80))
+		for _, incident := range incidents {
+			printIncident(incident)
+		}
+		fmt.Println()
+	}
 
 	// Print critical issues
-	if len(critical) > 0 {
+	if countUncorrelated(critical) > 0 {
 		fmt.Println("🔴 CRITICAL ISSUES:")
 		fmt.Println(strings.Repeat("═", 80))
-		for _, issue := range critical {
-			printIssue(issue)
-		}
+		printUncorrelated(critical)
 		fmt.Println()
 	}
 
 	// Print high priority issues
-	if len(high) > 0 {
+	if countUncorrelated(high) > 0 {
 		fmt.Println("🟡 HIGH PRIORITY:")
 		fmt.Println(strings.Repeat("═", 80))
-		for _, issue := range high {
-			printIssue(issue)
-		}
+		printUncorrelated(high)
 		fmt.Println()
 	}
 
 	// Print medium priority issues
-	if len(medium) > 0 {
+	if countUncorrelated(medium) > 0 {
 		fmt.Println("🟠 MEDIUM PRIORITY:")
 		fmt.Println(strings.Repeat("═", 80))
-		for _, issue := range medium {
+		printUncorrelated(medium)
+	}
+}
+
+func printIncident(incident models.Incident) {
+	icon := "🟠"
+	switch incident.Severity {
+	case "critical":
+		icon = "🔴"
+	case "high":
+		icon = "🟡"
+	}
+
+	fmt.Printf("  %s [%s] %s: %s\n", icon, incident.ID, incident.Cause, incident.Key)
+	fmt.Printf("  └─ Probable cause: %s\n", incident.ProbableCause)
+	fmt.Printf("  └─ Affected (%d resources, %d issues):\n", len(incident.Affected), incident.IssueCount)
+
+	for i, resource := range incident.Affected {
+		if i == maxAffectedShown {
+			fmt.Printf("       ... and %d more\n", len(incident.Affected)-maxAffectedShown)
+			break
+		}
+		fmt.Printf("       • %s\n", resource)
+	}
+	fmt.Println()
+}
+
+// countUncorrelated counts issues not already shown under an incident
+func countUncorrelated(issues []models.EmergencyIssue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.IncidentID == "" {
+			count++
+		}
+	}
+	return count
+}
+
+func printUncorrelated(issues []models.EmergencyIssue) {
+	for _, issue := range issues {
+		if issue.IncidentID == "" {
 			printIssue(issue)
 		}
 	}
 }
 
 func printIssue(issue models.EmergencyIssue) {
-	fmt.Printf("  %s/%s\n", issue.Namespace, issue.Name)
+	if issue.Resource != "" && issue.Resource != "pod" {
+		fmt.Printf("  %s/%s (%s)\n", issue.Namespace, issue.Name, issue.Resource)
+	} else {
+		fmt.Printf("  %s/%s\n", issue.Namespace, issue.Name)
+	}
 	fmt.Printf("  └─ Status: %s", issue.Reason)
 	if issue.Restarts > 0 {
 		fmt.Printf(" | Restarts: %d", issue.Restarts)
 	}
-	fmt.Printf(" | Age: %s\n", formatDuration(issue.Age))
-	fmt.Printf("  └─ %s\n\n", issue.Message)
+	fmt.Printf(" | Age: %s\n", formatDuration(time.Duration(issue.Age)))
+	fmt.Printf("  └─ %s\n", issue.Message)
+	if issue.Hint != "" {
+		fmt.Printf("  └─ 💡 %s\n", issue.Hint)
+	}
+	for _, event := range issue.Events {
+		fmt.Printf("  └─ %s\n", formatEvent(event))
+	}
+	if issue.Logs != nil {
+		printContainerLogs(issue.Container, issue.Logs)
+	}
+	fmt.Println()
 }
 
-// PrintSnapshotJSON outputs snapshot as JSON
-func PrintSnapshotJSON(snapshot *models.ClusterSnapshot) {
-	data, err := json.MarshalIndent(snapshot, "", "  ")
-	if err != nil {
-		fmt.Printf("Error formatting JSON: %v\n", err)
+func printContainerLogs(container string, logs *models.ContainerLogs) {
+	if logs.Error != "" {
+		fmt.Printf("  └─ 📜 Logs (%s): %s\n", container, logs.Error)
 		return
 	}
-	fmt.Println(string(data))
+
+	if len(logs.Previous) > 0 {
+		fmt.Printf("  └─ 📜 Previous logs (%s, last %d lines):\n", container, len(logs.Previous))
+		for _, line := range logs.Previous {
+			fmt.Printf("       │ %s\n", line)
+		}
+	}
+	if len(logs.Current) > 0 {
+		fmt.Printf("  └─ 📜 Current logs (%s, last %d lines):\n", container, len(logs.Current))
+		for _, line := range logs.Current {
+			fmt.Printf("       │ %s\n", line)
+		}
+	}
+	if len(logs.Previous) == 0 && len(logs.Current) == 0 {
+		fmt.Printf("  └─ 📜 Logs (%s): empty\n", container)
+	}
+}
+
+// EmergencyOutput is the data of `emergency --format json`
+type EmergencyOutput struct {
+	Issues    []models.EmergencyIssue `json:"issues"`
+	Incidents []models.Incident       `json:"incidents"`
+}
+
+// PrintEmergencyIssuesJSON outputs issues and correlated incidents as JSON
+func PrintEmergencyIssuesJSON(clusterName string, issues []models.EmergencyIssue, incidents []models.Incident) {
+	output := EmergencyOutput{
+		Issues:    issues,
+		Incidents: incidents,
+	}
+	if output.Issues == nil {
+		output.Issues = []models.EmergencyIssue{}
+	}
+	if output.Incidents == nil {
+		output.Incidents = []models.Incident{}
+	}
+
+	models.PrintEnvelope("emergency", clusterName, output)
+}
+
+// PrintSnapshotJSON outputs snapshot as JSON
+func PrintSnapshotJSON(snapshot *models.ClusterSnapshot) {
+	models.PrintEnvelope("snapshot", snapshot.ClusterName, snapshot)
 }
 
 // PrintSnapshotTable outputs snapshot in table format
@@ -121,7 +232,7 @@ func PrintSnapshotTable(snapshot *models.ClusterSnapshot) {
 				deploy.Replicas,
 				deploy.ReadyReplicas,
 				status,
-				formatDuration(deploy.Age))
+				formatDuration(time.Duration(deploy.Age)))
 		}
 		w.Flush()
 		fmt.Println()
@@ -138,18 +249,37 @@ func PrintIdleResources(idle []models.IdleResource) {
 	fmt.Printf("Found %d idle resources:\n\n", len(idle))
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "TYPE\tNAMESPACE\tNAME\tIDLE DAYS\tRECOMMENDATION")
-	fmt.Fprintln(w, strings.Repeat("─", 80))
+	fmt.Fprintln(w, "TYPE\tNAMESPACE\tNAME\tIDLE DAYS\tEST $/MO\tREASON\tRECOMMENDATION")
+	fmt.Fprintln(w, strings.Repeat("─", 110))
 
+	totalCost := 0.0
 	for _, resource := range idle {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n",
+		cost := "-"
+		if resource.EstCostPerMonth > 0 {
+			cost = fmt.Sprintf("$%.2f", resource.EstCostPerMonth)
+			totalCost += resource.EstCostPerMonth
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
 			resource.Type,
 			resource.Namespace,
 			resource.Name,
 			resource.IdleDays,
+			cost,
+			resource.Reason,
 			resource.Recommendation)
 	}
 	w.Flush()
+
+	if totalCost > 0 {
+		fmt.Printf("\n💰 Estimated waste: $%.2f/month (list-price estimate for storage and load balancers)\n", totalCost)
+	}
+
+	fmt.Println("\n🧹 Cleanup commands (review before running):")
+	for _, resource := range idle {
+		if resource.CleanupCommand != "" {
+			fmt.Printf("   %s\n", resource.CleanupCommand)
+		}
+	}
 }
 
 // FindResources searches for resources across clusters
@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// oomKilledHint explains an OOMKilled termination
+const oomKilledHint = "exceeded its memory limit - raise limits.memory or fix the memory leak"
+
+// waitingReasonHints explains waiting reasons that block a container from starting
+var waitingReasonHints = map[string]string{
+	"CreateContainerConfigError": "missing config key - a referenced ConfigMap/Secret or one of its keys does not exist",
+	"InvalidImageName":           "image reference is malformed - check the registry, repository and tag",
+	"CreateContainerError":       "runtime could not create the container - check volume mounts, securityContext and command",
+}
+
+// podReasonHints explains pod-level failure reasons
+var podReasonHints = map[string]string{
+	"DeadlineExceeded": "pod ran longer than activeDeadlineSeconds and was stopped",
+	"Evicted":          "kubelet evicted the pod under node resource pressure (memory, disk or PIDs)",
+}
+
+// classifyTermination maps a container's termination state to a class and a human hint
+func classifyTermination(terminated *corev1.ContainerStateTerminated) (string, string) {
+	if terminated == nil {
+		return "", ""
+	}
+
+	// Reasons set by the kubelet/runtime are more specific than the exit code
+	switch terminated.Reason {
+	case "OOMKilled":
+		return "OOMKilled", oomKilledHint
+	case "ContainerCannotRun":
+		return "ContainerCannotRun", "runtime could not start the process - check command/entrypoint, binary path and file permissions"
+	case "DeadlineExceeded":
+		return "DeadlineExceeded", "container ran longer than activeDeadlineSeconds and was stopped"
+	}
+
+	switch terminated.ExitCode {
+	case 0:
+		return "Completed (0)", "process exited successfully - a long-running container should not exit (wrong command or args?)"
+	case 1:
+		return "Error (1)", "application error - check logs for the exception or failed startup check"
+	case 126:
+		return "NotExecutable (126)", "command found but not executable - check file permissions and the binary's architecture"
+	case 127:
+		return "CommandNotFound (127)", "command not found - check command/entrypoint and the image contents"
+	case 137:
+		return "SIGKILL (137)", "killed by SIGKILL - OOM killer, a failed liveness probe or shutdown exceeding terminationGracePeriodSeconds"
+	case 139:
+		return "Segfault (139)", "segfault - the process accessed invalid memory (native crash, bad library or wrong architecture)"
+	case 143:
+		return "SIGTERM (143)", "terminated by SIGTERM - SIGTERM not handled, or stopped by a liveness probe failure or node drain"
+	}
+
+	return fmt.Sprintf("Error (%d)", terminated.ExitCode), fmt.Sprintf("exited with code %d - check logs", terminated.ExitCode)
+}
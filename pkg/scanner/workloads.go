@@ -0,0 +1,437 @@
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opscart/opscart-k8s-watcher/pkg/models"
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// rolloutStallThreshold is how long a new ReplicaSet may stay unready before the rollout counts as stalled
+	rolloutStallThreshold = 10 * time.Minute
+
+	// statefulSetStuckThreshold is how long a StatefulSet ordinal may stay unready before it counts as stuck
+	statefulSetStuckThreshold = 5 * time.Minute
+
+	// revisionAnnotation is set by the deployment controller on Deployments and their ReplicaSets
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// findWorkloadIssues checks controllers (Deployments, StatefulSets, DaemonSets, Jobs, CronJobs) for failures
+func (s *Scanner) findWorkloadIssues(namespace string) []models.EmergencyIssue {
+	var issues []models.EmergencyIssue
+
+	if deployIssues, err := s.findDeploymentIssues(namespace); err == nil {
+		issues = append(issues, deployIssues...)
+	}
+
+	if stsIssues, err := s.findStatefulSetIssues(namespace); err == nil {
+		issues = append(issues, stsIssues...)
+	}
+
+	if dsIssues, err := s.findDaemonSetIssues(namespace); err == nil {
+		issues = append(issues, dsIssues...)
+	}
+
+	if jobIssues, err := s.findJobIssues(namespace); err == nil {
+		issues = append(issues, jobIssues...)
+	}
+
+	if cronIssues, err := s.findCronJobIssues(namespace); err == nil {
+		issues = append(issues, cronIssues...)
+	}
+
+	return issues
+}
+
+// findDeploymentIssues looks for failed rollouts and unavailable replicas
+func (s *Scanner) findDeploymentIssues(namespace string) ([]models.EmergencyIssue, error) {
+	var issues []models.EmergencyIssue
+
+	deployList, err := s.clientset.AppsV1().Deployments(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// ReplicaSets are only needed to spot stalled rollouts; carry on without them if listing fails
+	rsList, _ := s.clientset.AppsV1().ReplicaSets(namespace).List(s.ctx, metav1.ListOptions{})
+
+	for _, deploy := range deployList.Items {
+		start := len(issues)
+		age := time.Since(deploy.CreationTimestamp.Time)
+		desired := replicasOrDefault(deploy.Spec.Replicas)
+
+		// Paused or scaled-to-zero deployments are intentional
+		if deploy.Spec.Paused || desired == 0 {
+			continue
+		}
+
+		// ProgressDeadlineExceeded is the controller's own verdict that the rollout failed
+		deadlineExceeded := false
+		for _, condition := range deploy.Status.Conditions {
+			if condition.Type == appsv1.DeploymentProgressing &&
+				condition.Status == corev1.ConditionFalse &&
+				condition.Reason == "ProgressDeadlineExceeded" {
+				deadlineExceeded = true
+				issues = append(issues, models.EmergencyIssue{
+					Severity:  "critical",
+					Resource:  "deployment",
+					Namespace: deploy.Namespace,
+					Name:      deploy.Name,
+					Reason:    "ProgressDeadlineExceeded",
+					Message:   fmt.Sprintf("Rollout failed to progress: %s", condition.Message),
+					Age:       models.Duration(age),
+				})
+			}
+		}
+
+		// Stalled rollout: new ReplicaSet exists but its pods never become ready
+		if !deadlineExceeded && rsList != nil {
+			if newRS := findNewReplicaSet(deploy, rsList.Items); newRS != nil {
+				rsDesired := replicasOrDefault(newRS.Spec.Replicas)
+				rsAge := time.Since(newRS.CreationTimestamp.Time)
+				if rsDesired > 0 && newRS.Status.ReadyReplicas < rsDesired && rsAge > rolloutStallThreshold {
+					issues = append(issues, models.EmergencyIssue{
+						Severity:  "high",
+						Resource:  "deployment",
+						Namespace: deploy.Namespace,
+						Name:      deploy.Name,
+						Reason:    "RolloutStalled",
+						Message: fmt.Sprintf("New ReplicaSet %s (revision %s) has %d/%d ready replicas after %s",
+							newRS.Name, newRS.Annotations[revisionAnnotation],
+							newRS.Status.ReadyReplicas, rsDesired, formatDuration(rsAge)),
+						Age: models.Duration(age),
+					})
+				}
+			}
+		}
+
+		// Unavailable replicas. Surge and maxUnavailable make the count non-zero during every
+		// rolling update, so it only counts once the Deployment is unavailable or has made no
+		// progress for rolloutStallThreshold.
+		if deploy.Status.UnavailableReplicas > 0 && !deadlineExceeded {
+			severity, reason := "", ""
+			for _, condition := range deploy.Status.Conditions {
+				switch {
+				case condition.Type == appsv1.DeploymentAvailable && condition.Status == corev1.ConditionFalse:
+					severity, reason = "high", condition.Reason
+				case condition.Type == appsv1.DeploymentProgressing && severity == "" &&
+					time.Since(condition.LastUpdateTime.Time) > rolloutStallThreshold:
+					severity, reason = "medium", "UnavailableReplicas"
+				}
+			}
+			if severity != "" {
+				issues = append(issues, models.EmergencyIssue{
+					Severity:  severity,
+					Resource:  "deployment",
+					Namespace: deploy.Namespace,
+					Name:      deploy.Name,
+					Reason:    reason,
+					Message: fmt.Sprintf("%d of %d replicas unavailable (%d available)",
+						deploy.Status.UnavailableReplicas, desired, deploy.Status.AvailableReplicas),
+					Age: models.Duration(age),
+				})
+			}
+		}
+
+		applyOwnerContext(issues[start:], "Deployment/"+deploy.Name, deploy.Annotations[revisionAnnotation])
+	}
+
+	return issues, nil
+}
+
+// findNewReplicaSet returns the ReplicaSet matching the deployment's current revision
+func findNewReplicaSet(deploy appsv1.Deployment, replicaSets []appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	revision := deploy.Annotations[revisionAnnotation]
+	if revision == "" {
+		return nil
+	}
+
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		if !isOwnedBy(rs.OwnerReferences, "Deployment", deploy.Name) {
+			continue
+		}
+		if rs.Annotations[revisionAnnotation] == revision {
+			return rs
+		}
+	}
+	return nil
+}
+
+// findStatefulSetIssues looks for StatefulSets stuck waiting on a single ordinal
+func (s *Scanner) findStatefulSetIssues(namespace string) ([]models.EmergencyIssue, error) {
+	var issues []models.EmergencyIssue
+
+	stsList, err := s.clientset.AppsV1().StatefulSets(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sts := range stsList.Items {
+		start := len(issues)
+		desired := replicasOrDefault(sts.Spec.Replicas)
+		if desired == 0 || (sts.Status.ReadyReplicas >= desired && sts.Status.UpdatedReplicas >= desired) {
+			continue
+		}
+
+		age := time.Since(sts.CreationTimestamp.Time)
+
+		selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		podList, err := s.clientset.CoreV1().Pods(sts.Namespace).List(s.ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			continue
+		}
+
+		// Index owned pods by ordinal (pod name is <sts-name>-<ordinal>)
+		pods := make(map[int]corev1.Pod)
+		for _, pod := range podList.Items {
+			if !isOwnedBy(pod.OwnerReferences, "StatefulSet", sts.Name) {
+				continue
+			}
+			ordinal, err := strconv.Atoi(strings.TrimPrefix(pod.Name, sts.Name+"-"))
+			if err != nil {
+				continue
+			}
+			pods[ordinal] = pod
+		}
+
+		// The lowest ordinal that is missing or unready is the one blocking progress
+		for ordinal := 0; ordinal < int(desired); ordinal++ {
+			podName := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+			pod, exists := pods[ordinal]
+
+			if !exists {
+				if age < statefulSetStuckThreshold {
+					break
+				}
+				issues = append(issues, models.EmergencyIssue{
+					Severity:  "high",
+					Resource:  "statefulset",
+					Namespace: sts.Namespace,
+					Name:      sts.Name,
+					Reason:    "StatefulSetStuck",
+					Message: fmt.Sprintf("Stuck on ordinal %d: pod %s has not been created (%d/%d ready)",
+						ordinal, podName, sts.Status.ReadyReplicas, desired),
+					Age: models.Duration(age),
+				})
+				break
+			}
+
+			if isPodReady(pod) {
+				continue
+			}
+
+			podAge := time.Since(pod.CreationTimestamp.Time)
+			if podAge < statefulSetStuckThreshold {
+				break
+			}
+
+			issues = append(issues, models.EmergencyIssue{
+				Severity:  "high",
+				Resource:  "statefulset",
+				Namespace: sts.Namespace,
+				Name:      sts.Name,
+				Reason:    "StatefulSetStuck",
+				Message: fmt.Sprintf("Stuck on ordinal %d: pod %s not ready for %s (phase %s, %d/%d ready)",
+					ordinal, podName, formatDuration(podAge), pod.Status.Phase, sts.Status.ReadyReplicas, desired),
+				Age: models.Duration(age),
+			})
+			break
+		}
+
+		applyOwnerContext(issues[start:], "StatefulSet/"+sts.Name, sts.Status.UpdateRevision)
+	}
+
+	return issues, nil
+}
+
+// findDaemonSetIssues looks for DaemonSets with misscheduled or unavailable pods
+func (s *Scanner) findDaemonSetIssues(namespace string) ([]models.EmergencyIssue, error) {
+	var issues []models.EmergencyIssue
+
+	dsList, err := s.clientset.AppsV1().DaemonSets(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ds := range dsList.Items {
+		start := len(issues)
+		age := time.Since(ds.CreationTimestamp.Time)
+
+		if ds.Status.NumberUnavailable > 0 {
+			issues = append(issues, models.EmergencyIssue{
+				Severity:  "high",
+				Resource:  "daemonset",
+				Namespace: ds.Namespace,
+				Name:      ds.Name,
+				Reason:    "DaemonSetUnavailable",
+				Message: fmt.Sprintf("%d of %d nodes have no available pod",
+					ds.Status.NumberUnavailable, ds.Status.DesiredNumberScheduled),
+				Age: models.Duration(age),
+			})
+		}
+
+		if ds.Status.NumberMisscheduled > 0 {
+			issues = append(issues, models.EmergencyIssue{
+				Severity:  "medium",
+				Resource:  "daemonset",
+				Namespace: ds.Namespace,
+				Name:      ds.Name,
+				Reason:    "DaemonSetMisscheduled",
+				Message:   fmt.Sprintf("%d pods running on nodes they should not run on", ds.Status.NumberMisscheduled),
+				Age:       models.Duration(age),
+			})
+		}
+
+		applyOwnerContext(issues[start:], "DaemonSet/"+ds.Name, "")
+	}
+
+	return issues, nil
+}
+
+// findJobIssues looks for Jobs that exhausted their retries or deadline
+func (s *Scanner) findJobIssues(namespace string) ([]models.EmergencyIssue, error) {
+	var issues []models.EmergencyIssue
+
+	jobList, err := s.clientset.BatchV1().Jobs(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobList.Items {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type != batchv1.JobFailed || condition.Status != corev1.ConditionTrue {
+				continue
+			}
+
+			severity := "high"
+			message := fmt.Sprintf("Job failed: %s", condition.Message)
+			if condition.Reason == "BackoffLimitExceeded" {
+				severity = "critical"
+				backoffLimit := int32(6) // Kubernetes default
+				if job.Spec.BackoffLimit != nil {
+					backoffLimit = *job.Spec.BackoffLimit
+				}
+				message = fmt.Sprintf("Job hit backoffLimit (%d) after %d failed pods", backoffLimit, job.Status.Failed)
+			}
+
+			issues = append(issues, models.EmergencyIssue{
+				Severity:  severity,
+				Resource:  "job",
+				Namespace: job.Namespace,
+				Name:      job.Name,
+				Reason:    condition.Reason,
+				Message:   message,
+				Age:       models.Duration(time.Since(condition.LastTransitionTime.Time)),
+				Restarts:  int(job.Status.Failed),
+				Owner:     "Job/" + job.Name,
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// findCronJobIssues looks for CronJobs without a successful run inside their schedule window
+func (s *Scanner) findCronJobIssues(namespace string) ([]models.EmergencyIssue, error) {
+	var issues []models.EmergencyIssue
+
+	cronList, err := s.clientset.BatchV1().CronJobs(namespace).List(s.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, cj := range cronList.Items {
+		if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+			continue
+		}
+
+		age := now.Sub(cj.CreationTimestamp.Time)
+
+		schedule, err := parseCronSchedule(cj)
+		if err != nil {
+			issues = append(issues, models.EmergencyIssue{
+				Severity:  "medium",
+				Resource:  "cronjob",
+				Namespace: cj.Namespace,
+				Name:      cj.Name,
+				Reason:    "InvalidSchedule",
+				Message:   fmt.Sprintf("Cannot parse schedule %q: %v", cj.Spec.Schedule, err),
+				Age:       models.Duration(age),
+				Owner:     "CronJob/" + cj.Name,
+			})
+			continue
+		}
+
+		// Window starts at the last success, or creation if it never succeeded
+		base := cj.CreationTimestamp.Time
+		lastSuccess := "never succeeded"
+		if cj.Status.LastSuccessfulTime != nil {
+			base = cj.Status.LastSuccessfulTime.Time
+			lastSuccess = fmt.Sprintf("last success %s ago", formatDuration(now.Sub(base)))
+		}
+
+		// Missed when a whole scheduled run came and went after the base without succeeding
+		expected := schedule.Next(base)
+		deadline := schedule.Next(expected)
+		if now.Before(deadline) {
+			continue
+		}
+
+		issues = append(issues, models.EmergencyIssue{
+			Severity:  "high",
+			Resource:  "cronjob",
+			Namespace: cj.Namespace,
+			Name:      cj.Name,
+			Reason:    "CronJobMissedSchedule",
+			Message: fmt.Sprintf("No successful run since run due at %s (schedule %q, %s)",
+				expected.Format("2006-01-02 15:04"), cj.Spec.Schedule, lastSuccess),
+			Age:   models.Duration(age),
+			Owner: "CronJob/" + cj.Name,
+		})
+	}
+
+	return issues, nil
+}
+
+// parseCronSchedule parses a CronJob schedule honoring spec.timeZone
+func parseCronSchedule(cj batchv1.CronJob) (cron.Schedule, error) {
+	spec := cj.Spec.Schedule
+	if cj.Spec.TimeZone != nil && *cj.Spec.TimeZone != "" && !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", *cj.Spec.TimeZone, spec)
+	}
+	return cron.ParseStandard(spec)
+}
+
+// isOwnedBy checks whether owner references include a controller of the given kind and name
+func isOwnedBy(refs []metav1.OwnerReference, kind, name string) bool {
+	for _, ref := range refs {
+		if ref.Kind == kind && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// replicasOrDefault dereferences a replica count, applying the Kubernetes default of 1
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}